@@ -0,0 +1,139 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/jwt"
+	"github.com/omnichat/validator/internal/mock"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const defaultAddr = "localhost:3000"
+
+func main() {
+	var (
+		addr         = flag.String("addr", defaultAddr, "Address to listen on")
+		clerkToken   = flag.String("clerk-token", mock.DefaultClerkToken, "Token accepted for Clerk auth endpoints")
+		jwtToken     = flag.String("jwt-token", mock.DefaultJWTToken, "Token accepted for V1 API endpoints")
+		secondToken  = flag.String("jwt-token-b", mock.DefaultSecondToken, "Token accepted for V1 API endpoints as a second user")
+		jwtSecret    = flag.String("jwt-secret", jwt.DevSecret, "HS256 secret for minted V1 access tokens")
+		refreshToken = flag.String("refresh-token", mock.DefaultRefreshToken, "Refresh token accepted by /api/v1/auth/refresh")
+		appleJWKS    = flag.String("apple-jwks", "", "JWKS URL Apple ID tokens are verified against (rejects every ID token when empty)")
+		appleClient  = flag.String("apple-client-id", mock.DefaultAppleClientID, "Audience expected in Apple ID tokens")
+		rotate       = flag.Bool("rotate-refresh-tokens", false, "Rotate refresh tokens on refresh and revoke a token family when an old token is reused")
+		webhookKey   = flag.String("stripe-webhook-secret", mock.DefaultStripeWebhookSecret, "Secret that verifies Stripe-Signature headers on /api/stripe/webhook")
+		openAPIPath  = flag.String("openapi", "", "OpenAPI document to serve from /api/openapi.json")
+		latency      = flag.Duration("latency", 0, "Latency added to every response")
+		rateLimit    = flag.Int("rate-limit", 0, "Requests per client and window for V1 routes, uploads get a fifth (0 disables)")
+		rateWindow   = flag.Duration("rate-window", time.Minute, "Rate limit window")
+		quiet        = flag.Bool("quiet", false, "Disable request logging")
+	)
+
+	var faultFlags stringList
+	flag.Var(&faultFlags, "fault", "Inject a fault as [METHOD ]PATTERN=ACTION[@RATE] (repeatable)")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", colors.BoldText("OmniChat Mock Server"))
+		fmt.Fprintf(os.Stderr, "In-memory OmniChat API for offline validator runs\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nFaults:\n")
+		fmt.Fprintf(os.Stderr, "  PATTERN uses path.Match syntax; \"*\" matches every path.\n")
+		fmt.Fprintf(os.Stderr, "  ACTION is an HTTP status code, a delay such as 2s, or reset.\n")
+		fmt.Fprintf(os.Stderr, "  RATE is the probability of the fault firing (default 1).\n")
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  # Serve the mock API on the validator's default URL\n")
+		fmt.Fprintf(os.Stderr, "  %s\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Serve the real OpenAPI document for contract testing\n")
+		fmt.Fprintf(os.Stderr, "  %s --openapi ../openapi/openapi.json\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Rate limit V1 routes like production\n")
+		fmt.Fprintf(os.Stderr, "  %s --rate-limit 100\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Accept Apple ID tokens minted by test-apple-auth\n")
+		fmt.Fprintf(os.Stderr, "  %s --apple-jwks http://localhost:3003/auth/keys\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Fail half of the v1 uploads and drop chat connections\n")
+		fmt.Fprintf(os.Stderr, "  %s --fault \"POST /api/v1/upload=503@0.5\" --fault \"/api/chat=reset\"\n", os.Args[0])
+	}
+
+	flag.Parse()
+
+	opts := mock.Options{
+		ClerkToken:          *clerkToken,
+		JWTToken:            *jwtToken,
+		SecondToken:         *secondToken,
+		JWTSecret:           *jwtSecret,
+		RefreshToken:        *refreshToken,
+		AppleJWKS:           *appleJWKS,
+		AppleClientID:       *appleClient,
+		RotateRefreshTokens: *rotate,
+		StripeWebhookSecret: *webhookKey,
+		Latency:             *latency,
+		RateLimit:           *rateLimit,
+		RateWindow:          *rateWindow,
+	}
+
+	if *openAPIPath != "" {
+		data, err := os.ReadFile(*openAPIPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s failed to read OpenAPI document: %v\n", colors.Error("Error:"), err)
+			os.Exit(2)
+		}
+		opts.OpenAPI = data
+	}
+
+	for _, value := range faultFlags {
+		fault, err := mock.ParseFault(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+			os.Exit(2)
+		}
+		opts.Faults = append(opts.Faults, fault)
+	}
+
+	if !*quiet {
+		logger := log.New(os.Stdout, "", log.Ltime)
+		opts.Logf = logger.Printf
+	}
+
+	fmt.Println(colors.BoldText("🧪 OmniChat Mock Server"))
+	fmt.Printf("📍 Listening on http://%s\n", *addr)
+	fmt.Printf("🔑 Clerk token: %s\n", *clerkToken)
+	fmt.Printf("🔑 JWT token:   %s\n", *jwtToken)
+	if *rateLimit > 0 {
+		fmt.Printf("🚦 Rate limit: %d per %v\n", *rateLimit, *rateWindow)
+	}
+	for _, fault := range opts.Faults {
+		fmt.Printf("💥 Fault: %s\n", fault)
+	}
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Printf("Run: omnichat-validator --url http://%s --clerk %s --bearer %s\n", *addr, *clerkToken, *jwtToken)
+	fmt.Println()
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mock.New(opts),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if err := server.ListenAndServe(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+		os.Exit(1)
+	}
+}
+
+// stringList collects the values of a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/bench"
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const (
+	defaultBenchDuration    = 30 * time.Second
+	defaultBenchConcurrency = 4
+)
+
+// defaultBenchEndpoints are public, so a benchmark works without tokens
+var defaultBenchEndpoints = []string{"GET /api/config", "GET /api/models"}
+
+// runBench implements the bench subcommand
+func runBench(args []string) {
+	if len(args) > 0 && args[0] == "models" {
+		runModelBench(args[1:])
+		return
+	}
+
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	var (
+		baseURL     = fs.String("url", defaultURL, "Base URL of the API")
+		clerkToken  = fs.String("clerk", "", "Clerk session token for web app endpoints")
+		jwtToken    = fs.String("bearer", "", "JWT Bearer token for V1 API endpoints")
+		timeout     = fs.Duration("timeout", defaultTimeout, "Request timeout")
+		duration    = fs.Duration("duration", defaultBenchDuration, "How long to send requests")
+		concurrency = fs.Int("concurrency", defaultBenchConcurrency, "Concurrent workers per endpoint")
+		rps         = fs.Float64("rps", 0, "Target requests per second per endpoint (0 for unlimited)")
+		jsonPath    = fs.String("json", "", "Write the results as JSON to a file")
+	)
+	var endpoints stringList
+	fs.Var(&endpoints, "endpoint", "\"METHOD /path\" to benchmark (repeatable, default GET /api/config and GET /api/models)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", colors.BoldText("OmniChat API Benchmark"))
+		fmt.Fprintf(os.Stderr, "Load-test endpoints and report latency percentiles, statuses and throughput\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s bench [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s bench models [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nEndpoints under /api/v1/ use --bearer, all others use --clerk.\n")
+		fmt.Fprintf(os.Stderr, "Requests are never retried and carry no body.\n")
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  # Benchmark the public endpoints for 30s\n")
+		fmt.Fprintf(os.Stderr, "  %s bench\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Hold 20 req/s against the conversation list for a minute\n")
+		fmt.Fprintf(os.Stderr, "  %s bench --bearer \"jwt\" --endpoint \"GET /api/v1/conversations\" --rps 20 --duration 1m\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Track results over time\n")
+		fmt.Fprintf(os.Stderr, "  %s bench --json bench/$(date +%%F).json\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	if len(endpoints) == 0 {
+		endpoints = defaultBenchEndpoints
+	}
+
+	// Keep a connection per worker so reconnects don't skew latency, and
+	// never retry so every response is measured as it arrived
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = *concurrency
+	config := types.Config{BaseURL: *baseURL, Timeout: *timeout, Transport: transport}
+	publicClient := client.NewAPIClient(&config)
+	config.AuthToken = *clerkToken
+	clerkClient := client.NewAPIClient(&config)
+	config.AuthToken = *jwtToken
+	jwtClient := client.NewAPIClient(&config)
+
+	opts := bench.Options{Concurrency: *concurrency, RPS: *rps, Duration: *duration}
+	for _, value := range endpoints {
+		target, err := bench.ParseTarget(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+			os.Exit(2)
+		}
+		switch {
+		case strings.HasPrefix(target.Path, "/api/v1/") && *jwtToken != "":
+			target.Client = jwtClient
+		case !strings.HasPrefix(target.Path, "/api/v1/") && *clerkToken != "":
+			target.Client = clerkClient
+		default:
+			target.Client = publicClient
+		}
+		opts.Targets = append(opts.Targets, target)
+	}
+
+	fmt.Println(colors.BoldText("🏁 OmniChat API Benchmark"))
+	fmt.Printf("📍 Benchmarking %d endpoint(s) at %s for %v\n", len(opts.Targets), *baseURL, *duration)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Println()
+
+	result, err := bench.Run(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+		os.Exit(2)
+	}
+	result.BaseURL = *baseURL
+
+	bench.Print(os.Stdout, result)
+
+	if *jsonPath != "" {
+		if err := bench.WriteJSON(*jsonPath, result); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+			os.Exit(1)
+		}
+		fmt.Println()
+		fmt.Printf("📝 Wrote benchmark results to %s\n", *jsonPath)
+	}
+}
+
+// runModelBench implements bench models: stream a fixed prompt through
+// every model and compare time to first token and throughput
+func runModelBench(args []string) {
+	fs := flag.NewFlagSet("bench models", flag.ExitOnError)
+	var (
+		baseURL    = fs.String("url", defaultURL, "Base URL of the API")
+		clerkToken = fs.String("clerk", "", "Clerk session token, enables /api/chat and lists the models of /api/models")
+		jwtToken   = fs.String("bearer", "", "JWT Bearer token, enables V1 messages")
+		timeout    = fs.Duration("timeout", 2*time.Minute, "Timeout per streamed reply")
+		prompt     = fs.String("prompt", bench.DefaultModelPrompt, "Prompt sent to every model")
+		runs       = fs.Int("runs", 1, "Streams per model and endpoint; the median is reported")
+		jsonPath   = fs.String("json", "", "Write the results as JSON to a file")
+	)
+	var modelIDs, providers stringList
+	fs.Var(&modelIDs, "model", "Model ID to benchmark (repeatable, default every chat model)")
+	fs.Var(&providers, "provider", "Only benchmark models from this provider (repeatable)")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", colors.BoldText("OmniChat Model Benchmark"))
+		fmt.Fprintf(os.Stderr, "Stream a prompt through each model from /api/models and compare time to\n")
+		fmt.Fprintf(os.Stderr, "first token, total time, chunks and throughput\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s bench models [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nEvery stream is charged to the account's battery. Image generation\n")
+		fmt.Fprintf(os.Stderr, "models are skipped unless named with --model.\n")
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  # Compare every model on both endpoints\n")
+		fmt.Fprintf(os.Stderr, "  %s bench models --clerk \"token1\" --bearer \"token2\"\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Three runs of two models through the V1 API, without a Clerk session\n")
+		fmt.Fprintf(os.Stderr, "  %s bench models --bearer \"jwt\" --model gpt-4o-mini --model gpt-4o --runs 3\n", os.Args[0])
+	}
+	fs.Parse(args)
+
+	if *clerkToken == "" && *jwtToken == "" {
+		fmt.Fprintf(os.Stderr, "%s bench models needs --clerk for /api/chat and/or --bearer for V1 messages\n", colors.Error("Error:"))
+		os.Exit(2)
+	}
+
+	// /api/models only accepts Clerk sessions, so without one the models
+	// are the ones named
+	config := types.Config{BaseURL: *baseURL, Timeout: *timeout}
+	var models []types.AIModel
+	switch {
+	case *clerkToken != "":
+		config.AuthToken = *clerkToken
+		var err error
+		if models, err = client.NewAPIClient(&config).ListModels(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+			os.Exit(1)
+		}
+	case len(modelIDs) > 0:
+		for _, id := range modelIDs {
+			models = append(models, types.AIModel{ID: id, Name: id})
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "%s bench models needs --clerk to list /api/models, or --model to name the models\n", colors.Error("Error:"))
+		os.Exit(2)
+	}
+
+	opts := bench.ModelOptions{Prompt: *prompt, Runs: *runs}
+	for _, model := range models {
+		if len(modelIDs) > 0 && !slices.Contains(modelIDs, model.ID) {
+			continue
+		}
+		if len(modelIDs) == 0 && model.SupportsImageGeneration {
+			continue
+		}
+		if len(providers) > 0 && !slices.Contains(providers, model.Provider) {
+			continue
+		}
+		opts.Models = append(opts.Models, model)
+	}
+	if len(opts.Models) == 0 {
+		fmt.Fprintf(os.Stderr, "%s no models from /api/models match the filters\n", colors.Error("Error:"))
+		os.Exit(2)
+	}
+
+	if *clerkToken != "" {
+		config.AuthToken = *clerkToken
+		opts.ChatClient = client.NewAPIClient(&config)
+	}
+	if *jwtToken != "" {
+		config.AuthToken = *jwtToken
+		opts.V1Client = client.NewAPIClient(&config)
+	}
+
+	fmt.Println(colors.BoldText("🏁 OmniChat Model Benchmark"))
+	fmt.Printf("📍 Streaming %d model(s) at %s, %d run(s) each\n", len(opts.Models), *baseURL, max(*runs, 1))
+	fmt.Printf("💬 Prompt: %s\n", *prompt)
+	fmt.Println(strings.Repeat("─", 60))
+	fmt.Println()
+
+	startedAt := time.Now()
+	results := bench.RunModels(opts, func(r bench.ModelResult) {
+		switch {
+		case r.Error != "":
+			fmt.Printf("%s %s via %s\n", colors.Error("❌"), r.Model, r.Endpoint)
+		case r.Failures > 0:
+			fmt.Printf("%s  %s via %s (%d/%d runs failed)\n", colors.Warning("⚠️"), r.Model, r.Endpoint, r.Failures, r.Runs)
+		default:
+			fmt.Printf("%s %s via %s (TTFT %v)\n", colors.Success("✅"), r.Model, r.Endpoint, r.TTFT.Round(time.Millisecond))
+		}
+	})
+
+	fmt.Println()
+	fmt.Println(colors.Header("📊", "Model Comparison:"))
+	fmt.Println()
+	bench.PrintModels(os.Stdout, results)
+
+	if *jsonPath != "" {
+		if err := bench.WriteModelsJSON(*jsonPath, *baseURL, *prompt, startedAt, results); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+			os.Exit(1)
+		}
+		fmt.Println()
+		fmt.Printf("📝 Wrote benchmark results to %s\n", *jsonPath)
+	}
+}
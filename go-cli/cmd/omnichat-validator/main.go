@@ -7,6 +7,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/jwt"
+	"github.com/omnichat/validator/internal/report"
 	"github.com/omnichat/validator/internal/types"
 	"github.com/omnichat/validator/internal/validator"
 	"github.com/omnichat/validator/pkg/colors"
@@ -15,27 +18,71 @@ import (
 const (
 	defaultURL     = "http://localhost:3000"
 	defaultTimeout = 30 * time.Second
+
+	defaultMaxAttempts   = 3
+	defaultRetryDelay    = 500 * time.Millisecond
+	defaultRetryMaxDelay = 60 * time.Second
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		runBench(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "webhook" {
+		runWebhook(os.Args[2:])
+		return
+	}
+
 	// Define command-line flags
 	var (
 		baseURL    = flag.String("url", defaultURL, "Base URL of the API")
 		clerkToken = flag.String("clerk", "", "Clerk session token for web app endpoints")
 		jwtToken   = flag.String("bearer", "", "JWT Bearer token for V1 API endpoints")
+		jwtTokenB  = flag.String("bearer-b", "", "JWT Bearer token of a second user, enables the authorization matrix")
+		refresh    = flag.String("refresh-token", "", "Refresh token for the token lifecycle suite (used up if the server rotates refresh tokens)")
+		jwtSecret  = flag.String("jwt-secret", jwt.DevSecret, "HS256 secret of the API, signs the expired and wrong-audience tokens of the JWT rejection suite")
+		billing    = flag.Bool("billing", false, "Run the billing scenario after the other checks (changes the --clerk user's subscription and battery, needs --stripe-webhook-secret)")
+		stripeKey  = flag.String("stripe-webhook-secret", "", "Stripe webhook signing secret of the API, for the billing scenario")
+		stripeUser = flag.String("stripe-user-id", "", "ID of the --clerk user for the billing scenario (default the --bearer user)")
+		stripeSub  = flag.String("stripe-subscription", "", "Test-mode Stripe subscription of the starter plan for the billing scenario (default a made-up one, which only the mock accepts)")
 		timeout    = flag.Duration("timeout", defaultTimeout, "Request timeout")
 		verbose    = flag.Bool("verbose", false, "Enable verbose output")
 		help       = flag.Bool("help", false, "Show help message")
+		noBuiltin  = flag.Bool("skip-builtin", false, "Skip the built-in endpoint spec (use with --spec)")
+		contract   = flag.Bool("contract", false, "Validate every operation in /api/openapi.json against its declared responses")
+		rateLimit  = flag.Bool("rate-limit", false, "Run the rate limit conformance suite against the V1 API (takes minutes)")
+		models     = flag.Bool("models", false, "Smoke test every model from /api/models, including vision and image generation (uses battery)")
+		audit      = flag.Bool("battery-audit", false, "Check battery spend of a few chats against /api/v1/user/usage and the daily usage (uses battery)")
+		parallel   = flag.Int("parallel", 1, "Number of checks to run concurrently")
+		recordPath = flag.String("record", "", "Record every request/response pair to a cassette file")
+		replayPath = flag.String("replay", "", "Serve responses from a cassette file instead of the API")
+
+		maxAttempts     = flag.Int("max-attempts", defaultMaxAttempts, "Attempts per request for 429/502/503/504 and network errors (1 disables retries)")
+		retryDelay      = flag.Duration("retry-delay", defaultRetryDelay, "Initial retry backoff, doubled per attempt with jitter")
+		retryMaxDelay   = flag.Duration("retry-max-delay", defaultRetryMaxDelay, "Longest wait before a retry, including Retry-After and X-RateLimit-Reset")
+		retryAllMethods = flag.Bool("retry-all-methods", false, "Also retry POST and PATCH requests")
 		
 		// Legacy token flag for backward compatibility
 		legacyToken = flag.String("token", "", "Bearer token (deprecated, use --clerk or --bearer)")
 	)
 
+	var specFiles stringList
+	flag.Var(&specFiles, "spec", "YAML/JSON test spec file to run (repeatable)")
+	var rateLimitEndpoints stringList
+	flag.Var(&rateLimitEndpoints, "rate-limit-endpoint", "\"METHOD /path\" to burst in --rate-limit mode (repeatable)")
+	var auditModels stringList
+	flag.Var(&auditModels, "audit-model", "Model ID the battery audit chats with (repeatable, default the first text model of every provider)")
+	var reportFlags stringList
+	flag.Var(&reportFlags, "report", "Write a report as format=path, format is junit or json (repeatable)")
+
 	// Custom usage message
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "%s\n", colors.BoldText("OmniChat API Validator"))
-		fmt.Fprintf(os.Stderr, "Comprehensive testing for all 43 OmniChat API endpoints\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Comprehensive testing for the OmniChat API endpoints\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s bench [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s webhook [options]\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nAuthentication:\n")
@@ -51,6 +98,23 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s --bearer \"your-jwt-token\"\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  # Full test with both auth types\n")
 		fmt.Fprintf(os.Stderr, "  %s --clerk \"token1\" --bearer \"token2\"\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Check that neither of two users can reach the other's data\n")
+		fmt.Fprintf(os.Stderr, "  %s --bearer \"jwt-user-a\" --bearer-b \"jwt-user-b\"\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Run custom endpoint checks alongside the built-in ones\n")
+		fmt.Fprintf(os.Stderr, "  %s --spec specs/new-routes.yaml\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Check live responses against the published OpenAPI spec\n")
+		fmt.Fprintf(os.Stderr, "  %s --contract --clerk \"token1\" --bearer \"token2\"\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Run eight checks at a time against a slow deployment\n")
+		fmt.Fprintf(os.Stderr, "  %s --url https://omnichat-7pu.pages.dev --bearer \"jwt\" --parallel 8\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Check that every advertised model and capability works\n")
+		fmt.Fprintf(os.Stderr, "  %s --models --clerk \"token1\" --parallel 4\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Check the V1 rate limit headers, 429s and window resets\n")
+		fmt.Fprintf(os.Stderr, "  %s --rate-limit --rate-limit-endpoint \"GET /api/v1/user/profile\"\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Write JUnit and JSON reports for CI\n")
+		fmt.Fprintf(os.Stderr, "  %s --report junit=results.xml --report json=results.json\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Record a production run and replay it locally\n")
+		fmt.Fprintf(os.Stderr, "  %s --url https://omnichat-7pu.pages.dev --bearer \"jwt\" --record run.cassette.json\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  %s --bearer replay --replay run.cassette.json\n\n", os.Args[0])
 		fmt.Fprintf(os.Stderr, "  # Test production API\n")
 		fmt.Fprintf(os.Stderr, "  %s --url https://omnichat-7pu.pages.dev --bearer \"jwt\"\n", os.Args[0])
 	}
@@ -69,27 +133,143 @@ func main() {
 		*clerkToken = *legacyToken
 	}
 
+	// Each mode replaces the normal run, so only one can be chosen
+	var modes []string
+	for _, mode := range []struct {
+		name string
+		set  bool
+	}{
+		{"--contract", *contract},
+		{"--rate-limit", *rateLimit},
+		{"--models", *models},
+		{"--battery-audit", *audit},
+	} {
+		if mode.set {
+			modes = append(modes, mode.name)
+		}
+	}
+	if len(modes) > 1 {
+		fmt.Fprintf(os.Stderr, "%s %s cannot be combined\n", colors.Error("Error:"), strings.Join(modes, ", "))
+		os.Exit(2)
+	}
+	if *billing && *stripeKey == "" {
+		fmt.Fprintf(os.Stderr, "%s --billing needs --stripe-webhook-secret\n", colors.Error("Error:"))
+		os.Exit(2)
+	}
+
+	// Validate report targets before running anything
+	var reportTargets []report.Target
+	for _, value := range reportFlags {
+		target, err := report.ParseTarget(value)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+			os.Exit(2)
+		}
+		reportTargets = append(reportTargets, target)
+	}
+
 	// Create configuration
 	config := &types.Config{
 		BaseURL: *baseURL,
 		Timeout: *timeout,
 		Verbose: *verbose,
+
+		SpecFiles:       specFiles,
+		SkipBuiltinSpec: *noBuiltin,
+		Contract:        *contract,
+		Parallel:        *parallel,
+		Models:          *models,
+		BatteryAudit:    *audit,
+		SecondBearer:    *jwtTokenB,
+		JWTSecret:       *jwtSecret,
+		RefreshToken:    *refresh,
+		Billing:         *billing,
+		Webhook:         types.WebhookConfig{Secret: *stripeKey, UserID: *stripeUser, SubscriptionID: *stripeSub},
+
+		RateLimit:          *rateLimit,
+		RateLimitEndpoints: rateLimitEndpoints,
+		BatteryAuditModels: auditModels,
+
+		Retry: types.RetryPolicy{
+			MaxAttempts: *maxAttempts,
+			BaseDelay:   *retryDelay,
+			MaxDelay:    *retryMaxDelay,
+			AllMethods:  *retryAllMethods,
+		},
+	}
+
+	// Set up cassette recording or replay
+	if *recordPath != "" && *replayPath != "" {
+		fmt.Fprintf(os.Stderr, "%s --record and --replay cannot be combined\n", colors.Error("Error:"))
+		os.Exit(2)
+	}
+	var recorder *client.Recorder
+	if *recordPath != "" {
+		recorder = client.NewRecorder(nil)
+		config.Transport = recorder
+	}
+	if *replayPath != "" {
+		cassette, err := client.LoadCassette(*replayPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+			os.Exit(2)
+		}
+		config.Transport = client.NewReplayer(cassette)
+		fmt.Printf("📼 Replaying %d interactions recorded against %s\n", len(cassette.Interactions), cassette.BaseURL)
 	}
 
 	// Create and run validator
 	fmt.Println(colors.BoldText("🚀 OmniChat API Validator"))
-	fmt.Printf("📍 Testing %d endpoints across 10 categories\n", 43)
 	fmt.Println(strings.Repeat("─", 60))
 	fmt.Println()
 	
+	startedAt := time.Now()
 	v := validator.NewValidator(config, *clerkToken, *jwtToken)
-	if err := v.RunAllTests(); err != nil {
+	runErr := v.RunAllTests()
+
+	// Save the cassette even when the run failed, that is when it matters
+	if recorder != nil {
+		if err := recorder.Save(*recordPath, *baseURL); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+			os.Exit(1)
+		}
+		fmt.Printf("📼 Recorded %d interactions to %s\n", recorder.Len(), *recordPath)
+	}
+
+	if err := runErr; err != nil {
 		fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
 		os.Exit(1)
 	}
 
+	// Write CI reports
+	run := report.Run{
+		BaseURL:   *baseURL,
+		StartedAt: startedAt,
+		Duration:  time.Since(startedAt),
+		Results:   v.Results(),
+	}
+	for _, target := range reportTargets {
+		if err := report.Write(target, run); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+			os.Exit(1)
+		}
+		fmt.Printf("📝 Wrote %s report to %s\n", target.Format, target.Path)
+	}
+
 	// Exit with non-zero if tests failed
 	if v.HasFailures() {
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
+
+// stringList collects the values of a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/omnichat/validator/internal/stripe"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/internal/validator"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+// runWebhook implements the webhook subcommand
+func runWebhook(args []string) {
+	fs := flag.NewFlagSet("webhook", flag.ExitOnError)
+	var (
+		baseURL    = fs.String("url", defaultURL, "Base URL of the API")
+		clerkToken = fs.String("clerk", "", "Clerk session token of the user, reads /api/user/tier and /api/battery")
+		jwtToken   = fs.String("bearer", "", "JWT Bearer token of the same user, names the user if --user-id is not set")
+		secret     = fs.String("secret", os.Getenv("STRIPE_WEBHOOK_SECRET"), "Stripe webhook signing secret of the API (default $STRIPE_WEBHOOK_SECRET)")
+		userID     = fs.String("user-id", "", "ID of the user the events are about")
+		planID     = fs.String("plan", "starter", "Plan of the subscription: "+strings.Join(planIDs(), ", "))
+		subID      = fs.String("subscription", "", "Test-mode Stripe subscription of --plan for the events to describe (default a made-up one, which only the mock accepts)")
+		timeout    = fs.Duration("timeout", defaultTimeout, "Request timeout")
+		verbose    = fs.Bool("verbose", false, "Enable verbose output")
+	)
+	var events stringList
+	fs.Var(&events, "event", "Event type to send, in order (repeatable, default "+strings.Join(stripe.EventTypes, ", ")+")")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", colors.BoldText("OmniChat Stripe Webhook Test"))
+		fmt.Fprintf(os.Stderr, "Send signed Stripe events to /api/stripe/webhook and check their effect\n")
+		fmt.Fprintf(os.Stderr, "on /api/user/tier and /api/battery\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s webhook [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nThe events change the user's tier and battery, so use a test user.\n")
+		fmt.Fprintf(os.Stderr, "The API looks the subscription of %s and %s\n", stripe.CheckoutSessionCompleted, stripe.SubscriptionUpdated)
+		fmt.Fprintf(os.Stderr, "events up in Stripe, so against a deployment pass --subscription.\n")
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  # A subscription's whole life against the mock server\n")
+		fmt.Fprintf(os.Stderr, "  %s webhook --url http://localhost:3000 --clerk mock-clerk-token --user-id user_mock --secret whsec_mock\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Only a checkout of the power plan, against a deployment\n")
+		fmt.Fprintf(os.Stderr, "  %s webhook --clerk \"token\" --bearer \"jwt\" --plan power --subscription sub_123 --event %s\n", os.Args[0], stripe.CheckoutSessionCompleted)
+	}
+	fs.Parse(args)
+
+	if *secret == "" {
+		fmt.Fprintf(os.Stderr, "%s webhook needs --secret or STRIPE_WEBHOOK_SECRET to sign events\n", colors.Error("Error:"))
+		os.Exit(2)
+	}
+	if _, ok := stripe.Plans[*planID]; !ok {
+		fmt.Fprintf(os.Stderr, "%s unknown plan %q, expected one of %s\n", colors.Error("Error:"), *planID, strings.Join(planIDs(), ", "))
+		os.Exit(2)
+	}
+	if len(events) == 0 {
+		events = stripe.EventTypes
+	}
+	for _, event := range events {
+		if !slices.Contains(stripe.EventTypes, event) {
+			fmt.Fprintf(os.Stderr, "%s unsupported event %q, expected one of %s\n", colors.Error("Error:"), event, strings.Join(stripe.EventTypes, ", "))
+			os.Exit(2)
+		}
+	}
+
+	config := &types.Config{
+		BaseURL:  *baseURL,
+		Timeout:  *timeout,
+		Verbose:  *verbose,
+		Webhooks: true,
+		Webhook: types.WebhookConfig{
+			Secret: *secret,
+			UserID: *userID,
+			PlanID: *planID,
+			Events: events,
+
+			SubscriptionID: *subID,
+		},
+	}
+
+	fmt.Println(colors.BoldText("💳 OmniChat Stripe Webhook Test"))
+	fmt.Printf("📍 Sending %d event(s) to %s\n", len(events), *baseURL)
+	fmt.Println(strings.Repeat("─", 60))
+
+	v := validator.NewValidator(config, *clerkToken, *jwtToken)
+	if err := v.RunAllTests(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+		os.Exit(1)
+	}
+	if v.HasFailures() {
+		os.Exit(1)
+	}
+}
+
+// planIDs returns the ids of stripe.Plans in order
+func planIDs() []string {
+	ids := make([]string, 0, len(stripe.Plans))
+	for id := range stripe.Plans {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/omnichat/validator/pkg/colors"
+	"github.com/omnichat/validator/pkg/omnichat"
+)
+
+const (
+	// historyMessages is how many messages /open shows
+	historyMessages = 6
+	// searchConversations is how many conversations /search reads the
+	// messages of, most recent first. Each is at least one request against
+	// the v1 rate limit of 100 a minute.
+	searchConversations = 20
+)
+
+// command runs a slash command and reports whether the REPL goes on
+func (s *session) command(line string) bool {
+	name, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "/help":
+		printHelp()
+	case "/quit", "/exit":
+		return false
+	case "/model":
+		s.switchModel(arg)
+	case "/new":
+		s.newConversation(arg)
+	case "/list":
+		s.list()
+	case "/open":
+		s.open(arg)
+	case "/attach":
+		s.attach(arg)
+	case "/search":
+		s.search(arg)
+	default:
+		fmt.Printf("%s unknown command %s, type /help for the list\n", colors.Warning("⚠️"), name)
+	}
+	return true
+}
+
+func printHelp() {
+	fmt.Println(colors.Header("📖", "Commands"))
+	fmt.Println("  /model [n|id]    Switch model, from the list if no model is given")
+	fmt.Println("  /new [title]     Start a new conversation")
+	fmt.Println("  /list            List your conversations")
+	fmt.Println("  /open <n|id>     Continue a conversation from /list or /search")
+	fmt.Println("  /attach <path>   Upload a file for the next message")
+	fmt.Println("  /search <query>  Search your conversations")
+	fmt.Println("  /quit            Leave")
+	fmt.Println("Anything else is sent to the model. Ctrl-C stops a reply.")
+}
+
+// Switch the model. A conversation keeps the model it was created with, so
+// a different model starts a new conversation.
+func (s *session) switchModel(ref string) {
+	if ref == "" {
+		if !s.pickModel() {
+			return
+		}
+	} else {
+		model, ok := s.findModel(ref)
+		if !ok {
+			fmt.Printf("%s unknown model %q\n", colors.Warning("⚠️"), ref)
+			return
+		}
+		s.model = model
+	}
+
+	if s.conversation != nil && s.conversation.Model != s.model.ID {
+		s.reset("")
+		fmt.Printf("🤖 Switched to %s, the next message starts a new conversation\n", colors.BoldText(s.model.Name))
+		return
+	}
+	fmt.Printf("🤖 Switched to %s\n", colors.BoldText(s.model.Name))
+}
+
+// Start a new conversation, created with the next message
+func (s *session) newConversation(title string) {
+	s.reset(title)
+	if title != "" {
+		fmt.Printf("✨ New conversation %q\n", title)
+		return
+	}
+	fmt.Println("✨ New conversation")
+}
+
+// reset leaves the current conversation. Its pending attachments belong to
+// it, so they are dropped.
+func (s *session) reset(title string) {
+	if len(s.attachments) > 0 {
+		fmt.Printf("%s dropped %d attachment(s) of the previous conversation\n", colors.Warning("⚠️"), len(s.attachments))
+	}
+	s.conversation = nil
+	s.title = title
+	s.attachments = nil
+}
+
+// List the conversations, most recent first
+func (s *session) list() {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	conversations, err := s.client.ListConversations(ctx)
+	if err != nil {
+		printError(fmt.Errorf("failed to list conversations: %w", err))
+		return
+	}
+	s.listed = conversations
+	if len(conversations) == 0 {
+		fmt.Println("No conversations yet")
+		return
+	}
+
+	fmt.Println(colors.Header("🗂️", "Conversations"))
+	for i, conversation := range conversations {
+		s.printListed(i, conversation, "")
+	}
+}
+
+// printListed prints a conversation of s.listed with a detail line, the
+// last message if detail is empty
+func (s *session) printListed(i int, conversation omnichat.Conversation, detail string) {
+	marker := " "
+	if s.conversation != nil && conversation.ID == s.conversation.ID {
+		marker = colors.Success("*")
+	}
+	fmt.Printf("  %s %3d. %s %s\n", marker, i+1, colors.BoldText(conversation.Title), colors.Info("("+conversation.Model+")"))
+	if detail == "" && conversation.LastMessage != nil {
+		detail = conversation.LastMessage.Role + ": " + conversation.LastMessage.Content
+	}
+	if detail != "" {
+		fmt.Printf("        %s\n", truncate(detail, 70))
+	}
+}
+
+// Continue a conversation of the last /list or /search, or any by id
+func (s *session) open(ref string) {
+	if ref == "" {
+		fmt.Printf("%s usage: /open <n|id>\n", colors.Warning("⚠️"))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	conversation, ok := s.findListed(ref)
+	if !ok {
+		conversations, err := s.client.ListConversations(ctx)
+		if err != nil {
+			printError(fmt.Errorf("failed to list conversations: %w", err))
+			return
+		}
+		for i := range conversations {
+			if conversations[i].ID == ref {
+				conversation, ok = conversations[i], true
+			}
+		}
+	}
+	if !ok {
+		fmt.Printf("%s no conversation %q, see /list\n", colors.Warning("⚠️"), ref)
+		return
+	}
+
+	messages, err := s.client.ListMessages(ctx, conversation.ID)
+	if err != nil {
+		printError(fmt.Errorf("failed to read messages: %w", err))
+		return
+	}
+
+	s.reset("")
+	s.conversation = &conversation
+	if model, ok := s.findModel(conversation.Model); ok {
+		s.model = model
+	}
+	fmt.Printf("📂 %s %s\n", colors.BoldText(conversation.Title), colors.Info("("+conversation.Model+")"))
+	if len(messages) > historyMessages {
+		fmt.Printf("   … %d earlier message(s)\n", len(messages)-historyMessages)
+		messages = messages[len(messages)-historyMessages:]
+	}
+	for _, message := range messages {
+		if message.Role == "user" {
+			fmt.Printf("%s%s\n", colors.Colorize(colors.Bold+colors.Green, "› "), message.Content)
+			continue
+		}
+		fmt.Println()
+		s.out.WriteString(message.Content)
+		s.out.Flush()
+		fmt.Print("\n\n")
+	}
+}
+
+// findListed looks a conversation up by its number or id in s.listed
+func (s *session) findListed(ref string) (omnichat.Conversation, bool) {
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(s.listed) {
+		return s.listed[n-1], true
+	}
+	for _, conversation := range s.listed {
+		if conversation.ID == ref {
+			return conversation, true
+		}
+	}
+	return omnichat.Conversation{}, false
+}
+
+// Upload a file for the next message. Uploads belong to a conversation, so
+// a new one is created first, titled after the file unless /new named it.
+func (s *session) attach(path string) {
+	if path == "" {
+		fmt.Printf("%s usage: /attach <path>\n", colors.Warning("⚠️"))
+		return
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	name := filepath.Base(path)
+	if err := s.ensureConversation(ctx, name); err != nil {
+		printError(err)
+		return
+	}
+	attachment, err := s.client.Upload(ctx, s.conversation.ID, name, file)
+	if err != nil {
+		printError(fmt.Errorf("failed to upload %s: %w", name, err))
+		return
+	}
+	s.attachments = append(s.attachments, *attachment)
+	fmt.Printf("📎 Attached %s (%s, %d bytes), sent with the next message\n", attachment.FileName, attachment.FileType, attachment.FileSize)
+}
+
+// Search the titles and messages of the conversations. The v1 API has no
+// search endpoint, so the messages of the most recent conversations are
+// read and matched here. Each request has its own deadline, and a 429 ends
+// the search with the matches found so far.
+func (s *session) search(query string) {
+	if query == "" {
+		fmt.Printf("%s usage: /search <query>\n", colors.Warning("⚠️"))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	conversations, err := s.client.ListConversations(ctx)
+	cancel()
+	if err != nil {
+		printError(fmt.Errorf("failed to list conversations: %w", err))
+		return
+	}
+	if len(conversations) > searchConversations {
+		conversations = conversations[:searchConversations]
+	}
+
+	needle := strings.ToLower(query)
+	var hits []omnichat.Conversation
+	var details []string
+	for n, conversation := range conversations {
+		if strings.Contains(strings.ToLower(conversation.Title), needle) {
+			hits = append(hits, conversation)
+			details = append(details, "")
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		messages, err := s.client.ListMessages(ctx, conversation.ID)
+		cancel()
+		if errors.Is(err, omnichat.ErrRateLimited) {
+			fmt.Printf("%s rate limited, showing matches among the first %d conversations\n", colors.Warning("⚠️"), n)
+			break
+		}
+		if err != nil {
+			printError(fmt.Errorf("failed to read messages of %s: %w", conversation.ID, err))
+			return
+		}
+		for _, message := range messages {
+			if i := strings.Index(strings.ToLower(message.Content), needle); i >= 0 {
+				hits = append(hits, conversation)
+				details = append(details, message.Role+": "+snippet(message.Content, i))
+				break
+			}
+		}
+	}
+
+	s.listed = hits
+	if len(hits) == 0 {
+		fmt.Printf("No conversations match %q\n", query)
+		return
+	}
+	fmt.Println(colors.Header("🔍", fmt.Sprintf("%d conversation(s) match %q", len(hits), query)))
+	for i, conversation := range hits {
+		s.printListed(i, conversation, details[i])
+	}
+}
+
+// snippet returns the text around byte offset i of s
+func snippet(s string, i int) string {
+	start := max(i-20, 0)
+	for start > 0 && start < len(s) && !utf8.RuneStart(s[start]) {
+		start--
+	}
+	if start > 0 {
+		return "…" + s[start:]
+	}
+	return s
+}
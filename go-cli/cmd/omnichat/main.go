@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/pkg/colors"
+	"github.com/omnichat/validator/pkg/omnichat"
+)
+
+// requestTimeout bounds every request but the message stream, which runs
+// until the reply is complete or Ctrl-C
+const requestTimeout = 30 * time.Second
+
+// session is the state of the REPL
+type session struct {
+	client   *omnichat.Client
+	baseURL  string
+	hasClerk bool
+	in       *bufio.Scanner
+	out      *markdownWriter
+
+	models []omnichat.Model
+	model  omnichat.Model
+
+	// conversation is nil until the first message, or the first attachment,
+	// of a new conversation
+	conversation *omnichat.Conversation
+	// title of the next conversation, the first message if empty
+	title string
+	// attachments uploaded for the next message
+	attachments []omnichat.Attachment
+	// listed are the conversations of the last /list or /search, which
+	// /open picks from by number
+	listed []omnichat.Conversation
+}
+
+func main() {
+	var (
+		baseURL      = flag.String("url", omnichat.DefaultBaseURL, "Base URL of the API")
+		bearer       = flag.String("bearer", os.Getenv("OMNICHAT_TOKEN"), "JWT Bearer token (default $OMNICHAT_TOKEN)")
+		refreshToken = flag.String("refresh-token", os.Getenv("OMNICHAT_REFRESH_TOKEN"), "Refresh token, renews the Bearer token when it expires (default $OMNICHAT_REFRESH_TOKEN)")
+		clerkToken   = flag.String("clerk", os.Getenv("OMNICHAT_CLERK_TOKEN"), "Clerk session token, lists the models of /api/models, which are cached for later sessions (default $OMNICHAT_CLERK_TOKEN)")
+		modelID      = flag.String("model", "", "Model to chat with, picked from /api/models if not set")
+		plain        = flag.Bool("plain", false, "Print replies as they arrive, without colors or markdown styling")
+	)
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "%s\n", colors.BoldText("OmniChat"))
+		fmt.Fprintf(os.Stderr, "Chat with the OmniChat models from the terminal\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nType /help in the chat for its commands.\n")
+		fmt.Fprintf(os.Stderr, "\nExamples:\n")
+		fmt.Fprintf(os.Stderr, "  # Chat against the mock server\n")
+		fmt.Fprintf(os.Stderr, "  %s --url http://localhost:3000 --bearer mock-jwt-token --clerk mock-clerk-token\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "  # Skip the model picker\n")
+		fmt.Fprintf(os.Stderr, "  OMNICHAT_TOKEN=\"jwt\" %s --model gpt-4o-mini\n", os.Args[0])
+	}
+	flag.Parse()
+
+	if *bearer == "" {
+		fmt.Fprintf(os.Stderr, "%s %s needs --bearer or OMNICHAT_TOKEN to sign in\n", colors.Error("Error:"), os.Args[0])
+		os.Exit(2)
+	}
+
+	s := &session{
+		client: omnichat.New(omnichat.Options{
+			BaseURL:      *baseURL,
+			AccessToken:  *bearer,
+			RefreshToken: *refreshToken,
+			ClerkToken:   *clerkToken,
+		}),
+		baseURL:  *baseURL,
+		hasClerk: *clerkToken != "",
+		in:       bufio.NewScanner(os.Stdin),
+		out:      newMarkdownWriter(os.Stdout, !*plain),
+	}
+	s.in.Buffer(make([]byte, 0, 64<<10), 1<<20)
+
+	if err := s.start(*modelID); err != nil {
+		fmt.Fprintf(os.Stderr, "%s %s\n", colors.Error("Error:"), err.Error())
+		os.Exit(1)
+	}
+	s.run()
+}
+
+// start signs in and picks the model. A model given by id is used even if
+// no model list is available.
+func (s *session) start(modelID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	profile, err := s.client.GetProfile(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to sign in: %w", err)
+	}
+	if err := s.loadModels(ctx); err != nil {
+		if modelID == "" {
+			return err
+		}
+		s.models = []omnichat.Model{{ID: modelID, Name: modelID}}
+	}
+	if len(s.models) == 0 {
+		return errors.New("the API has no models")
+	}
+
+	fmt.Println(colors.BoldText("💬 OmniChat"))
+	fmt.Printf("👤 Signed in as %s (%s tier", profile.Email, profile.Tier)
+	if profile.Battery != nil {
+		fmt.Printf(", %d battery units", profile.Battery.TotalBalance)
+	}
+	fmt.Println(")")
+
+	if modelID != "" {
+		model, ok := s.findModel(modelID)
+		if !ok {
+			return fmt.Errorf("unknown model %q", modelID)
+		}
+		s.model = model
+	} else if !s.pickModel() {
+		return errors.New("no model picked")
+	}
+	fmt.Printf("🤖 Chatting with %s, type /help for commands\n", colors.BoldText(s.model.Name))
+	return nil
+}
+
+// run reads lines until /quit or the end of the input
+func (s *session) run() {
+	for {
+		fmt.Print(colors.Colorize(colors.Bold+colors.Green, "› "))
+		if !s.in.Scan() {
+			fmt.Println()
+			return
+		}
+		line := strings.TrimSpace(s.in.Text())
+		switch {
+		case line == "":
+		case strings.HasPrefix(line, "/"):
+			if !s.command(line) {
+				return
+			}
+		default:
+			s.send(line)
+		}
+	}
+}
+
+// send sends a message and prints the reply as it streams. Ctrl-C stops
+// the reply.
+func (s *session) send(content string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := s.ensureConversation(ctx, content); err != nil {
+		printError(err)
+		return
+	}
+	req := omnichat.MessageRequest{Content: content}
+	for _, attachment := range s.attachments {
+		req.AttachmentIDs = append(req.AttachmentIDs, attachment.ID)
+	}
+	stream, err := s.client.SendMessage(ctx, s.conversation.ID, req)
+	if err != nil {
+		printError(err)
+		return
+	}
+	defer stream.Close()
+	s.attachments = nil
+
+	fmt.Println()
+	for {
+		event, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.out.Flush()
+			if ctx.Err() != nil {
+				fmt.Println(colors.Warning("\n⏹  Stopped"))
+			} else {
+				printError(err)
+			}
+			return
+		}
+		if event.Type == omnichat.StreamEventContentChunk {
+			s.out.WriteString(event.Content)
+		}
+	}
+	s.out.Flush()
+	fmt.Print("\n\n")
+}
+
+// ensureConversation creates the conversation if there is none yet, titled
+// after /new or the first message
+func (s *session) ensureConversation(ctx context.Context, firstMessage string) error {
+	if s.conversation != nil {
+		return nil
+	}
+	title := s.title
+	if title == "" {
+		title = truncate(firstMessage, 50)
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	conversation, err := s.client.CreateConversation(ctx, omnichat.ConversationRequest{Title: title, Model: s.model.ID})
+	if err != nil {
+		return fmt.Errorf("failed to create conversation: %w", err)
+	}
+	s.conversation = conversation
+	s.title = ""
+	return nil
+}
+
+// pickModel lists the models and reads a number, the first model if the
+// line is empty. It reports whether a model was picked.
+func (s *session) pickModel() bool {
+	fmt.Println(colors.Header("🤖", "Models"))
+	provider := ""
+	for i, model := range s.models {
+		if model.Provider != provider {
+			provider = model.Provider
+			fmt.Printf("  %s\n", colors.Subheader("•", provider))
+		}
+		marker := " "
+		if model.ID == s.model.ID {
+			marker = colors.Success("*")
+		}
+		fmt.Printf("  %s %3d. %s %s\n", marker, i+1, model.Name, colors.Info("("+model.ID+")"))
+	}
+
+	for {
+		fmt.Printf("Model [1-%d, default 1]: ", len(s.models))
+		if !s.in.Scan() {
+			fmt.Println()
+			return false
+		}
+		answer := strings.TrimSpace(s.in.Text())
+		if answer == "" {
+			answer = "1"
+		}
+		if model, ok := s.findModel(answer); ok {
+			s.model = model
+			return true
+		}
+		fmt.Println(colors.Warning("Enter a number from the list or a model id"))
+	}
+}
+
+// findModel looks a model up by its number in s.models or its id
+func (s *session) findModel(ref string) (omnichat.Model, bool) {
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(s.models) {
+		return s.models[n-1], true
+	}
+	for _, model := range s.models {
+		if model.ID == ref {
+			return model, true
+		}
+	}
+	return omnichat.Model{}, false
+}
+
+func printError(err error) {
+	fmt.Printf("%s %s\n", colors.Error("Error:"), err.Error())
+}
+
+// truncate shortens s to at most n runes on one line
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
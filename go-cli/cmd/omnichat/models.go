@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/omnichat/validator/pkg/colors"
+	"github.com/omnichat/validator/pkg/omnichat"
+)
+
+// errNoClerk explains why the models cannot be listed without --clerk
+var errNoClerk = errors.New("listing models needs --clerk or OMNICHAT_CLERK_TOKEN, /api/models only accepts Clerk sessions")
+
+// modelCache is the last model list read from /api/models. The route only
+// accepts Clerk sessions, so later sessions can start without one.
+type modelCache struct {
+	BaseURL string           `json:"baseUrl"`
+	SavedAt time.Time        `json:"savedAt"`
+	Models  []omnichat.Model `json:"models"`
+}
+
+// loadModels lists the models with the Clerk token and caches them.
+// Without a Clerk token, or if listing fails, the models cached for the
+// same API are used.
+func (s *session) loadModels(ctx context.Context) error {
+	listErr := errNoClerk
+	if s.hasClerk {
+		models, err := s.client.ListModels(ctx)
+		if err == nil {
+			s.models = models
+			if err := saveModelCache(modelCache{BaseURL: s.baseURL, SavedAt: time.Now(), Models: models}); err != nil {
+				fmt.Printf("%s failed to cache the models: %v\n", colors.Warning("⚠️"), err)
+			}
+			return nil
+		}
+		listErr = fmt.Errorf("failed to list models: %w", err)
+	}
+
+	cache, err := loadModelCache(s.baseURL)
+	if err != nil {
+		return listErr
+	}
+	if s.hasClerk {
+		fmt.Printf("%s %v\n", colors.Warning("⚠️"), listErr)
+	}
+	fmt.Printf("📋 Using the models cached on %s\n", cache.SavedAt.Local().Format("2006-01-02 15:04"))
+	s.models = cache.Models
+	return nil
+}
+
+// modelCachePath is where the model list is cached
+func modelCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "omnichat", "models.json"), nil
+}
+
+// loadModelCache reads the cached models of the API at baseURL
+func loadModelCache(baseURL string) (modelCache, error) {
+	var cache modelCache
+	path, err := modelCachePath()
+	if err != nil {
+		return cache, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cache, err
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return cache, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if cache.BaseURL != baseURL || len(cache.Models) == 0 {
+		return cache, fmt.Errorf("no models cached for %s", baseURL)
+	}
+	return cache, nil
+}
+
+func saveModelCache(cache modelCache) error {
+	path, err := modelCachePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+// fence opens and closes a code block
+const fence = "```"
+
+// markdownWriter styles markdown as it streams in: headings and **strong**
+// text are bold, code blocks and `code` cyan. The text itself is kept,
+// markers included, so it can be copied as markdown. Only the few
+// characters that may start a marker are held back until the next chunk
+// decides them.
+type markdownWriter struct {
+	w     io.Writer
+	color bool
+
+	lineStart bool   // nothing of the current line is written yet
+	prefix    string // held start of the line, a possible fence or heading
+	star      bool   // held '*', a possible **
+
+	inFence   bool
+	inCode    bool
+	inStrong  bool
+	inHeading bool
+}
+
+func newMarkdownWriter(w io.Writer, color bool) *markdownWriter {
+	return &markdownWriter{w: w, color: color, lineStart: true}
+}
+
+// WriteString writes a chunk of the reply
+func (m *markdownWriter) WriteString(s string) {
+	if !m.color {
+		io.WriteString(m.w, s)
+		return
+	}
+	var out strings.Builder
+	for _, r := range s {
+		m.rune(&out, r)
+	}
+	io.WriteString(m.w, out.String())
+}
+
+// Flush writes what is held back and ends the styling, for the end of a
+// reply
+func (m *markdownWriter) Flush() {
+	if m.color {
+		var out strings.Builder
+		m.release(&out)
+		if m.inFence || m.inCode || m.inStrong || m.inHeading {
+			out.WriteString(colors.Reset)
+		}
+		io.WriteString(m.w, out.String())
+	}
+	*m = markdownWriter{w: m.w, color: m.color, lineStart: true}
+}
+
+func (m *markdownWriter) rune(out *strings.Builder, r rune) {
+	if m.lineStart {
+		candidate := m.prefix + string(r)
+		if strings.HasPrefix(fence, candidate) || strings.Trim(candidate, "#") == "" {
+			m.prefix = candidate
+			return
+		}
+		m.lineStart = false
+		m.startLine(out, r)
+	}
+
+	if r == '\n' {
+		m.release(out)
+		if m.inCode || m.inStrong || m.inHeading {
+			m.inCode, m.inStrong, m.inHeading = false, false, false
+			m.restyle(out)
+		}
+		out.WriteRune(r)
+		m.lineStart = true
+		return
+	}
+	if m.inFence || (m.inCode && r != '`') {
+		out.WriteRune(r)
+		return
+	}
+
+	if m.star {
+		m.star = false
+		if r == '*' {
+			if m.inStrong {
+				out.WriteString("**")
+				m.inStrong = false
+				m.restyle(out)
+			} else {
+				m.inStrong = true
+				m.restyle(out)
+				out.WriteString("**")
+			}
+			return
+		}
+		out.WriteByte('*')
+	}
+
+	switch r {
+	case '*':
+		m.star = true
+	case '`':
+		if m.inCode {
+			out.WriteRune(r)
+			m.inCode = false
+			m.restyle(out)
+		} else {
+			m.inCode = true
+			m.restyle(out)
+			out.WriteRune(r)
+		}
+	default:
+		out.WriteRune(r)
+	}
+}
+
+// startLine writes the held prefix once r shows what the line is
+func (m *markdownWriter) startLine(out *strings.Builder, r rune) {
+	prefix := m.prefix
+	m.prefix = ""
+
+	switch {
+	case prefix == fence:
+		if !m.inFence {
+			m.inFence = true
+			m.restyle(out)
+			out.WriteString(prefix)
+		} else {
+			out.WriteString(prefix)
+			m.inFence = false
+			m.restyle(out)
+		}
+	case m.inFence:
+		out.WriteString(prefix)
+	case prefix != "" && prefix[0] == '#' && r == ' ':
+		m.inHeading = true
+		m.restyle(out)
+		out.WriteString(prefix)
+	default:
+		out.WriteString(prefix)
+	}
+}
+
+// release writes a held prefix or star as plain text
+func (m *markdownWriter) release(out *strings.Builder) {
+	out.WriteString(m.prefix)
+	m.prefix = ""
+	if m.star {
+		out.WriteByte('*')
+		m.star = false
+	}
+}
+
+// restyle switches the terminal to the current style
+func (m *markdownWriter) restyle(out *strings.Builder) {
+	out.WriteString(colors.Reset)
+	if m.inStrong || m.inHeading {
+		out.WriteString(colors.Bold)
+	}
+	if m.inFence || m.inCode {
+		out.WriteString(colors.Cyan)
+	}
+}
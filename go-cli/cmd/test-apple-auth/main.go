@@ -2,31 +2,122 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"os"
+	"strings"
 	"time"
 
+	"github.com/omnichat/validator/internal/jwt"
 	"github.com/omnichat/validator/internal/types"
 	"github.com/omnichat/validator/pkg/colors"
 )
 
+const (
+	// appleIssuer is the iss claim of every Apple ID token
+	appleIssuer = "https://appleid.apple.com"
+
+	// defaultClientID is the API's APPLE_CLIENT_ID fallback
+	defaultClientID = "com.example.omnichat"
+
+	// jwksPath mirrors https://appleid.apple.com/auth/keys
+	jwksPath = "/auth/keys"
+)
+
 type AppleAuthTestCase struct {
 	Name           string
 	Request        interface{}
 	ExpectedStatus int
 	Description    string
+	// Check verifies the body of a response with the expected status and
+	// returns the failures, if any
+	Check func(body []byte) []string
+}
+
+// appleIdentity is the Apple account the minted ID tokens describe
+type appleIdentity struct {
+	sub      string
+	email    string
+	audience string
+	lifetime time.Duration
+	nonce    string
+}
+
+// appleSigner stands in for Apple: it signs ID tokens with a key that the
+// local JWKS endpoint publishes
+type appleSigner struct {
+	key *rsa.PrivateKey
+	kid string
 }
 
 func main() {
 	// Parse command-line arguments
 	baseURL := flag.String("url", "http://localhost:3002", "Base URL of the API")
+	jwksAddr := flag.String("jwks-addr", "localhost:3003", "Address to serve the local Apple JWKS on, at "+jwksPath)
+	sub := flag.String("sub", "", "Apple user id (sub) of the minted ID token (default: new for every run)")
+	email := flag.String("email", "apple-test@privaterelay.appleid.com", "Email claim of the minted ID token")
+	aud := flag.String("aud", defaultClientID, "Audience of the minted ID token, the server's APPLE_CLIENT_ID")
+	exp := flag.Duration("exp", 10*time.Minute, "Lifetime of the minted ID token")
+	nonce := flag.String("nonce", "", "Nonce claim of the minted ID token (omitted when empty)")
 	flag.Parse()
 
+	identity := appleIdentity{sub: *sub, email: *email, audience: *aud, lifetime: *exp, nonce: *nonce}
+	if identity.sub == "" {
+		identity.sub = newAppleSubject()
+	}
+
 	fmt.Printf("%s\n\n", colors.Header("🍎", fmt.Sprintf("Testing Sign in with Apple endpoint at %s", *baseURL)))
 
+	signer, err := newAppleSigner()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", colors.Error("Error:"), err)
+		os.Exit(2)
+	}
+	// A key the JWKS does not publish, for the forged token case
+	forger, err := newAppleSigner()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", colors.Error("Error:"), err)
+		os.Exit(2)
+	}
+
+	jwksServer, jwksURL, err := serveJWKS(*jwksAddr, signer)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s %v\n", colors.Error("Error:"), err)
+		os.Exit(2)
+	}
+	fmt.Printf("%s Serving Apple keys at %s\n", colors.Info("🔑"), jwksURL)
+	fmt.Printf("   Point the server's Apple JWKS URI at it (APPLE_JWKS_URI under next dev, or --apple-jwks for omnichat-mock)\n\n")
+
+	expired := identity
+	expired.lifetime = -time.Hour
+	wrongAudience := identity
+	wrongAudience.audience = identity.audience + ".wrong"
+
+	var expiredToken, wrongAudienceToken, forgedToken, validToken string
+	for _, m := range []struct {
+		token    *string
+		signer   *appleSigner
+		identity appleIdentity
+	}{
+		{&expiredToken, signer, expired},
+		{&wrongAudienceToken, signer, wrongAudience},
+		{&forgedToken, forger, identity},
+		{&validToken, signer, identity},
+	} {
+		if *m.token, err = m.signer.mint(m.identity); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", colors.Error("Error:"), err)
+			os.Exit(2)
+		}
+	}
+
 	// Define test cases
 	testCases := []AppleAuthTestCase{
 		{
@@ -44,38 +135,60 @@ func main() {
 			Description:    "Should return 401 when idToken is invalid",
 		},
 		{
-			Name: "Valid idToken format (would need real token)",
-			Request: types.AppleAuthRequest{
-				IDToken: "eyJhbGciOiJSUzI1NiIsInR5cCI6IkpXVCJ9...",
-				User: &types.AppleUserData{
-					Email: "test@example.com",
-					Name: &types.AppleUserName{
-						FirstName: "Test",
-						LastName:  "User",
-					},
-				},
-			},
-			ExpectedStatus: 401, // Will still fail without valid Apple token
-			Description:    "Shows the expected request format for Sign in with Apple",
+			Name:           "Expired idToken",
+			Request:        appleRequest(expiredToken),
+			ExpectedStatus: 401,
+			Description:    "Should return 401 when idToken has expired",
+		},
+		{
+			Name:           "Wrong audience",
+			Request:        appleRequest(wrongAudienceToken),
+			ExpectedStatus: 401,
+			Description:    "Should return 401 when idToken was issued to another client",
+		},
+		{
+			Name:           "Unknown signing key",
+			Request:        appleRequest(forgedToken),
+			ExpectedStatus: 401,
+			Description:    "Should return 401 when idToken is signed by a key missing from the JWKS",
+		},
+		{
+			Name:           "Valid idToken",
+			Request:        appleRequest(validToken),
+			ExpectedStatus: 200,
+			Description:    "Should sign in and return tokens for " + identity.email,
+			Check:          func(body []byte) []string { return checkAuthResponse(body, identity) },
 		},
 	}
 
 	// Run tests
 	client := &http.Client{Timeout: 10 * time.Second}
-	
+
+	failed := 0
 	for _, tc := range testCases {
-		runTest(client, *baseURL, tc)
+		if !runTest(client, *baseURL, tc) {
+			failed++
+		}
 		fmt.Println()
 	}
+	if err := jwksServer.Shutdown(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed to stop the JWKS server: %v\n", colors.Warning("⚠️"), err)
+	}
 
 	// Print integration notes
 	printIntegrationNotes()
+
+	if failed > 0 {
+		fmt.Printf("\n%s\n", colors.Error(fmt.Sprintf("❌ %d of %d tests failed", failed, len(testCases))))
+		os.Exit(1)
+	}
+	fmt.Printf("\n%s\n", colors.Success(fmt.Sprintf("✅ All %d tests passed", len(testCases))))
 }
 
-func runTest(client *http.Client, baseURL string, tc AppleAuthTestCase) {
+func runTest(client *http.Client, baseURL string, tc AppleAuthTestCase) bool {
 	fmt.Printf("%s Test: %s\n", colors.Info("📝"), tc.Name)
 	fmt.Printf("   Description: %s\n", tc.Description)
-	
+
 	// Pretty print request
 	reqJSON, _ := json.MarshalIndent(tc.Request, "   ", "  ")
 	fmt.Printf("   Request body: %s\n", string(reqJSON))
@@ -84,13 +197,13 @@ func runTest(client *http.Client, baseURL string, tc AppleAuthTestCase) {
 	body, err := json.Marshal(tc.Request)
 	if err != nil {
 		fmt.Printf("   %s Error marshaling request: %v\n", colors.Error("❌"), err)
-		return
+		return false
 	}
 
 	req, err := http.NewRequest("POST", baseURL+"/api/v1/auth/apple", bytes.NewBuffer(body))
 	if err != nil {
 		fmt.Printf("   %s Error creating request: %v\n", colors.Error("❌"), err)
-		return
+		return false
 	}
 	req.Header.Set("Content-Type", "application/json")
 
@@ -98,7 +211,7 @@ func runTest(client *http.Client, baseURL string, tc AppleAuthTestCase) {
 	resp, err := client.Do(req)
 	if err != nil {
 		fmt.Printf("   %s Error sending request: %v\n", colors.Error("❌"), err)
-		return
+		return false
 	}
 	defer resp.Body.Close()
 
@@ -106,13 +219,14 @@ func runTest(client *http.Client, baseURL string, tc AppleAuthTestCase) {
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		fmt.Printf("   %s Error reading response: %v\n", colors.Error("❌"), err)
-		return
+		return false
 	}
 
 	fmt.Printf("   Response status: %d %s\n", resp.StatusCode, http.StatusText(resp.StatusCode))
 
 	// Check if status matches expected
-	if resp.StatusCode == tc.ExpectedStatus {
+	passed := resp.StatusCode == tc.ExpectedStatus
+	if passed {
 		fmt.Printf("   %s Status matches expected: %d\n", colors.Success("✅"), tc.ExpectedStatus)
 	} else {
 		fmt.Printf("   %s Expected status %d, got %d\n", colors.Error("❌"), tc.ExpectedStatus, resp.StatusCode)
@@ -127,6 +241,141 @@ func runTest(client *http.Client, baseURL string, tc AppleAuthTestCase) {
 			fmt.Printf("   Response: %s\n", string(respBody))
 		}
 	}
+
+	if passed && tc.Check != nil {
+		failures := tc.Check(respBody)
+		for _, failure := range failures {
+			fmt.Printf("   %s %s\n", colors.Error("❌"), failure)
+		}
+		if len(failures) == 0 {
+			fmt.Printf("   %s Response fields match the signed in user\n", colors.Success("✅"))
+		}
+		passed = len(failures) == 0
+	}
+	return passed
+}
+
+// checkAuthResponse decodes a sign in response strictly into
+// types.AuthResponse and checks every field against the identity
+func checkAuthResponse(body []byte, identity appleIdentity) []string {
+	var auth types.AuthResponse
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&auth); err != nil {
+		return []string{fmt.Sprintf("response does not decode into AuthResponse: %v", err)}
+	}
+
+	var failures []string
+	if auth.RefreshToken == "" {
+		failures = append(failures, "refreshToken: expected a token, got an empty string")
+	}
+	if auth.ExpiresIn <= 0 {
+		failures = append(failures, fmt.Sprintf("expiresIn: expected a positive lifetime, got %d", auth.ExpiresIn))
+	}
+	if auth.TokenType != "Bearer" {
+		failures = append(failures, fmt.Sprintf("tokenType: expected %q, got %q", "Bearer", auth.TokenType))
+	}
+	if auth.User == nil || auth.User.ID == "" {
+		return append(failures, "user.id: expected the signed in user's id")
+	}
+	if identity.email != "" && auth.User.Email != identity.email {
+		failures = append(failures, fmt.Sprintf("user.email: expected %q, got %q", identity.email, auth.User.Email))
+	}
+
+	// The access token must be issued to the user the response names
+	claims, err := jwt.Decode(auth.AccessToken)
+	if err != nil {
+		return append(failures, fmt.Sprintf("accessToken: %v", err))
+	}
+	if claims["sub"] != auth.User.ID {
+		failures = append(failures, fmt.Sprintf("accessToken: expected sub %q, got %v", auth.User.ID, claims["sub"]))
+	}
+	if exp, ok := claims["exp"].(float64); !ok || int64(exp) <= time.Now().Unix() {
+		failures = append(failures, fmt.Sprintf("accessToken: expected an exp in the future, got %v", claims["exp"]))
+	}
+	return failures
+}
+
+func newAppleSigner() (*appleSigner, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	id := make([]byte, 5)
+	rand.Read(id)
+	return &appleSigner{key: key, kid: hex.EncodeToString(id)}, nil
+}
+
+// mint returns an ID token with the claims Apple sets for an identity
+func (s *appleSigner) mint(identity appleIdentity) (string, error) {
+	now := time.Now()
+	claims := jwt.Claims{
+		"iss":             appleIssuer,
+		"aud":             identity.audience,
+		"sub":             identity.sub,
+		"iat":             now.Unix(),
+		"exp":             now.Add(identity.lifetime).Unix(),
+		"auth_time":       now.Unix(),
+		"nonce_supported": true,
+	}
+	if identity.email != "" {
+		claims["email"] = identity.email
+		claims["email_verified"] = "true"
+		claims["is_private_email"] = fmt.Sprint(strings.HasSuffix(identity.email, "@privaterelay.appleid.com"))
+	}
+	if identity.nonce != "" {
+		claims["nonce"] = identity.nonce
+	}
+
+	token, err := jwt.SignRS256(claims, s.key, s.kid)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign ID token: %w", err)
+	}
+	return token, nil
+}
+
+// serveJWKS publishes the signer's public key in the background and
+// returns the server and the URL of the key set
+func serveJWKS(addr string, signer *appleSigner) (*http.Server, string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to serve JWKS: %w", err)
+	}
+
+	keys := jwt.JWKS{Keys: []jwt.JWK{jwt.PublicJWK(&signer.key.PublicKey, signer.kid)}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+jwksPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(keys)
+	})
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "%s JWKS server stopped: %v\n", colors.Error("Error:"), err)
+		}
+	}()
+
+	return server, "http://" + listener.Addr().String() + jwksPath, nil
+}
+
+// newAppleSubject returns an id shaped like Apple's, so every run signs in
+// a new user unless --sub is set
+func newAppleSubject() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return fmt.Sprintf("000000.%s.0000", hex.EncodeToString(id))
+}
+
+func appleRequest(idToken string) types.AppleAuthRequest {
+	return types.AppleAuthRequest{
+		IDToken: idToken,
+		User: &types.AppleUserData{
+			Name: &types.AppleUserName{
+				FirstName: "Test",
+				LastName:  "User",
+			},
+		},
+	}
 }
 
 func printIntegrationNotes() {
@@ -135,9 +384,11 @@ func printIntegrationNotes() {
 	fmt.Println("   - idToken: The JWT token from Apple Sign In")
 	fmt.Println("   - user: Optional user data from first-time sign in")
 	fmt.Println("\n2. The idToken is verified against Apple's public keys")
+	fmt.Println("   - This tool serves its own keys; the server must fetch them from --jwks-addr")
+	fmt.Println("   - iss must be https://appleid.apple.com and aud the server's APPLE_CLIENT_ID")
 	fmt.Println("\n3. On success, returns:")
 	fmt.Println("   - accessToken: JWT for API access")
 	fmt.Println("   - refreshToken: Token to refresh access")
 	fmt.Println("   - user: User profile information")
 	fmt.Println("\n4. The endpoint creates or updates user records in the database")
-}
\ No newline at end of file
+}
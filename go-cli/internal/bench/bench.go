@@ -0,0 +1,148 @@
+// Package bench load-tests OmniChat endpoints and summarizes latency,
+// status codes and throughput per endpoint.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/omnichat/validator/internal/client"
+)
+
+// Target is one endpoint to benchmark
+type Target struct {
+	Method string
+	Path   string
+	Client *client.APIClient
+}
+
+// String returns the target as "METHOD /path"
+func (t Target) String() string {
+	return t.Method + " " + t.Path
+}
+
+// ParseTarget parses a "METHOD /path" value; the method defaults to GET
+func ParseTarget(value string) (Target, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(value), " ")
+	if !ok {
+		method, path = "GET", method
+	}
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "/") {
+		return Target{}, fmt.Errorf("invalid endpoint %q, expected \"METHOD /path\"", value)
+	}
+	return Target{Method: strings.ToUpper(method), Path: path}, nil
+}
+
+// Options configures a benchmark run. Each target gets its own workers
+// and rate so a slow endpoint does not hold back the others.
+type Options struct {
+	Targets     []Target
+	Concurrency int           // workers per target
+	RPS         float64       // request rate per target, 0 for unlimited
+	Duration    time.Duration // how long to send requests
+}
+
+// sample is the outcome of a single request
+type sample struct {
+	status  int // 0 for transport errors
+	latency time.Duration
+}
+
+// Run benchmarks every target concurrently until the duration elapses and
+// returns per-endpoint statistics
+func Run(opts Options) (*Report, error) {
+	if len(opts.Targets) == 0 {
+		return nil, fmt.Errorf("no endpoints to benchmark")
+	}
+	if opts.Concurrency < 1 {
+		return nil, fmt.Errorf("concurrency must be at least 1")
+	}
+	if opts.Duration <= 0 {
+		return nil, fmt.Errorf("duration must be positive")
+	}
+
+	start := time.Now()
+	deadline := start.Add(opts.Duration)
+	samples := make([][]sample, len(opts.Targets))
+
+	var wg sync.WaitGroup
+	for i, target := range opts.Targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			samples[i] = hammer(target, opts, deadline)
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	report := &Report{
+		StartedAt:   start,
+		Duration:    elapsed,
+		Concurrency: opts.Concurrency,
+		RPS:         opts.RPS,
+	}
+	for i, target := range opts.Targets {
+		report.Endpoints = append(report.Endpoints, summarize(target.String(), samples[i], elapsed))
+	}
+	return report, nil
+}
+
+// hammer sends requests to one target from opts.Concurrency workers,
+// paced by opts.RPS, until the deadline
+func hammer(target Target, opts Options, deadline time.Time) []sample {
+	var ticks <-chan time.Time
+	if opts.RPS > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.RPS))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	var mu sync.Mutex
+	var samples []sample
+	var wg sync.WaitGroup
+	for range opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				if ticks != nil {
+					select {
+					case <-ticks:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if !time.Now().Before(deadline) {
+					return
+				}
+
+				s := send(target)
+				mu.Lock()
+				samples = append(samples, s)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return samples
+}
+
+// send performs one request, timing it until the body is fully read
+func send(target Target) sample {
+	start := time.Now()
+	resp, err := target.Client.Request(target.Method, target.Path, nil)
+	if err != nil {
+		return sample{latency: time.Since(start)}
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return sample{status: resp.StatusCode, latency: time.Since(start)}
+}
@@ -0,0 +1,166 @@
+package bench
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/types"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		err   bool
+	}{
+		{value: "/api/config", want: "GET /api/config"},
+		{value: " post  /api/v1/conversations ", want: "POST /api/v1/conversations"},
+		{value: "GET api/config", err: true},
+		{value: "", err: true},
+	}
+	for _, tt := range tests {
+		target, err := ParseTarget(tt.value)
+		if tt.err {
+			if err == nil {
+				t.Errorf("ParseTarget(%q) = %v, want an error", tt.value, target)
+			}
+			continue
+		}
+		if err != nil || target.String() != tt.want {
+			t.Errorf("ParseTarget(%q) = %v, %v, want %s", tt.value, target, err, tt.want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	var samples []sample
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, sample{status: 200, latency: time.Duration(i) * time.Millisecond})
+	}
+	samples = append(samples,
+		sample{status: 503, latency: 20 * time.Second},
+		sample{status: 0, latency: time.Second},
+	)
+
+	stats := summarize("GET /api/config", samples, 2*time.Second)
+	if stats.Requests != 102 || stats.Errors != 2 || stats.Throughput != 51 {
+		t.Errorf("%d requests, %d errors, %g req/s", stats.Requests, stats.Errors, stats.Throughput)
+	}
+	if stats.Statuses["200"] != 100 || stats.Statuses["503"] != 1 || stats.Statuses["error"] != 1 {
+		t.Errorf("statuses = %v", stats.Statuses)
+	}
+	// The transport error is left out of the latencies, the 503 is not
+	want := []time.Duration{time.Millisecond, 51 * time.Millisecond, 91 * time.Millisecond, 100 * time.Millisecond, 20 * time.Second}
+	got := []time.Duration{stats.Min, stats.P50, stats.P90, stats.P99, stats.Max}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("min/p50/p90/p99/max = %v, want %v", got, want)
+			break
+		}
+	}
+	if mean := (5050*time.Millisecond + 20*time.Second) / 101; stats.Mean != mean {
+		t.Errorf("mean = %v, want %v", stats.Mean, mean)
+	}
+
+	// ≤5ms, ≤10ms, ≤25ms, ≤50ms, ≤100ms and the overflow bucket
+	histogram := map[int]int{0: 5, 1: 5, 2: 15, 3: 25, 4: 50, len(histogramBounds): 1}
+	for i, count := range stats.Histogram {
+		if count != histogram[i] {
+			t.Errorf("histogram = %v", stats.Histogram)
+			break
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5}
+	tests := map[int]time.Duration{0: 1, 20: 1, 21: 2, 50: 3, 99: 5, 100: 5}
+	for p, want := range tests {
+		if got := percentile(sorted, p); got != want {
+			t.Errorf("p%d = %v, want %v", p, got, want)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	var configs, missing atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/config" {
+			configs.Add(1)
+			w.Write([]byte(`{}`))
+			return
+		}
+		missing.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	apiClient := client.NewAPIClient(&types.Config{BaseURL: server.URL, Timeout: time.Second})
+	report, err := Run(Options{
+		Targets: []Target{
+			{Method: "GET", Path: "/api/config", Client: apiClient},
+			{Method: "GET", Path: "/missing", Client: apiClient},
+		},
+		Concurrency: 2,
+		RPS:         50,
+		Duration:    200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, notFound := report.Endpoints[0], report.Endpoints[1]
+	if config.Requests == 0 || config.Errors != 0 || int64(config.Requests) != configs.Load() {
+		t.Errorf("config: %d requests, %d errors, %d served", config.Requests, config.Errors, configs.Load())
+	}
+	// 50 req/s for 200ms, whatever the concurrency
+	if config.Requests > 12 {
+		t.Errorf("config: %d requests sent at 50 req/s in 200ms", config.Requests)
+	}
+	if notFound.Requests == 0 || notFound.Errors != notFound.Requests || notFound.Statuses["404"] != notFound.Requests {
+		t.Errorf("missing: %d requests, %d errors, statuses %v", notFound.Requests, notFound.Errors, notFound.Statuses)
+	}
+
+	one := []Target{{Method: "GET", Path: "/api/config", Client: apiClient}}
+	for _, opts := range []Options{
+		{Concurrency: 1, Duration: time.Second},
+		{Targets: one, Duration: time.Second},
+		{Targets: one, Concurrency: 1},
+	} {
+		if _, err := Run(opts); err == nil {
+			t.Errorf("Run(%+v) succeeded", opts)
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	stats := summarize("GET /api/config", []sample{{status: 200, latency: 1500 * time.Microsecond}, {status: 0}}, time.Second)
+	path := filepath.Join(t.TempDir(), "bench", "report.json")
+	if err := WriteJSON(path, &Report{BaseURL: "http://localhost:3000", Concurrency: 1, Endpoints: []EndpointStats{stats}}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	endpoint := report.Endpoints[0]
+	if endpoint.ErrorRate != 0.5 || endpoint.LatencyMs.P50 != 1.5 || len(endpoint.Histogram) != len(histogramBounds)+1 {
+		t.Errorf("endpoint = %+v", endpoint)
+	}
+	if overflow := endpoint.Histogram[len(histogramBounds)]; overflow.LeMs != 0 || strings.Contains(string(data), `"leMs": 0`) {
+		t.Errorf("the overflow bucket has a bound: %+v", overflow)
+	}
+}
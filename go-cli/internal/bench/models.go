@@ -0,0 +1,364 @@
+package bench
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+// Streaming endpoints measured by RunModels
+const (
+	EndpointChat = "chat" // POST /api/chat
+	EndpointV1   = "v1"   // POST /api/v1/conversations/{id}/messages
+)
+
+// benchConversationID tags /api/chat usage, which requires a conversation
+const benchConversationID = "validator-model-benchmark"
+
+// DefaultModelPrompt asks for a reply long enough to measure throughput
+const DefaultModelPrompt = "Count from 1 to 20 in words, separated by commas."
+
+// ModelOptions configures a streaming benchmark across models
+type ModelOptions struct {
+	Models     []types.AIModel
+	ChatClient *client.APIClient // Clerk client for /api/chat, nil to skip
+	V1Client   *client.APIClient // JWT client for v1 messages, nil to skip
+	Prompt     string
+	Runs       int // streams per model and endpoint
+}
+
+// ModelResult is the median of a model's runs against one endpoint
+type ModelResult struct {
+	Model    string
+	Provider string
+	Endpoint string
+	Runs     int
+	Failures int
+	Error    string // last failure, set when every run failed
+
+	TTFT         time.Duration // request sent to first content
+	Total        time.Duration // request sent to end of stream
+	Chunks       int
+	Chars        int
+	OutputTokens int // from the /api/chat usage event, when sent
+}
+
+// CharsPerSecond is the generation rate after the first content arrived
+func (r ModelResult) CharsPerSecond() float64 {
+	return rate(r.Chars, r.Total-r.TTFT)
+}
+
+// TokensPerSecond is the output token rate after the first content
+// arrived, or zero when the server reported no usage
+func (r ModelResult) TokensPerSecond() float64 {
+	return rate(r.OutputTokens, r.Total-r.TTFT)
+}
+
+func rate(n int, d time.Duration) float64 {
+	if n == 0 || d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
+
+// streamRun is the measurement of a single streamed reply
+type streamRun struct {
+	ttft         time.Duration
+	total        time.Duration
+	chunks       int
+	chars        int
+	outputTokens int
+}
+
+// RunModels streams the prompt through each model on each configured
+// endpoint, one stream at a time so models do not compete. progress is
+// called after each model and endpoint finishes.
+func RunModels(opts ModelOptions, progress func(ModelResult)) []ModelResult {
+	runs := max(opts.Runs, 1)
+
+	var results []ModelResult
+	for _, model := range opts.Models {
+		for _, endpoint := range []string{EndpointChat, EndpointV1} {
+			var measure func() (streamRun, error)
+			switch {
+			case endpoint == EndpointChat && opts.ChatClient != nil:
+				measure = func() (streamRun, error) { return streamChat(opts.ChatClient, model.ID, opts.Prompt) }
+			case endpoint == EndpointV1 && opts.V1Client != nil:
+				measure = func() (streamRun, error) { return streamV1(opts.V1Client, model.ID, opts.Prompt) }
+			default:
+				continue
+			}
+
+			result := ModelResult{Model: model.ID, Provider: model.Provider, Endpoint: endpoint, Runs: runs}
+			var samples []streamRun
+			for range runs {
+				run, err := measure()
+				if err != nil {
+					result.Failures++
+					result.Error = err.Error()
+					continue
+				}
+				samples = append(samples, run)
+			}
+			if len(samples) > 0 {
+				result.Error = ""
+				medianRun(&result, samples)
+			}
+
+			results = append(results, result)
+			if progress != nil {
+				progress(result)
+			}
+		}
+	}
+	return results
+}
+
+// medianRun fills result with the median of each measurement
+func medianRun(result *ModelResult, samples []streamRun) {
+	median := func(value func(streamRun) int64) int64 {
+		values := make([]int64, len(samples))
+		for i, s := range samples {
+			values[i] = value(s)
+		}
+		slices.Sort(values)
+		return values[len(values)/2]
+	}
+
+	result.TTFT = time.Duration(median(func(s streamRun) int64 { return int64(s.ttft) }))
+	result.Total = time.Duration(median(func(s streamRun) int64 { return int64(s.total) }))
+	result.Chunks = int(median(func(s streamRun) int64 { return int64(s.chunks) }))
+	result.Chars = int(median(func(s streamRun) int64 { return int64(s.chars) }))
+	result.OutputTokens = int(median(func(s streamRun) int64 { return int64(s.outputTokens) }))
+}
+
+// streamChat measures one streamed reply from /api/chat
+func streamChat(apiClient *client.APIClient, model, prompt string) (streamRun, error) {
+	start := time.Now()
+	stream, err := apiClient.StreamChat(types.ChatRequest{
+		Model:          model,
+		Messages:       []types.ChatMessage{{Role: "user", Content: prompt}},
+		ConversationID: benchConversationID,
+	})
+	if err != nil {
+		return streamRun{}, err
+	}
+	defer stream.Close()
+
+	var run streamRun
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return run, err
+		}
+		if chunk.OutputTokens > 0 {
+			run.outputTokens = chunk.OutputTokens
+		}
+		if chunk.Text != "" {
+			run.record(start, chunk.Text)
+		}
+	}
+	return run.finish(start)
+}
+
+// streamV1 measures one streamed reply from the v1 messages endpoint in
+// a throwaway conversation using the model
+func streamV1(apiClient *client.APIClient, model, prompt string) (streamRun, error) {
+	resp, err := apiClient.Post("/api/v1/conversations", types.ConversationRequest{Title: "Validator Model Benchmark", Model: model})
+	if err != nil {
+		return streamRun{}, fmt.Errorf("failed to create conversation: %w", err)
+	}
+	var conversation types.Conversation
+	err = json.NewDecoder(resp.Body).Decode(&conversation)
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || err != nil || conversation.ID == "" {
+		return streamRun{}, fmt.Errorf("failed to create conversation: HTTP %d", resp.StatusCode)
+	}
+	defer func() {
+		if resp, err := apiClient.Request("DELETE", "/api/v1/conversations/"+conversation.ID, nil); err == nil {
+			resp.Body.Close()
+		}
+	}()
+
+	start := time.Now()
+	stream, err := apiClient.StreamMessage(conversation.ID, types.V1MessageRequest{Content: prompt})
+	if err != nil {
+		return streamRun{}, err
+	}
+	defer stream.Close()
+
+	var run streamRun
+	for {
+		event, err := stream.Next()
+		if err == io.EOF || event.Type == types.StreamEventDone {
+			break
+		}
+		if err != nil {
+			return run, fmt.Errorf("stream read failed: %w", err)
+		}
+		switch event.Type {
+		case types.StreamEventError:
+			return run, fmt.Errorf("error event: %s", event.Error)
+		case types.StreamEventContentChunk:
+			if event.Content != "" {
+				run.record(start, event.Content)
+			}
+		}
+	}
+	return run.finish(start)
+}
+
+func (r *streamRun) record(start time.Time, text string) {
+	if r.chunks == 0 {
+		r.ttft = time.Since(start)
+	}
+	r.chunks++
+	r.chars += len([]rune(text))
+}
+
+func (r *streamRun) finish(start time.Time) (streamRun, error) {
+	r.total = time.Since(start)
+	if r.chunks == 0 {
+		return *r, errors.New("stream ended without content")
+	}
+	return *r, nil
+}
+
+// PrintModels writes the results as a comparison table
+func PrintModels(w io.Writer, results []ModelResult) {
+	headers := []string{"Provider", "Model", "Endpoint", "TTFT", "Total", "Chunks", "Chars", "Chars/s", "Tok/s"}
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		if r.Error != "" {
+			rows = append(rows, []string{r.Provider, r.Model, r.Endpoint, "-", "-", "-", "-", "-", "-"})
+			continue
+		}
+		tokens := "-"
+		if r.OutputTokens > 0 {
+			tokens = fmt.Sprintf("%.1f", r.TokensPerSecond())
+		}
+		rows = append(rows, []string{
+			r.Provider, r.Model, r.Endpoint,
+			round(r.TTFT).String(), round(r.Total).String(),
+			fmt.Sprint(r.Chunks), fmt.Sprint(r.Chars),
+			fmt.Sprintf("%.1f", r.CharsPerSecond()), tokens,
+		})
+	}
+
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = len(header)
+		for _, row := range rows {
+			widths[i] = max(widths[i], len([]rune(row[i])))
+		}
+	}
+
+	printRow := func(cells []string) string {
+		parts := make([]string, len(cells))
+		for i, cell := range cells {
+			// Text columns align left, measurements align right
+			if i < 3 {
+				parts[i] = fmt.Sprintf("%-*s", widths[i], cell)
+			} else {
+				parts[i] = fmt.Sprintf("%*s", widths[i], cell)
+			}
+		}
+		return strings.Join(parts, "  ")
+	}
+
+	fmt.Fprintln(w, "   "+colors.BoldText(printRow(headers)))
+	for i, row := range rows {
+		line := printRow(row)
+		if results[i].Error != "" {
+			line = colors.Error(line)
+		} else if results[i].Failures > 0 {
+			line = colors.Warning(line)
+		}
+		fmt.Fprintln(w, "   "+line)
+	}
+
+	var failed []ModelResult
+	for _, r := range results {
+		if r.Failures > 0 {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, colors.Warning(fmt.Sprintf("Failed runs (%d):", len(failed))))
+		for _, r := range failed {
+			fmt.Fprintf(w, "   • %s via %s: %d/%d failed", r.Model, r.Endpoint, r.Failures, r.Runs)
+			if r.Error != "" {
+				fmt.Fprintf(w, ": %s", r.Error)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+type jsonModelReport struct {
+	BaseURL   string          `json:"baseUrl"`
+	StartedAt time.Time       `json:"startedAt"`
+	Prompt    string          `json:"prompt"`
+	Results   []jsonModelStat `json:"results"`
+}
+
+type jsonModelStat struct {
+	Model           string  `json:"model"`
+	Provider        string  `json:"provider"`
+	Endpoint        string  `json:"endpoint"`
+	Runs            int     `json:"runs"`
+	Failures        int     `json:"failures"`
+	Error           string  `json:"error,omitempty"`
+	TTFTMs          float64 `json:"ttftMs"`
+	TotalMs         float64 `json:"totalMs"`
+	Chunks          int     `json:"chunks"`
+	Chars           int     `json:"chars"`
+	CharsPerSecond  float64 `json:"charsPerSecond"`
+	OutputTokens    int     `json:"outputTokens,omitempty"`
+	TokensPerSecond float64 `json:"tokensPerSecond,omitempty"`
+}
+
+// WriteModelsJSON writes model benchmark results as JSON to path
+func WriteModelsJSON(path, baseURL, prompt string, startedAt time.Time, results []ModelResult) error {
+	out := jsonModelReport{
+		BaseURL:   baseURL,
+		StartedAt: startedAt.UTC(),
+		Prompt:    prompt,
+		Results:   make([]jsonModelStat, 0, len(results)),
+	}
+	for _, r := range results {
+		out.Results = append(out.Results, jsonModelStat{
+			Model:           r.Model,
+			Provider:        r.Provider,
+			Endpoint:        r.Endpoint,
+			Runs:            r.Runs,
+			Failures:        r.Failures,
+			Error:           r.Error,
+			TTFTMs:          milliseconds(r.TTFT),
+			TotalMs:         milliseconds(r.Total),
+			Chunks:          r.Chunks,
+			Chars:           r.Chars,
+			CharsPerSecond:  r.CharsPerSecond(),
+			OutputTokens:    r.OutputTokens,
+			TokensPerSecond: r.TokensPerSecond(),
+		})
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode benchmark report: %w", err)
+	}
+	return writeFile(path, append(data, '\n'))
+}
@@ -0,0 +1,124 @@
+package bench
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/mock"
+	"github.com/omnichat/validator/internal/types"
+)
+
+func TestRunModelsAgainstMock(t *testing.T) {
+	server := httptest.NewServer(mock.New(mock.Options{}))
+	defer server.Close()
+	newClient := func(token string) *client.APIClient {
+		return client.NewAPIClient(&types.Config{BaseURL: server.URL, AuthToken: token, Timeout: 5 * time.Second})
+	}
+
+	models := []types.AIModel{
+		{ID: "gpt-4o-mini", Provider: "openai"},
+		{ID: "claude-3-5-haiku-20241022", Provider: "anthropic"},
+	}
+	var progressed []ModelResult
+	results := RunModels(ModelOptions{
+		Models:     models,
+		ChatClient: newClient(mock.DefaultClerkToken),
+		V1Client:   newClient(mock.DefaultJWTToken),
+		Prompt:     DefaultModelPrompt,
+		Runs:       2,
+	}, func(r ModelResult) { progressed = append(progressed, r) })
+
+	if len(results) != 4 || len(progressed) != 4 {
+		t.Fatalf("%d results, %d progress calls, want 4", len(results), len(progressed))
+	}
+	for i, r := range results {
+		if want := models[i/2].ID; r.Model != want || r.Endpoint != []string{EndpointChat, EndpointV1}[i%2] {
+			t.Errorf("result %d is %s via %s", i, r.Model, r.Endpoint)
+		}
+		if r.Runs != 2 || r.Failures != 0 || r.Error != "" {
+			t.Errorf("%s via %s: %d/%d failed: %s", r.Model, r.Endpoint, r.Failures, r.Runs, r.Error)
+		}
+		if r.Chunks == 0 || r.Chars == 0 || r.TTFT <= 0 || r.Total < r.TTFT {
+			t.Errorf("%s via %s: %d chunks, %d chars, TTFT %v, total %v", r.Model, r.Endpoint, r.Chunks, r.Chars, r.TTFT, r.Total)
+		}
+	}
+
+	// The v1 runs delete their conversations
+	resp, err := newClient(mock.DefaultJWTToken).Get("/api/v1/conversations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var listed types.ConversationsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&listed); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.Conversations) != 0 {
+		t.Errorf("%d benchmark conversations left", len(listed.Conversations))
+	}
+
+	failed := RunModels(ModelOptions{Models: models[:1], V1Client: newClient("wrong-token"), Runs: 3}, nil)
+	if len(failed) != 1 || failed[0].Endpoint != EndpointV1 || failed[0].Failures != 3 || failed[0].Error == "" {
+		t.Errorf("with a wrong token: %+v", failed)
+	}
+}
+
+func TestMedianRun(t *testing.T) {
+	var result ModelResult
+	medianRun(&result, []streamRun{
+		{ttft: 30 * time.Millisecond, total: time.Second, chunks: 9, chars: 90, outputTokens: 20},
+		{ttft: 10 * time.Millisecond, total: 3 * time.Second, chunks: 1, chars: 10},
+		{ttft: 20 * time.Millisecond, total: 2 * time.Second, chunks: 5, chars: 50, outputTokens: 10},
+	})
+	want := ModelResult{TTFT: 20 * time.Millisecond, Total: 2 * time.Second, Chunks: 5, Chars: 50, OutputTokens: 10}
+	if result != want {
+		t.Errorf("median = %+v, want %+v", result, want)
+	}
+}
+
+func TestModelRates(t *testing.T) {
+	r := ModelResult{TTFT: 500 * time.Millisecond, Total: 2500 * time.Millisecond, Chars: 100, OutputTokens: 30}
+	if got := r.CharsPerSecond(); got != 50 {
+		t.Errorf("CharsPerSecond() = %g, want 50", got)
+	}
+	if got := r.TokensPerSecond(); got != 15 {
+		t.Errorf("TokensPerSecond() = %g, want 15", got)
+	}
+	if got := (ModelResult{Chars: 10, TTFT: time.Second, Total: time.Second}).CharsPerSecond(); got != 0 {
+		t.Errorf("a single chunk reply has a rate of %g", got)
+	}
+}
+
+func TestWriteModelsJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.json")
+	results := []ModelResult{
+		{Model: "gpt-4o", Provider: "openai", Endpoint: EndpointChat, Runs: 1, TTFT: 250 * time.Millisecond, Total: 1250 * time.Millisecond, Chunks: 4, Chars: 40},
+		{Model: "gpt-4o", Provider: "openai", Endpoint: EndpointV1, Runs: 1, Failures: 1, Error: "HTTP 500"},
+	}
+	if err := WriteModelsJSON(path, "http://localhost:3000", "hi", time.Now(), results); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report jsonModelReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 2 || report.Prompt != "hi" {
+		t.Fatalf("report = %+v", report)
+	}
+	if chat := report.Results[0]; chat.TTFTMs != 250 || chat.TotalMs != 1250 || chat.CharsPerSecond != 40 {
+		t.Errorf("chat = %+v", chat)
+	}
+	if v1 := report.Results[1]; v1.Failures != 1 || v1.Error != "HTTP 500" {
+		t.Errorf("v1 = %+v", v1)
+	}
+}
@@ -0,0 +1,298 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+// histogramBounds are the upper edges of the latency histogram buckets;
+// a final bucket collects everything slower
+var histogramBounds = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// histogramWidth is the length of the longest histogram bar
+const histogramWidth = 40
+
+// Report is the result of a benchmark run
+type Report struct {
+	BaseURL     string
+	StartedAt   time.Time
+	Duration    time.Duration
+	Concurrency int
+	RPS         float64
+	Endpoints   []EndpointStats
+}
+
+// EndpointStats summarizes the requests sent to one endpoint. Latency
+// percentiles cover every response, including error statuses; transport
+// errors only count towards Statuses["error"].
+type EndpointStats struct {
+	Endpoint   string
+	Requests   int
+	Errors     int            // transport errors and 4xx/5xx responses
+	Statuses   map[string]int // by status code, "error" for transport errors
+	Throughput float64        // requests per second
+	Min        time.Duration
+	Mean       time.Duration
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+	Histogram  []int // counts per histogramBounds bucket plus overflow
+}
+
+// ErrorRate returns the fraction of requests that failed
+func (s EndpointStats) ErrorRate() float64 {
+	if s.Requests == 0 {
+		return 0
+	}
+	return float64(s.Errors) / float64(s.Requests)
+}
+
+// summarize computes the statistics for one endpoint's samples
+func summarize(endpoint string, samples []sample, elapsed time.Duration) EndpointStats {
+	stats := EndpointStats{
+		Endpoint:  endpoint,
+		Requests:  len(samples),
+		Statuses:  make(map[string]int),
+		Histogram: make([]int, len(histogramBounds)+1),
+	}
+	if elapsed > 0 {
+		stats.Throughput = float64(len(samples)) / elapsed.Seconds()
+	}
+
+	var latencies []time.Duration
+	var total time.Duration
+	for _, s := range samples {
+		if s.status == 0 {
+			stats.Errors++
+			stats.Statuses["error"]++
+			continue
+		}
+		if s.status >= 400 {
+			stats.Errors++
+		}
+		stats.Statuses[strconv.Itoa(s.status)]++
+
+		latencies = append(latencies, s.latency)
+		total += s.latency
+		bucket, _ := slices.BinarySearch(histogramBounds, s.latency)
+		stats.Histogram[bucket]++
+	}
+
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		stats.Min = latencies[0]
+		stats.Max = latencies[len(latencies)-1]
+		stats.Mean = total / time.Duration(len(latencies))
+		stats.P50 = percentile(latencies, 50)
+		stats.P90 = percentile(latencies, 90)
+		stats.P99 = percentile(latencies, 99)
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank, 1)-1]
+}
+
+// Print writes a human-readable summary of the report to w
+func Print(w io.Writer, r *Report) {
+	rate := "unlimited"
+	if r.RPS > 0 {
+		rate = fmt.Sprintf("%g req/s", r.RPS)
+	}
+	fmt.Fprintf(w, "⏱️  %v at concurrency %d, rate %s\n", r.Duration.Round(time.Millisecond), r.Concurrency, rate)
+
+	for _, s := range r.Endpoints {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, colors.Subheader("📈", s.Endpoint+":"))
+		fmt.Fprintf(w, "   Requests: %d (%.1f req/s) | Errors: %s\n", s.Requests, s.Throughput, formatErrorRate(s))
+		if len(s.Statuses) > 0 {
+			fmt.Fprintf(w, "   Statuses: %s\n", formatStatuses(s.Statuses))
+		}
+		if s.Requests == s.Statuses["error"] {
+			continue
+		}
+		fmt.Fprintf(w, "   Latency:  min %v | p50 %v | p90 %v | p99 %v | max %v\n",
+			round(s.Min), round(s.P50), round(s.P90), round(s.P99), round(s.Max))
+		printHistogram(w, s.Histogram)
+	}
+}
+
+func formatErrorRate(s EndpointStats) string {
+	text := fmt.Sprintf("%d (%.1f%%)", s.Errors, s.ErrorRate()*100)
+	if s.Errors > 0 {
+		return colors.Error(text)
+	}
+	return colors.Success(text)
+}
+
+// formatStatuses lists status counts in code order, transport errors last
+func formatStatuses(statuses map[string]int) string {
+	keys := make([]string, 0, len(statuses))
+	for key := range statuses {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		part := fmt.Sprintf("%s × %d", key, statuses[key])
+		if key == "error" || key >= "400" {
+			part = colors.Error(part)
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printHistogram draws the non-empty span of the latency histogram
+func printHistogram(w io.Writer, histogram []int) {
+	first, last, peak := -1, -1, 0
+	for i, count := range histogram {
+		if count > 0 {
+			if first < 0 {
+				first = i
+			}
+			last = i
+			peak = max(peak, count)
+		}
+	}
+	if first < 0 {
+		return
+	}
+
+	for i := first; i <= last; i++ {
+		label := "> " + histogramBounds[len(histogramBounds)-1].String()
+		if i < len(histogramBounds) {
+			label = "≤ " + histogramBounds[i].String()
+		}
+		bar := strings.Repeat("█", (histogram[i]*histogramWidth+peak-1)/peak)
+		fmt.Fprintf(w, "   %8s │%s %d\n", label, bar, histogram[i])
+	}
+}
+
+func round(d time.Duration) time.Duration {
+	if d < 10*time.Millisecond {
+		return d.Round(10 * time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+type jsonReport struct {
+	BaseURL     string         `json:"baseUrl"`
+	StartedAt   time.Time      `json:"startedAt"`
+	DurationMs  int64          `json:"durationMs"`
+	Concurrency int            `json:"concurrency"`
+	RPS         float64        `json:"rps,omitempty"`
+	Endpoints   []jsonEndpoint `json:"endpoints"`
+}
+
+type jsonEndpoint struct {
+	Endpoint   string         `json:"endpoint"`
+	Requests   int            `json:"requests"`
+	Errors     int            `json:"errors"`
+	ErrorRate  float64        `json:"errorRate"`
+	Statuses   map[string]int `json:"statuses"`
+	Throughput float64        `json:"throughput"`
+	LatencyMs  jsonLatency    `json:"latencyMs"`
+	Histogram  []jsonBucket   `json:"histogram"`
+}
+
+type jsonLatency struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+type jsonBucket struct {
+	LeMs  float64 `json:"leMs,omitempty"` // omitted for the overflow bucket
+	Count int     `json:"count"`
+}
+
+// WriteJSON writes the report as JSON to path
+func WriteJSON(path string, r *Report) error {
+	out := jsonReport{
+		BaseURL:     r.BaseURL,
+		StartedAt:   r.StartedAt.UTC(),
+		DurationMs:  r.Duration.Milliseconds(),
+		Concurrency: r.Concurrency,
+		RPS:         r.RPS,
+		Endpoints:   make([]jsonEndpoint, 0, len(r.Endpoints)),
+	}
+	for _, s := range r.Endpoints {
+		endpoint := jsonEndpoint{
+			Endpoint:   s.Endpoint,
+			Requests:   s.Requests,
+			Errors:     s.Errors,
+			ErrorRate:  s.ErrorRate(),
+			Statuses:   s.Statuses,
+			Throughput: s.Throughput,
+			LatencyMs: jsonLatency{
+				Min:  milliseconds(s.Min),
+				Mean: milliseconds(s.Mean),
+				P50:  milliseconds(s.P50),
+				P90:  milliseconds(s.P90),
+				P99:  milliseconds(s.P99),
+				Max:  milliseconds(s.Max),
+			},
+		}
+		for i, count := range s.Histogram {
+			bucket := jsonBucket{Count: count}
+			if i < len(histogramBounds) {
+				bucket.LeMs = milliseconds(histogramBounds[i])
+			}
+			endpoint.Histogram = append(endpoint.Histogram, bucket)
+		}
+		out.Endpoints = append(out.Endpoints, endpoint)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode benchmark report: %w", err)
+	}
+	return writeFile(path, append(data, '\n'))
+}
+
+// writeFile writes a report, creating its directory if needed
+func writeFile(path string, data []byte) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write benchmark report %s: %w", path, err)
+	}
+	return nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,349 @@
+package client
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// cassetteVersion is bumped when the cassette format changes
+const cassetteVersion = 1
+
+// redactedHeaders are replaced before interactions are written to disk
+var redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// redactedFields are the JSON body fields replaced before interactions are
+// written to disk, at any depth
+var redactedFields = map[string]bool{
+	"accessToken":  true,
+	"refreshToken": true,
+	"idToken":      true,
+}
+
+// Cassette is a recorded sequence of HTTP interactions
+type Cassette struct {
+	Version      int            `json:"version"`
+	BaseURL      string         `json:"baseUrl"`
+	RecordedAt   time.Time      `json:"recordedAt"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single request/response pair
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the request half of an interaction. URL is the
+// request URI (path and query) so cassettes replay against any host.
+type RecordedRequest struct {
+	Method       string      `json:"method"`
+	URL          string      `json:"url"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+// RecordedResponse is the response half of an interaction
+type RecordedResponse struct {
+	StatusCode   int         `json:"statusCode"`
+	Headers      http.Header `json:"headers,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+// LoadCassette reads a cassette file written by Recorder.Save
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette %s: %w", path, err)
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if cassette.Version != cassetteVersion {
+		return nil, fmt.Errorf("unsupported cassette version %d in %s", cassette.Version, path)
+	}
+	return &cassette, nil
+}
+
+// Recorder is an http.RoundTripper that records every interaction. Share
+// one Recorder between clients via types.Config.Transport.
+type Recorder struct {
+	base http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+}
+
+// NewRecorder records traffic sent through base, or
+// http.DefaultTransport when base is nil
+func NewRecorder(base http.RoundTripper) *Recorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Recorder{base: base}
+}
+
+// RoundTrip sends the request and records it with its response. Request
+// bodies are buffered; response bodies are captured as they are read so
+// streams still reach the caller incrementally.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		req.ContentLength = int64(len(reqBody))
+	}
+
+	resp, err := r.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     req.URL.RequestURI(),
+			Headers: redact(req.Header),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    redact(resp.Header),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(redactBody(reqBody))
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, interaction)
+	r.mu.Unlock()
+
+	resp.Body = &recordingBody{ReadCloser: resp.Body, recorder: r, interaction: interaction}
+	return resp, nil
+}
+
+// Len returns the number of recorded interactions
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.interactions)
+}
+
+// Save writes the recorded interactions to path
+func (r *Recorder) Save(path, baseURL string) error {
+	r.mu.Lock()
+	cassette := Cassette{
+		Version:      cassetteVersion,
+		BaseURL:      baseURL,
+		RecordedAt:   time.Now().UTC(),
+		Interactions: r.interactions,
+	}
+	data, err := json.MarshalIndent(cassette, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create cassette directory: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette %s: %w", path, err)
+	}
+	return nil
+}
+
+// recordingBody copies a response body into its interaction as it is read
+type recordingBody struct {
+	io.ReadCloser
+	recorder    *Recorder
+	interaction *Interaction
+	buf         bytes.Buffer
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.flush()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.flush()
+	return b.ReadCloser.Close()
+}
+
+func (b *recordingBody) flush() {
+	b.recorder.mu.Lock()
+	b.interaction.Response.Body, b.interaction.Response.BodyEncoding = encodeBody(redactBody(b.buf.Bytes()))
+	b.recorder.mu.Unlock()
+}
+
+// Replayer is an http.RoundTripper that serves responses from a cassette.
+// Requests are matched by method and URI; repeated requests receive the
+// recorded responses in order.
+type Replayer struct {
+	mu     sync.Mutex
+	queues map[string][]*Interaction
+}
+
+// NewReplayer serves the interactions in cassette
+func NewReplayer(cassette *Cassette) *Replayer {
+	queues := make(map[string][]*Interaction)
+	for _, interaction := range cassette.Interactions {
+		key := interactionKey(interaction.Request.Method, interaction.Request.URL)
+		queues[key] = append(queues[key], interaction)
+	}
+	return &Replayer{queues: queues}
+}
+
+// RoundTrip returns the next recorded response for the request
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Drain the body so streaming writers (multipart pipes) finish
+	if req.Body != nil {
+		io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+	}
+
+	key := interactionKey(req.Method, req.URL.RequestURI())
+
+	r.mu.Lock()
+	queue := r.queues[key]
+	if len(queue) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("no recorded response for %s", key)
+	}
+	interaction := queue[0]
+	r.queues[key] = queue[1:]
+	r.mu.Unlock()
+
+	body, err := decodeBody(interaction.Response.Body, interaction.Response.BodyEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recorded body for %s: %w", key, err)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+		StatusCode:    interaction.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        interaction.Response.Headers.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Remaining returns the number of interactions not yet replayed
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, queue := range r.queues {
+		n += len(queue)
+	}
+	return n
+}
+
+func interactionKey(method, uri string) string {
+	return strings.ToUpper(method) + " " + uri
+}
+
+// redact copies headers, replacing credentials while keeping the scheme
+func redact(header http.Header) http.Header {
+	out := header.Clone()
+	for _, name := range redactedHeaders {
+		values := out.Values(name)
+		for i, value := range values {
+			if scheme, _, ok := strings.Cut(value, " "); ok && name == "Authorization" {
+				values[i] = scheme + " [REDACTED]"
+			} else {
+				values[i] = "[REDACTED]"
+			}
+		}
+	}
+	return out
+}
+
+// redactBody replaces the redactedFields of a JSON body. Other bodies, and
+// JSON bodies without such fields, are returned unchanged.
+func redactBody(body []byte) []byte {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if decoder.Decode(&value) != nil || decoder.More() {
+		return body
+	}
+	if !redactValue(value) {
+		return body
+	}
+	redacted, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+// redactValue replaces the redactedFields in a decoded JSON value and
+// reports whether it replaced any
+func redactValue(value interface{}) bool {
+	redacted := false
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if _, ok := field.(string); ok && redactedFields[key] {
+				value[key] = "[REDACTED]"
+				redacted = true
+			} else if redactValue(field) {
+				redacted = true
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			if redactValue(item) {
+				redacted = true
+			}
+		}
+	}
+	return redacted
+}
+
+// encodeBody stores UTF-8 bodies as text and everything else as base64
+func encodeBody(body []byte) (string, string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch r.URL.Path {
+		case "/auth":
+			w.Header().Set("Set-Cookie", "session=secret")
+			io.WriteString(w, `{"accessToken":"secret-access","user":{"id":"u1","refreshToken":"secret-refresh"},"tokens":[{"idToken":"secret-id"}]}`)
+		case "/binary":
+			w.Write([]byte{0xff, 0x00, 0xfe})
+		default:
+			io.WriteString(w, strings.Repeat("x", calls))
+		}
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder(nil)
+	httpClient := &http.Client{Transport: recorder}
+	get := func(client *http.Client, path string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest("GET", server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer secret-token")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, string(body)
+	}
+
+	var recorded []string
+	for _, path := range []string{"/auth", "/binary", "/count?n=1", "/count?n=1"} {
+		_, body := get(httpClient, path)
+		recorded = append(recorded, body)
+	}
+	if !strings.Contains(recorded[0], "secret-access") {
+		t.Errorf("the caller got a redacted body: %s", recorded[0])
+	}
+
+	path := filepath.Join(t.TempDir(), "cassettes", "run.json")
+	if err := recorder.Save(path, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth := cassette.Interactions[0]
+	if got := auth.Request.Headers.Get("Authorization"); got != "Bearer [REDACTED]" {
+		t.Errorf("Authorization recorded as %q", got)
+	}
+	if got := auth.Response.Headers.Get("Set-Cookie"); got != "[REDACTED]" {
+		t.Errorf("Set-Cookie recorded as %q", got)
+	}
+	if strings.Contains(auth.Response.Body, "secret") || !strings.Contains(auth.Response.Body, `"id":"u1"`) {
+		t.Errorf("auth response recorded as %s", auth.Response.Body)
+	}
+	if binary := cassette.Interactions[1].Response; binary.BodyEncoding != "base64" {
+		t.Errorf("binary body recorded with encoding %q", binary.BodyEncoding)
+	}
+
+	replayer := NewReplayer(cassette)
+	replayClient := &http.Client{Transport: replayer}
+	for i, path := range []string{"/binary", "/count?n=1", "/count?n=1"} {
+		status, body := get(replayClient, path)
+		if want := recorded[i+1]; status != http.StatusOK || body != want {
+			t.Errorf("replayed GET %s = %d %q, want %q", path, status, body, want)
+		}
+	}
+	if n := replayer.Remaining(); n != 1 {
+		t.Errorf("%d interactions remaining, want 1", n)
+	}
+	if _, err := replayClient.Get(server.URL + "/count?n=2"); err == nil || !strings.Contains(err.Error(), "no recorded response for GET /count?n=2") {
+		t.Errorf("unrecorded request: error = %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("the server was called %d times, want 4", calls)
+	}
+}
+
+func TestLoadCassetteVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := NewRecorder(nil).Save(path, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCassette(path); err != nil {
+		t.Fatalf("LoadCassette: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"version":99}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCassette(path); err == nil || !strings.Contains(err.Error(), "unsupported cassette version 99") {
+		t.Errorf("LoadCassette error = %v", err)
+	}
+}
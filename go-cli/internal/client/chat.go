@@ -0,0 +1,135 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/omnichat/validator/internal/types"
+)
+
+// ListModels fetches /api/models and flattens it, ordered by provider and
+// then as listed. Models without a provider take their group's name.
+func (c *APIClient) ListModels() ([]types.AIModel, error) {
+	resp, err := c.Get("/api/models")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch /api/models: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to fetch /api/models: HTTP %d", resp.StatusCode)
+	}
+
+	var models types.ModelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&models); err != nil {
+		return nil, fmt.Errorf("failed to parse /api/models: %w", err)
+	}
+
+	providers := make([]string, 0, len(models.Providers))
+	for provider := range models.Providers {
+		providers = append(providers, provider)
+	}
+	slices.Sort(providers)
+
+	var all []types.AIModel
+	for _, provider := range providers {
+		for _, model := range models.Providers[provider] {
+			if model.Provider == "" {
+				model.Provider = provider
+			}
+			all = append(all, model)
+		}
+	}
+	return all, nil
+}
+
+// ChatChunk is one event from the /api/chat stream. Content events carry
+// Text; the usage event appended after the reply carries OutputTokens.
+type ChatChunk struct {
+	Text         string
+	OutputTokens int
+}
+
+// ChatStream yields chunks from the /api/chat stream, whose format depends
+// on the provider behind the model
+type ChatStream struct {
+	sse  *SSEReader
+	done bool
+}
+
+// StreamChat posts a chat request with streaming enabled
+func (c *APIClient) StreamChat(req types.ChatRequest) (*ChatStream, error) {
+	req.Stream = true
+	sse, _, err := c.Stream("POST", "/api/chat", req)
+	if err != nil {
+		return nil, err
+	}
+	return &ChatStream{sse: sse}, nil
+}
+
+// rawChatChunk covers the formats /api/chat passes through: OpenAI style
+// choices, Google's flattened content, Anthropic deltas, errors and the
+// usage event appended by the token tracker
+type rawChatChunk struct {
+	Type    string          `json:"type"`
+	Content string          `json:"content"`
+	Error   json.RawMessage `json:"error"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Delta struct {
+		Text string `json:"text"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int `json:"outputTokens"`
+	} `json:"usage"`
+}
+
+// Next returns the next chunk, or io.EOF after [DONE]. Error events are
+// returned as errors. Events that are not JSON, such as keep-alives, and
+// provider events without content are skipped.
+func (s *ChatStream) Next() (ChatChunk, error) {
+	for !s.done {
+		raw, err := s.sse.Next()
+		if err != nil {
+			return ChatChunk{}, err
+		}
+		if strings.TrimSpace(raw.Data) == doneSentinel {
+			s.done = true
+			break
+		}
+
+		var chunk rawChatChunk
+		if json.Unmarshal([]byte(raw.Data), &chunk) != nil {
+			continue
+		}
+		if len(chunk.Error) > 0 && string(chunk.Error) != "null" {
+			message := string(chunk.Error)
+			json.Unmarshal(chunk.Error, &message) // unwrap plain string errors
+			return ChatChunk{}, fmt.Errorf("error event: %s", message)
+		}
+		if chunk.Type == "usage" {
+			return ChatChunk{OutputTokens: chunk.Usage.OutputTokens}, nil
+		}
+
+		text := chunk.Content
+		if len(chunk.Choices) > 0 {
+			text = chunk.Choices[0].Delta.Content
+		} else if chunk.Delta.Text != "" {
+			text = chunk.Delta.Text
+		}
+		if text != "" {
+			return ChatChunk{Text: text}, nil
+		}
+	}
+	return ChatChunk{}, io.EOF
+}
+
+// Close closes the stream
+func (s *ChatStream) Close() error {
+	return s.sse.Close()
+}
@@ -16,6 +16,17 @@ type APIClient struct {
 	baseURL    string
 	authToken  string
 	httpClient *http.Client
+	retry      types.RetryPolicy
+}
+
+// StatusError is returned when a response has a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
 }
 
 // NewAPIClient creates a new API client
@@ -24,38 +35,62 @@ func NewAPIClient(config *types.Config) *APIClient {
 		baseURL:   config.BaseURL,
 		authToken: config.AuthToken,
 		httpClient: &http.Client{
-			Timeout: config.Timeout,
+			Timeout:   config.Timeout,
+			Transport: config.Transport,
 		},
+		retry: config.Retry,
 	}
 }
 
+// WithoutRetries returns a copy of the client that never retries
+func (c *APIClient) WithoutRetries() *APIClient {
+	clone := *c
+	clone.retry = types.RetryPolicy{}
+	return &clone
+}
+
 // Request performs an HTTP request and returns the response
 func (c *APIClient) Request(method, path string, body interface{}) (*http.Response, error) {
+	resp, _, err := c.do(method, path, body, nil)
+	return resp, err
+}
+
+// do performs a JSON request with extra headers, retrying per the policy
+func (c *APIClient) do(method, path string, body interface{}, header http.Header) (*http.Response, []types.Retry, error) {
 	url := c.baseURL + path
 
-	var reqBody io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonBody)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	return c.send(func() (*http.Request, error) {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(jsonBody)
+		}
 
-	// Set headers
-	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	if c.authToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.authToken)
-	}
+		req, err := http.NewRequest(method, url, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	return c.httpClient.Do(req)
+		// Set headers
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.authToken != "" {
+			req.Header.Set("Authorization", "Bearer "+c.authToken)
+		}
+		return req, nil
+	})
 }
 
 // Get performs a GET request
@@ -72,9 +107,34 @@ func (c *APIClient) Post(path string, body interface{}) (*http.Response, error)
 func (c *APIClient) TestEndpoint(name, method, path string, body interface{}) types.TestResult {
 	start := time.Now()
 	
-	resp, err := c.Request(method, path, body)
-	duration := time.Since(start)
-	
+	resp, retries, err := c.do(method, path, body, nil)
+	result := c.buildResult(name, resp, err, time.Since(start))
+	result.Retries = retries
+	return result
+}
+
+// TestEndpointWithHeader tests an endpoint with extra request headers. On
+// a client without a token, a hand-crafted Authorization header is sent
+// as is.
+func (c *APIClient) TestEndpointWithHeader(name, method, path string, body interface{}, header http.Header) types.TestResult {
+	start := time.Now()
+
+	resp, retries, err := c.do(method, path, body, header)
+	result := c.buildResult(name, resp, err, time.Since(start))
+	result.Retries = retries
+	return result
+}
+
+// TestMultipart tests a multipart endpoint and returns the result
+func (c *APIClient) TestMultipart(name, method, path string, fields map[string]string, files []FilePart) types.TestResult {
+	start := time.Now()
+
+	resp, err := c.Multipart(method, path, fields, files)
+	return c.buildResult(name, resp, err, time.Since(start))
+}
+
+// buildResult reads a response into a TestResult
+func (c *APIClient) buildResult(name string, resp *http.Response, err error, duration time.Duration) types.TestResult {
 	if err != nil {
 		return types.TestResult{
 			Name:     name,
@@ -0,0 +1,126 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// FilePart is a file field in a multipart request. The data is streamed
+// from Path when set, otherwise read from Reader.
+type FilePart struct {
+	FieldName   string
+	FileName    string
+	ContentType string
+	Path        string
+	Reader      io.Reader
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// Multipart performs a multipart/form-data request. The body is streamed
+// through a pipe so large files are never held in memory, which also means
+// multipart requests are never retried.
+func (c *APIClient) Multipart(method, path string, fields map[string]string, files []FilePart) (*http.Response, error) {
+	// Fail fast on unreadable files instead of inside the writer goroutine
+	for _, file := range files {
+		if file.Path == "" && file.Reader == nil {
+			return nil, fmt.Errorf("file part %s has no data source", file.FieldName)
+		}
+		if file.Path != "" {
+			if _, err := os.Stat(file.Path); err != nil {
+				return nil, fmt.Errorf("failed to open %s: %w", file.Path, err)
+			}
+		}
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+
+	go func() {
+		pw.CloseWithError(writeMultipart(writer, fields, files))
+	}()
+
+	req, err := http.NewRequest(method, c.baseURL+path, pr)
+	if err != nil {
+		pr.Close()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if c.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.authToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		pr.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+// writeMultipart encodes fields in sorted order followed by the files
+func writeMultipart(writer *multipart.Writer, fields map[string]string, files []FilePart) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := writer.WriteField(name, fields[name]); err != nil {
+			return fmt.Errorf("failed to write field %s: %w", name, err)
+		}
+	}
+
+	for _, file := range files {
+		if err := writeFilePart(writer, file); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func writeFilePart(writer *multipart.Writer, file FilePart) error {
+	contentType := file.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(file.FileName))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(file.FieldName), quoteEscaper.Replace(file.FileName)))
+	header.Set("Content-Type", contentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to create part %s: %w", file.FileName, err)
+	}
+
+	src := file.Reader
+	if file.Path != "" {
+		f, err := os.Open(file.Path)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", file.Path, err)
+		}
+		defer f.Close()
+		src = f
+	}
+
+	if _, err := io.Copy(part, src); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file.FileName, err)
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/omnichat/validator/internal/types"
+)
+
+// retryableStatuses are responses worth retrying after a wait
+var retryableStatuses = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// send performs the request built by newRequest, retrying per the client's
+// policy. newRequest is called once per attempt so bodies can be replayed.
+func (c *APIClient) send(newRequest func() (*http.Request, error)) (*http.Response, []types.Retry, error) {
+	var retries []types.Retry
+
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, retries, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		delay, ok := c.retryDelay(req.Method, attempt, resp, err)
+		if !ok {
+			return resp, retries, err
+		}
+
+		retry := types.Retry{Attempt: attempt, Delay: delay}
+		if err != nil {
+			retry.Error = err.Error()
+		} else {
+			retry.StatusCode = resp.StatusCode
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		retries = append(retries, retry)
+
+		time.Sleep(delay)
+	}
+}
+
+// retryDelay decides whether an attempt should be retried and how long to
+// wait first
+func (c *APIClient) retryDelay(method string, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	policy := c.retry
+	if attempt >= policy.MaxAttempts {
+		return 0, false
+	}
+	if !policy.AllMethods && !isIdempotent(method) {
+		return 0, false
+	}
+	if err == nil && !retryableStatuses[resp.StatusCode] {
+		return 0, false
+	}
+
+	// A server-provided wait wins over backoff, unless it is too long
+	if resp != nil {
+		if wait, ok := serverWait(resp, time.Now()); ok {
+			if policy.MaxDelay > 0 && wait > policy.MaxDelay {
+				return 0, false
+			}
+			return wait, true
+		}
+	}
+
+	return backoff(policy, attempt), true
+}
+
+// backoff returns the exponential delay for attempt with equal jitter
+func backoff(policy types.RetryPolicy, attempt int) time.Duration {
+	delay := policy.BaseDelay << (attempt - 1)
+	if policy.MaxDelay > 0 && (delay > policy.MaxDelay || delay <= 0) {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// serverWait reads Retry-After (seconds or HTTP date) and, for 429s,
+// X-RateLimit-Reset (ISO 8601 date or Unix seconds)
+func serverWait(resp *http.Response, now time.Time) (time.Duration, bool) {
+	header := resp.Header
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return untilOrZero(at, now), true
+		}
+	}
+
+	// The reset time only says when a 429 clears, not when a 5xx will
+	if value := header.Get("X-RateLimit-Reset"); value != "" && resp.StatusCode == http.StatusTooManyRequests {
+		if at, err := time.Parse(time.RFC3339, value); err == nil {
+			return untilOrZero(at, now), true
+		}
+		if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return untilOrZero(time.Unix(seconds, 0), now), true
+		}
+	}
+
+	return 0, false
+}
+
+func untilOrZero(at, now time.Time) time.Duration {
+	if wait := at.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
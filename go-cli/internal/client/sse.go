@@ -0,0 +1,159 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/omnichat/validator/internal/types"
+)
+
+// doneSentinel is the data payload that terminates OmniChat streams
+const doneSentinel = "[DONE]"
+
+// SSEEvent is a single raw server-sent event
+type SSEEvent struct {
+	ID    string
+	Event string
+	Data  string
+	Retry int
+}
+
+// SSEReader reads server-sent events from a response body
+type SSEReader struct {
+	body   io.ReadCloser
+	reader *bufio.Reader
+}
+
+// NewSSEReader wraps a text/event-stream body
+func NewSSEReader(body io.ReadCloser) *SSEReader {
+	return &SSEReader{body: body, reader: bufio.NewReader(body)}
+}
+
+// Next returns the next event with a data field, or io.EOF when the
+// stream ends. Comment lines and events without data are skipped.
+func (r *SSEReader) Next() (*SSEEvent, error) {
+	event := &SSEEvent{}
+	var data []string
+	hasData := false
+
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			// Flush a final event that was not followed by a blank line
+			if err == io.EOF && hasData {
+				event.Data = strings.Join(data, "\n")
+				return event, nil
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if line == "" {
+			if hasData {
+				event.Data = strings.Join(data, "\n")
+				return event, nil
+			}
+			event = &SSEEvent{}
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			event.ID = value
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+			hasData = true
+		case "retry":
+			if n, err := strconv.Atoi(value); err == nil {
+				event.Retry = n
+			}
+		}
+	}
+}
+
+// Close closes the underlying body
+func (r *SSEReader) Close() error {
+	return r.body.Close()
+}
+
+// Stream performs a request that expects a text/event-stream response.
+// Non-2xx responses are returned as a *StatusError.
+func (c *APIClient) Stream(method, path string, body interface{}) (*SSEReader, *http.Response, error) {
+	resp, _, err := c.do(method, path, body, http.Header{"Accept": {"text/event-stream"}})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, resp, &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(respBody))}
+	}
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		resp.Body.Close()
+		return nil, resp, fmt.Errorf("expected text/event-stream response, got %q", ct)
+	}
+
+	return NewSSEReader(resp.Body), resp, nil
+}
+
+// MessageStream yields typed events from the v1 messages stream
+type MessageStream struct {
+	sse  *SSEReader
+	done bool
+}
+
+// StreamMessage posts a message to a v1 conversation with streaming
+// enabled and returns the event stream
+func (c *APIClient) StreamMessage(conversationID string, req types.V1MessageRequest) (*MessageStream, error) {
+	req.Stream = true
+	sse, _, err := c.Stream("POST", "/api/v1/conversations/"+conversationID+"/messages", req)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageStream{sse: sse}, nil
+}
+
+// Next returns the next stream event. The [DONE] sentinel is returned as
+// an event of type types.StreamEventDone; io.EOF follows it.
+func (s *MessageStream) Next() (types.StreamEvent, error) {
+	if s.done {
+		return types.StreamEvent{}, io.EOF
+	}
+
+	raw, err := s.sse.Next()
+	if err != nil {
+		return types.StreamEvent{}, err
+	}
+
+	if strings.TrimSpace(raw.Data) == doneSentinel {
+		s.done = true
+		return types.StreamEvent{Type: types.StreamEventDone}, nil
+	}
+
+	var event types.StreamEvent
+	if err := json.Unmarshal([]byte(raw.Data), &event); err != nil {
+		return types.StreamEvent{}, fmt.Errorf("invalid stream event %q: %w", raw.Data, err)
+	}
+	return event, nil
+}
+
+// Close closes the stream
+func (s *MessageStream) Close() error {
+	return s.sse.Close()
+}
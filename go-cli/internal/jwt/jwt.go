@@ -0,0 +1,250 @@
+// Package jwt mints JSON Web Tokens for auth tests, including deliberately
+// broken ones, and verifies the HS256 tokens the API issues and the RS256
+// ID tokens Sign in with Apple issues.
+package jwt
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// DevSecret is the HS256 secret the API falls back to when JWT_SECRET is
+// not set, as in local development
+var DevSecret = "local-dev-secret-do-not-use-in-production-" + strings.Repeat("x", 32)
+
+// Claims is a JWT payload
+type Claims map[string]interface{}
+
+// JWK is an RSA public key in a JSON Web Key Set
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set, as served from Apple's /auth/keys
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// header is a JOSE header
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// AccessClaims returns the claims of an API access token for a user,
+// issued now and expiring after ttl
+func AccessClaims(userID, email string, ttl time.Duration) Claims {
+	now := time.Now()
+	return Claims{
+		"sub":   userID,
+		"email": email,
+		"scope": []string{"read", "write"},
+		"jti":   fmt.Sprintf("validator-%d", now.UnixNano()),
+		"iat":   now.Unix(),
+		"exp":   now.Add(ttl).Unix(),
+	}
+}
+
+// SignHS256 returns claims signed with HMAC-SHA256
+func SignHS256(claims Claims, secret string) (string, error) {
+	input, err := signingInput(header{Alg: "HS256", Typ: "JWT"}, claims)
+	if err != nil {
+		return "", err
+	}
+	return input + "." + encodeSegment(hs256(input, secret)), nil
+}
+
+// Unsigned returns claims as an "alg": "none" token with an empty
+// signature, which servers must reject
+func Unsigned(claims Claims) (string, error) {
+	input, err := signingInput(header{Alg: "none", Typ: "JWT"}, claims)
+	if err != nil {
+		return "", err
+	}
+	return input + ".", nil
+}
+
+// SignRS256 returns claims signed with an RSA key, naming the key's id in
+// the kid header as Apple does
+func SignRS256(claims Claims, key *rsa.PrivateKey, kid string) (string, error) {
+	input, err := signingInput(header{Alg: "RS256", Kid: kid}, claims)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(nil, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return input + "." + encodeSegment(signature), nil
+}
+
+// PublicJWK returns the public half of an RSA key as a JWK
+func PublicJWK(key *rsa.PublicKey, kid string) JWK {
+	return JWK{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   encodeSegment(key.N.Bytes()),
+		E:   encodeSegment(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// Decode returns the claims of a token without verifying it
+func Decode(token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token does not have three segments")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("failed to parse payload: %w", err)
+	}
+	return claims, nil
+}
+
+// VerifyHS256 checks the algorithm, signature and expiry of a token and
+// returns its claims
+func VerifyHS256(token, secret string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token does not have three segments")
+	}
+
+	h, err := decodeHeader(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if h.Alg != "HS256" {
+		return nil, fmt.Errorf("unsupported algorithm %q", h.Alg)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, hs256(parts[0]+"."+parts[1], secret)) {
+		return nil, errors.New("signature mismatch")
+	}
+	return unexpiredClaims(token)
+}
+
+// VerifyRS256 checks a token against the key its kid names in keys, then
+// its expiry, and returns its claims. Issuer and audience are left to the
+// caller.
+func VerifyRS256(token string, keys JWKS) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("token does not have three segments")
+	}
+
+	h, err := decodeHeader(parts[0])
+	if err != nil {
+		return nil, err
+	}
+	if h.Alg != "RS256" {
+		return nil, fmt.Errorf("unsupported algorithm %q", h.Alg)
+	}
+
+	var key *rsa.PublicKey
+	for _, jwk := range keys.Keys {
+		if jwk.Kid == h.Kid && jwk.Kty == "RSA" {
+			if key, err = jwk.publicKey(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if key == nil {
+		return nil, fmt.Errorf("no key with id %q", h.Kid)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("signature mismatch")
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, errors.New("signature mismatch")
+	}
+	return unexpiredClaims(token)
+}
+
+func (k JWK) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode modulus of key %q: %w", k.Kid, err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode exponent of key %q: %w", k.Kid, err)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+}
+
+func decodeHeader(segment string) (header, error) {
+	var h header
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return h, fmt.Errorf("failed to decode header: %w", err)
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return h, fmt.Errorf("failed to parse header: %w", err)
+	}
+	return h, nil
+}
+
+// unexpiredClaims decodes the claims of a verified token and checks exp
+func unexpiredClaims(token string) (Claims, error) {
+	claims, err := Decode(token)
+	if err != nil {
+		return nil, err
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("missing exp claim")
+	}
+	if time.Now().Unix() >= int64(exp) {
+		return nil, errors.New("token expired")
+	}
+	return claims, nil
+}
+
+func signingInput(header header, claims Claims) (string, error) {
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode header: %w", err)
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+	return encodeSegment(h) + "." + encodeSegment(c), nil
+}
+
+func hs256(input, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return mac.Sum(nil)
+}
+
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
@@ -0,0 +1,126 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHS256RoundTrip(t *testing.T) {
+	token, err := SignHS256(AccessClaims("user_1", "user@example.com", time.Hour), DevSecret)
+	if err != nil {
+		t.Fatalf("SignHS256: %v", err)
+	}
+
+	claims, err := VerifyHS256(token, DevSecret)
+	if err != nil {
+		t.Fatalf("VerifyHS256: %v", err)
+	}
+	if claims["sub"] != "user_1" || claims["email"] != "user@example.com" {
+		t.Errorf("claims = %v, want sub user_1 and email user@example.com", claims)
+	}
+}
+
+func TestVerifyHS256Rejects(t *testing.T) {
+	valid, err := SignHS256(AccessClaims("user_1", "", time.Hour), DevSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := SignHS256(AccessClaims("user_1", "", -time.Minute), DevSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned, err := Unsigned(AccessClaims("user_1", "", time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	noExp := AccessClaims("user_1", "", time.Hour)
+	delete(noExp, "exp")
+	withoutExp, err := SignHS256(noExp, DevSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(valid, ".")
+	tampered, err := SignHS256(AccessClaims("user_2", "", time.Hour), DevSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered = parts[0] + "." + strings.Split(tampered, ".")[1] + "." + parts[2]
+
+	tests := []struct {
+		name   string
+		token  string
+		secret string
+		want   string
+	}{
+		{"wrong secret", valid, "other-secret", "signature mismatch"},
+		{"tampered payload", tampered, DevSecret, "signature mismatch"},
+		{"expired", expired, DevSecret, "token expired"},
+		{"missing exp", withoutExp, DevSecret, "missing exp claim"},
+		{"alg none", unsigned, DevSecret, `unsupported algorithm "none"`},
+		{"malformed", "not-a-jwt", DevSecret, "three segments"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyHS256(tt.token, tt.secret)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("VerifyHS256 error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRS256RoundTrip(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := Claims{"sub": "apple-user", "exp": time.Now().Add(time.Hour).Unix()}
+	token, err := SignRS256(claims, key, "key-1")
+	if err != nil {
+		t.Fatalf("SignRS256: %v", err)
+	}
+
+	keys := JWKS{Keys: []JWK{PublicJWK(&other.PublicKey, "key-0"), PublicJWK(&key.PublicKey, "key-1")}}
+	got, err := VerifyRS256(token, keys)
+	if err != nil {
+		t.Fatalf("VerifyRS256: %v", err)
+	}
+	if got["sub"] != "apple-user" {
+		t.Errorf("sub = %v, want apple-user", got["sub"])
+	}
+
+	if _, err := VerifyRS256(token, JWKS{Keys: []JWK{PublicJWK(&key.PublicKey, "key-2")}}); err == nil || !strings.Contains(err.Error(), `no key with id "key-1"`) {
+		t.Errorf("unknown kid: error = %v", err)
+	}
+	if _, err := VerifyRS256(token, JWKS{Keys: []JWK{PublicJWK(&other.PublicKey, "key-1")}}); err == nil || err.Error() != "signature mismatch" {
+		t.Errorf("wrong key: error = %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	token, err := Unsigned(Claims{"sub": "user_1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := Decode(token)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if claims["sub"] != "user_1" {
+		t.Errorf("sub = %v, want user_1", claims["sub"])
+	}
+
+	if _, err := Decode("a.b"); err == nil {
+		t.Error("Decode of a two-segment token succeeded")
+	}
+	if _, err := Decode("a.!!!.c"); err == nil {
+		t.Error("Decode of a non-base64 payload succeeded")
+	}
+}
@@ -0,0 +1,349 @@
+package mock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/jwt"
+	"github.com/omnichat/validator/internal/types"
+)
+
+// accessTokenTTL is reported as ExpiresIn by the auth endpoints
+const accessTokenTTL = 3600
+
+var mockModels = types.ModelsResponse{
+	Providers: map[string][]types.AIModel{
+		"openai": {
+			{ID: "gpt-4o-mini", Name: "GPT-4o mini", Provider: "openai", ContextWindow: 128000, MaxOutput: 16384, SupportsVision: true, SupportsTools: true},
+			{ID: "gpt-4o", Name: "GPT-4o", Provider: "openai", ContextWindow: 128000, MaxOutput: 16384, SupportsVision: true, SupportsTools: true, SupportsWebSearch: true},
+			{ID: "gpt-image-1", Name: "GPT Image 1", Provider: "openai", ContextWindow: 32000, MaxOutput: 0, SupportsImageGeneration: true},
+		},
+		"anthropic": {
+			{ID: "claude-3-5-haiku-20241022", Name: "Claude 3.5 Haiku", Provider: "anthropic", ContextWindow: 200000, MaxOutput: 8192},
+		},
+	},
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, types.ConfigResponse{
+		StripePublishableKey: "pk_test_mock",
+		ClerkPublishableKey:  "pk_test_mock",
+		AppURL:               "http://" + r.Host,
+	})
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(s.opts.OpenAPI)
+}
+
+func (s *Server) handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("<!DOCTYPE html><html><head><title>OmniChat Mock API</title></head>" +
+		"<body><p>See <a href=\"/api/openapi.json\">/api/openapi.json</a>.</p></body></html>"))
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, mockModels)
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	var req types.RefreshTokenRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.RefreshToken == "" {
+		writeError(w, http.StatusBadRequest, "Refresh token is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	grant, issued := s.refreshTokens[req.RefreshToken]
+	switch {
+	case issued:
+	case req.RefreshToken == s.opts.RefreshToken:
+		grant = refreshGrant{userID: mockUserID}
+	default:
+		// Reuse of an exchanged token means it leaked: revoke its family
+		if family, rotated := s.rotatedTokens[req.RefreshToken]; rotated {
+			s.revokeRefreshFamily(family)
+		}
+		writeError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+
+	// Like the real server, refresh keeps the refresh token and leaves it
+	// out of the response unless rotation is enabled
+	refreshToken := req.RefreshToken
+	if s.opts.RotateRefreshTokens {
+		if issued {
+			delete(s.refreshTokens, req.RefreshToken)
+			s.rotatedTokens[req.RefreshToken] = grant.family
+		}
+		refreshToken = ""
+	}
+
+	auth, err := s.issueTokens(grant, refreshToken)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	resp := types.RefreshResponse{
+		AccessToken: auth.AccessToken,
+		ExpiresIn:   auth.ExpiresIn,
+		TokenType:   auth.TokenType,
+		User:        auth.User,
+	}
+	if s.opts.RotateRefreshTokens {
+		resp.RefreshToken = auth.RefreshToken
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// issueTokens signs an access token for the user of a grant. An empty
+// refreshToken issues a new one in the grant's family, or starts a family.
+// Callers hold s.mu.
+func (s *Server) issueTokens(grant refreshGrant, refreshToken string) (types.AuthResponse, error) {
+	userID := grant.userID
+	email := s.users[userID]
+	accessToken, err := jwt.SignHS256(jwt.AccessClaims(userID, email, accessTokenTTL*time.Second), s.opts.JWTSecret)
+	if err != nil {
+		return types.AuthResponse{}, err
+	}
+
+	if refreshToken == "" {
+		secret := make([]byte, 32)
+		rand.Read(secret)
+		refreshToken = hex.EncodeToString(secret)
+		if grant.family == "" {
+			grant.family = refreshToken
+		}
+		s.refreshTokens[refreshToken] = grant
+	}
+
+	return types.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    accessTokenTTL,
+		TokenType:    "Bearer",
+		User:         &types.AuthUser{ID: userID, Email: email},
+	}, nil
+}
+
+// revokeRefreshFamily invalidates every refresh token rotated from the
+// same sign in. Callers hold s.mu.
+func (s *Server) revokeRefreshFamily(family string) {
+	for token, grant := range s.refreshTokens {
+		if grant.family == family {
+			delete(s.refreshTokens, token)
+		}
+	}
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+	resp := types.SearchResponse{Results: []types.SearchResult{}}
+	if query == "" {
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range s.order {
+		conv := s.conversations[id]
+		if strings.Contains(strings.ToLower(conv.title), query) {
+			resp.Results = append(resp.Results, types.SearchResult{
+				Type:      "conversation",
+				ID:        conv.id,
+				Title:     conv.title,
+				CreatedAt: timestamp(conv.createdAt),
+				Model:     conv.model,
+				Score:     1,
+			})
+		}
+		for _, msg := range conv.messages {
+			if strings.Contains(strings.ToLower(msg.content), query) {
+				resp.Results = append(resp.Results, types.SearchResult{
+					Type:           "message",
+					ID:             msg.id,
+					Title:          conv.title,
+					Content:        msg.content,
+					ConversationID: conv.id,
+					MessageID:      msg.id,
+					CreatedAt:      timestamp(msg.createdAt),
+					Model:          msg.model,
+					Score:          1,
+				})
+			}
+		}
+	}
+	resp.Total = len(resp.Results)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleBattery(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := types.BatteryResponse{Balance: s.battery, UsageHistory: []types.BatteryUsage{}}
+	for _, date := range s.usageDates() {
+		day := s.usage[date]
+		resp.UsageHistory = append(resp.UsageHistory, types.BatteryUsage{
+			Date:     date,
+			Usage:    day.batteryUsed,
+			Balance:  s.battery,
+			Messages: day.messages,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCheckout(w http.ResponseWriter, r *http.Request) {
+	var req types.CheckoutRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Type != "subscription" && req.Type != "battery" {
+		writeError(w, http.StatusBadRequest, "Invalid checkout type")
+		return
+	}
+
+	s.mu.Lock()
+	id := s.newID("cs_test")
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, types.CheckoutResponse{
+		SessionID:  id,
+		SessionURL: "https://checkout.stripe.com/c/pay/" + id,
+	})
+}
+
+func (s *Server) handlePortal(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, types.BillingPortalResponse{URL: "https://billing.stripe.com/p/session/mock"})
+}
+
+func (s *Server) handleProfile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.userProfile(requestUser(r)))
+}
+
+func (s *Server) handleUpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var req types.UserProfileUpdate
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Name == "" && req.ImageURL == "" {
+		writeError(w, http.StatusBadRequest, "No fields to update")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.Name != "" {
+		s.profile.name = req.Name
+	}
+	if req.ImageURL != "" {
+		s.profile.imageURL = req.ImageURL
+	}
+	writeJSON(w, http.StatusOK, types.UserProfileUpdateResponse{
+		ID:        requestUser(r),
+		UpdatedAt: timestamp(time.Now()),
+		Name:      req.Name,
+		ImageURL:  req.ImageURL,
+	})
+}
+
+// userProfile builds the profile response. Only the id and email differ
+// between users; the name and battery are shared. Callers hold s.mu.
+func (s *Server) userProfile(userID string) types.UserProfile {
+	return types.UserProfile{
+		ID:        userID,
+		Email:     s.users[userID],
+		Name:      nullable(s.profile.name),
+		ImageURL:  nullable(s.profile.imageURL),
+		Tier:      "free",
+		CreatedAt: "2024-01-01T00:00:00Z",
+		Battery: &types.UserBattery{
+			TotalBalance:   s.battery,
+			DailyAllowance: 0,
+			LastDailyReset: time.Now().UTC().Format("2006-01-02"),
+		},
+	}
+}
+
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resp types.UserUsageResponse
+	now := time.Now().UTC()
+	resp.Period.Start = now.AddDate(0, 0, -30).Format("2006-01-02")
+	resp.Period.End = now.Format("2006-01-02")
+
+	dates := s.usageDates()
+	// The element types are anonymous, so grow the slices in place
+	resp.DailyUsage = slices.Grow(resp.DailyUsage, len(dates))[:len(dates)]
+	byModel := make(map[string]int)
+	for i, date := range dates {
+		day := s.usage[date]
+		resp.DailyUsage[i].Date = date
+		resp.DailyUsage[i].BatteryUsed = day.batteryUsed
+		resp.DailyUsage[i].Messages = day.messages
+		resp.DailyUsage[i].Models = day.models
+
+		resp.Summary.TotalBatteryUsed += day.batteryUsed
+		resp.Summary.TotalMessages += day.messages
+		for model, count := range day.models {
+			byModel[model] += count
+		}
+	}
+	if len(dates) > 0 {
+		resp.Summary.AverageDailyUsage = resp.Summary.TotalBatteryUsed / len(dates)
+	}
+	resp.Summary.TotalConversations = len(s.conversations)
+	for _, conv := range s.conversations {
+		for _, msg := range conv.messages {
+			if msg.role == "user" {
+				resp.Summary.TotalUserMessages++
+			}
+		}
+	}
+
+	models := make([]string, 0, len(byModel))
+	for model := range byModel {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	resp.ModelBreakdown = slices.Grow(resp.ModelBreakdown, len(models))[:len(models)]
+	for i, model := range models {
+		resp.ModelBreakdown[i].Model = model
+		resp.ModelBreakdown[i].MessageCount = byModel[model]
+		resp.ModelBreakdown[i].Percentage = float64(byModel[model]) * 100 / float64(resp.Summary.TotalMessages)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// usageDates returns the dates with recorded usage, oldest first. Callers
+// hold s.mu.
+func (s *Server) usageDates() []string {
+	dates := make([]string, 0, len(s.usage))
+	for date := range s.usage {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	return dates
+}
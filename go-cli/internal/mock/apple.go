@@ -0,0 +1,111 @@
+package mock
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/omnichat/validator/internal/jwt"
+	"github.com/omnichat/validator/internal/types"
+)
+
+// appleIssuer is the iss claim of every Apple ID token
+const appleIssuer = "https://appleid.apple.com"
+
+// jwksClient fetches Options.AppleJWKS
+var jwksClient = &http.Client{Timeout: 5 * time.Second}
+
+// handleAppleAuth signs in with an Apple ID token. As on the API, the
+// first sign in of an Apple subject creates a user, or links the user with
+// the same email, and later sign ins return the linked user.
+func (s *Server) handleAppleAuth(w http.ResponseWriter, r *http.Request) {
+	var req types.AppleAuthRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.IDToken == "" {
+		writeError(w, http.StatusBadRequest, "ID token is required")
+		return
+	}
+
+	claims, err := s.verifyAppleToken(req.IDToken)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, "Invalid Apple ID token")
+		return
+	}
+	subject, _ := claims["sub"].(string)
+	email, _ := claims["email"].(string)
+	if email == "" && req.User != nil {
+		email = req.User.Email
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userID, linked := s.appleUsers[subject]
+	if !linked {
+		if email == "" {
+			writeError(w, http.StatusBadRequest, "Email required for new users")
+			return
+		}
+		userID = s.userByEmail(email)
+		if userID == "" {
+			userID = s.newID("user_apple")
+			s.users[userID] = email
+		}
+		s.appleUsers[subject] = userID
+	}
+
+	resp, err := s.issueTokens(refreshGrant{userID: userID}, "")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// verifyAppleToken checks an ID token's signature against the keys at
+// Options.AppleJWKS, then its expiry, issuer and audience
+func (s *Server) verifyAppleToken(token string) (jwt.Claims, error) {
+	if s.opts.AppleJWKS == "" {
+		return nil, errors.New("no Apple JWKS configured")
+	}
+
+	resp, err := jwksClient.Get(s.opts.AppleJWKS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Apple keys: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch Apple keys: HTTP %d", resp.StatusCode)
+	}
+	var keys jwt.JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&keys); err != nil {
+		return nil, fmt.Errorf("failed to parse Apple keys: %w", err)
+	}
+
+	claims, err := jwt.VerifyRS256(token, keys)
+	if err != nil {
+		return nil, err
+	}
+	if claims["iss"] != appleIssuer {
+		return nil, fmt.Errorf("unexpected issuer %v", claims["iss"])
+	}
+	if claims["aud"] != s.opts.AppleClientID {
+		return nil, fmt.Errorf("unexpected audience %v", claims["aud"])
+	}
+	return claims, nil
+}
+
+// userByEmail returns the id of the user with an email, or an empty
+// string. Callers hold s.mu.
+func (s *Server) userByEmail(email string) string {
+	for id, userEmail := range s.users {
+		if userEmail == email {
+			return id
+		}
+	}
+	return ""
+}
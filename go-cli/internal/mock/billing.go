@@ -0,0 +1,182 @@
+package mock
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/omnichat/validator/internal/stripe"
+	"github.com/omnichat/validator/internal/types"
+)
+
+// subscription is a user's Stripe subscription as the webhooks left it
+type subscription struct {
+	id         string
+	customerID string
+	planID     string
+	status     string
+	periodEnd  time.Time
+}
+
+// subscribed reports whether the subscription is current. A past_due
+// subscription stays current while Stripe retries the payment.
+func (sub *subscription) subscribed() bool {
+	if sub == nil {
+		return false
+	}
+	switch sub.status {
+	case "active", "trialing", "past_due":
+		return true
+	}
+	return false
+}
+
+// handleStripeWebhook verifies the Stripe-Signature header with
+// Options.StripeWebhookSecret and applies the event to the user named in
+// its metadata. Unknown users and event types are acknowledged and
+// ignored, as on the API.
+func (s *Server) handleStripeWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to read body")
+		return
+	}
+	header := r.Header.Get("Stripe-Signature")
+	if header == "" {
+		writeError(w, http.StatusBadRequest, "No signature")
+		return
+	}
+	if err := stripe.Verify(payload, header, s.opts.StripeWebhookSecret, stripe.DefaultTolerance); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid signature")
+		return
+	}
+	var event stripe.Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid payload")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.applyStripeEvent(event) {
+		writeError(w, http.StatusInternalServerError, "Webhook handler failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"received": true})
+}
+
+// applyStripeEvent updates subscriptions, the tier and the battery for an
+// event. Like the API, only a checkout and a subscription update set the
+// tier: a failed payment and a deleted subscription leave it as it was. It
+// returns false for a checkout of an unknown plan, which the API fails on.
+// Callers hold s.mu.
+func (s *Server) applyStripeEvent(event stripe.Event) bool {
+	object := event.Data.Object
+	metadata, _ := object["metadata"].(map[string]interface{})
+	userID, _ := metadata["userId"].(string)
+	planID, _ := metadata["planId"].(string)
+
+	switch event.Type {
+	case stripe.CheckoutSessionCompleted:
+		if _, known := s.users[userID]; !known {
+			return true
+		}
+		if object["mode"] == "payment" {
+			units, _ := strconv.Atoi(stringField(metadata, "batteryUnits"))
+			s.battery += units
+			return true
+		}
+		plan, ok := stripe.Plans[planID]
+		if !ok {
+			return false
+		}
+		s.subscriptions[userID] = &subscription{
+			id:         stringField(object, "subscription"),
+			customerID: stringField(object, "customer"),
+			planID:     planID,
+			status:     "active",
+			periodEnd:  time.Now().AddDate(0, 1, 0),
+		}
+		s.tiers[userID] = "paid"
+		s.battery += plan.BatteryUnits
+
+	case stripe.SubscriptionUpdated, stripe.SubscriptionDeleted:
+		if _, known := s.users[userID]; known && event.Type == stripe.SubscriptionUpdated {
+			switch stringField(object, "status") {
+			case "active", "trialing":
+				s.tiers[userID] = "paid"
+			default:
+				s.tiers[userID] = "free"
+			}
+		}
+		sub := s.subscriptionByID(stringField(object, "id"))
+		if sub == nil {
+			return true
+		}
+		sub.status = stringField(object, "status")
+		if event.Type == stripe.SubscriptionDeleted {
+			sub.status = "canceled"
+		}
+		if _, ok := stripe.Plans[planID]; ok {
+			sub.planID = planID
+		}
+		if end, ok := object["current_period_end"].(float64); ok {
+			sub.periodEnd = time.Unix(int64(end), 0)
+		}
+
+	case stripe.InvoicePaymentFailed:
+		if sub := s.subscriptionByID(stringField(object, "subscription")); sub != nil {
+			sub.status = "past_due"
+		}
+	}
+	return true
+}
+
+// subscriptionByID finds a subscription by its Stripe id. Callers hold
+// s.mu.
+func (s *Server) subscriptionByID(id string) *subscription {
+	for _, sub := range s.subscriptions {
+		if sub.id == id && id != "" {
+			return sub
+		}
+	}
+	return nil
+}
+
+// handleTier reports the tier only, as /api/user/tier does
+func (s *Server) handleTier(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tier := s.tiers[requestUser(r)]
+	if tier == "" {
+		tier = "free"
+	}
+	writeJSON(w, http.StatusOK, types.UserTierResponse{Tier: tier})
+}
+
+func (s *Server) handleSubscriptionStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := s.subscriptions[requestUser(r)]
+	if sub == nil {
+		writeJSON(w, http.StatusOK, types.SubscriptionStatusResponse{IsSubscribed: false})
+		return
+	}
+	writeJSON(w, http.StatusOK, types.SubscriptionStatusResponse{
+		IsSubscribed:     sub.subscribed(),
+		SubscriptionID:   sub.id,
+		PlanName:         stripe.Plans[sub.planID].Name,
+		Status:           sub.status,
+		CurrentPeriodEnd: timestamp(sub.periodEnd),
+	})
+}
+
+func stringField(object map[string]interface{}, key string) string {
+	value, _ := object[key].(string)
+	return value
+}
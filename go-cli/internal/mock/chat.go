@@ -0,0 +1,124 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"strings"
+
+	"github.com/omnichat/validator/internal/types"
+)
+
+// namedColors are the colors the mock can recognize in attached images
+var namedColors = []struct {
+	name string
+	rgb  [3]float64
+}{
+	{"red", [3]float64{255, 0, 0}},
+	{"green", [3]float64{0, 255, 0}},
+	{"blue", [3]float64{0, 0, 255}},
+	{"yellow", [3]float64{255, 255, 0}},
+	{"white", [3]float64{255, 255, 255}},
+	{"black", [3]float64{0, 0, 0}},
+}
+
+// chatReply answers a /api/chat request the way the model it names would:
+// image models return an image_generation payload, vision models describe
+// the color of an attached image and other models send the canned reply
+func chatReply(req types.ChatRequest) string {
+	model, _ := findModel(req.Model)
+	if model.SupportsImageGeneration {
+		payload, _ := json.Marshal(map[string]string{
+			"type":   "image_generation",
+			"base64": generatedImage(),
+			"model":  req.Model,
+			"prompt": req.Messages[len(req.Messages)-1].Content,
+		})
+		return string(payload)
+	}
+
+	last := req.Messages[len(req.Messages)-1]
+	if model.SupportsVision && len(last.Images) > 0 {
+		if name, ok := dominantColor(last.Images[0]); ok {
+			return fmt.Sprintf("The image is %s.", name)
+		}
+		return "I could not read the attached image."
+	}
+	return mockReply(req.Model)
+}
+
+// findModel looks a model up in the advertised catalog
+func findModel(id string) (types.AIModel, bool) {
+	for _, models := range mockModels.Providers {
+		for _, model := range models {
+			if model.ID == id {
+				return model, true
+			}
+		}
+	}
+	return types.AIModel{}, false
+}
+
+// dominantColor names the basic color closest to the average color of a
+// base64 data URL image
+func dominantColor(dataURL string) (string, bool) {
+	_, encoded, ok := strings.Cut(dataURL, ";base64,")
+	if !ok {
+		return "", false
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", false
+	}
+
+	var sum [3]float64
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			sum[0] += float64(r >> 8)
+			sum[1] += float64(g >> 8)
+			sum[2] += float64(b >> 8)
+		}
+	}
+	pixels := float64(bounds.Dx() * bounds.Dy())
+	if pixels == 0 {
+		return "", false
+	}
+
+	best, bestDistance := "", -1.0
+	for _, named := range namedColors {
+		var distance float64
+		for i := range sum {
+			d := sum[i]/pixels - named.rgb[i]
+			distance += d * d
+		}
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = named.name, distance
+		}
+	}
+	return best, true
+}
+
+// generatedImage returns a small base64 PNG standing in for a generated
+// image
+func generatedImage() string {
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := range 8 {
+		for x := range 8 {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
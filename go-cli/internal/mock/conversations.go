@@ -0,0 +1,410 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/types"
+)
+
+const defaultModel = "gpt-4o-mini"
+
+func (c *conversation) toType() types.Conversation {
+	conv := types.Conversation{
+		ID:         c.id,
+		Title:      c.title,
+		Model:      c.model,
+		IsArchived: c.isArchived,
+		CreatedAt:  timestamp(c.createdAt),
+		UpdatedAt:  timestamp(c.updatedAt),
+	}
+	if n := len(c.messages); n > 0 {
+		last := c.messages[n-1]
+		conv.LastMessage = &types.LastMessage{
+			ID:        last.id,
+			Role:      last.role,
+			Content:   last.content,
+			CreatedAt: timestamp(last.createdAt),
+		}
+	}
+	return conv
+}
+
+func (m message) toType(conversationID string) types.Message {
+	return types.Message{
+		ID:             m.id,
+		ConversationID: conversationID,
+		Role:           m.role,
+		Content:        m.content,
+		Model:          nullable(m.model),
+		IsComplete:     true,
+		CreatedAt:      timestamp(m.createdAt),
+	}
+}
+
+// toStored returns the whole row, as the web app's routes send it
+func (m message) toStored(conversationID string) types.StoredMessage {
+	return types.StoredMessage{Message: m.toType(conversationID)}
+}
+
+func (s *Server) handleListConversations(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userID := requestUser(r)
+	resp := types.ConversationsResponse{Conversations: []types.Conversation{}}
+	for i := len(s.order) - 1; i >= 0; i-- {
+		if conv := s.conversations[s.order[i]]; conv.userID == userID {
+			resp.Conversations = append(resp.Conversations, conv.toType())
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCreateConversation(w http.ResponseWriter, r *http.Request) {
+	var req types.ConversationRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		writeError(w, http.StatusBadRequest, "Title is required")
+		return
+	}
+	if req.Model == "" {
+		req.Model = defaultModel
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	conv := &conversation{
+		id:        s.newID("conv"),
+		userID:    requestUser(r),
+		title:     req.Title,
+		model:     req.Model,
+		createdAt: now,
+		updatedAt: now,
+	}
+	s.conversations[conv.id] = conv
+	s.order = append(s.order, conv.id)
+
+	writeJSON(w, http.StatusOK, conv.toType())
+}
+
+func (s *Server) handleGetConversationV1(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, ok := s.ownedConversation(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, types.ConversationDetail{Conversation: conv.toType(), MessageCount: len(conv.messages)})
+}
+
+func (s *Server) handleUpdateConversationV1(w http.ResponseWriter, r *http.Request) {
+	// Pointers distinguish an omitted isArchived from false
+	var req struct {
+		Title      *string `json:"title"`
+		IsArchived *bool   `json:"isArchived"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if (req.Title == nil || *req.Title == "") && req.IsArchived == nil {
+		writeError(w, http.StatusBadRequest, "No fields to update")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, ok := s.ownedConversation(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+
+	conv.updatedAt = time.Now()
+	resp := types.ConversationUpdateResponse{ID: conv.id, UpdatedAt: timestamp(conv.updatedAt)}
+	if req.Title != nil {
+		conv.title = *req.Title
+		resp.Title = conv.title
+	}
+	if req.IsArchived != nil {
+		conv.isArchived = *req.IsArchived
+		resp.IsArchived = req.IsArchived
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleDeleteConversation(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, ok := s.ownedConversation(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+	id := conv.id
+	delete(s.conversations, id)
+	for i, existing := range s.order {
+		if existing == id {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	writeJSON(w, http.StatusOK, types.SuccessResponse{Success: true})
+}
+
+// handleListMessages lists every message, unpaged like the web app route
+func (s *Server) handleListMessages(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, ok := s.ownedConversation(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+
+	resp := types.StoredMessagesResponse{Messages: []types.StoredMessage{}}
+	for _, msg := range conv.messages {
+		resp.Messages = append(resp.Messages, msg.toStored(conv.id))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleListMessagesV1(w http.ResponseWriter, r *http.Request) {
+	limit := queryInt(r, "limit", 50)
+	offset := queryInt(r, "offset", 0)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, ok := s.ownedConversation(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+
+	resp := types.MessagesResponse{Messages: []types.Message{}, Total: len(conv.messages)}
+	for i := offset; i < len(conv.messages) && i < offset+limit; i++ {
+		resp.Messages = append(resp.Messages, conv.messages[i].toType(conv.id))
+	}
+	resp.HasMore = len(conv.messages) > offset+limit
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleCreateMessage stores a message without generating a reply, as the
+// web app saves both sides of the exchange itself
+func (s *Server) handleCreateMessage(w http.ResponseWriter, r *http.Request) {
+	var req types.MessageRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.Role == "" || req.Content == "" {
+		writeError(w, http.StatusBadRequest, "Role and content are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conv, ok := s.ownedConversation(r)
+	if !ok {
+		writeError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+
+	msg := s.appendMessage(conv, req.Role, req.Content, req.Model)
+	writeJSON(w, http.StatusOK, types.StoredMessageResponse{Message: msg.toStored(conv.id)})
+}
+
+func (s *Server) handleSendMessageV1(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Content       string   `json:"content"`
+		AttachmentIDs []string `json:"attachmentIds"`
+		Stream        *bool    `json:"stream"`
+	}
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		writeError(w, http.StatusBadRequest, "Message content is required")
+		return
+	}
+
+	s.mu.Lock()
+	conv, ok := s.ownedConversation(r)
+	if !ok {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "Conversation not found")
+		return
+	}
+	s.appendMessage(conv, "user", req.Content, "")
+	reply := s.appendMessage(conv, "assistant", mockReply(conv.model), conv.model)
+	s.charge(conv.model)
+	conversationID := conv.id
+	s.mu.Unlock()
+
+	// The server streams unless told otherwise
+	if req.Stream == nil || *req.Stream {
+		streamV1Reply(w, reply)
+		return
+	}
+	writeJSON(w, http.StatusOK, types.SentMessage{
+		ID:             reply.id,
+		ConversationID: conversationID,
+		Role:           reply.role,
+		Content:        reply.content,
+		Model:          reply.model,
+		CreatedAt:      timestamp(reply.createdAt),
+	})
+}
+
+// streamV1Reply emits the v1 message_start, content_chunk*,
+// message_complete, [DONE] event sequence
+func streamV1Reply(w http.ResponseWriter, reply message) {
+	sse := newEventWriter(w)
+	sse.data(types.StreamEvent{ID: reply.id, Type: types.StreamEventMessageStart})
+	for _, chunk := range splitChunks(reply.content) {
+		sse.data(types.StreamEvent{ID: reply.id, Type: types.StreamEventContentChunk, Content: chunk})
+	}
+	sse.data(types.StreamEvent{ID: reply.id, Type: types.StreamEventMessageComplete, Content: reply.content})
+	sse.done()
+}
+
+func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
+	var req types.ChatRequest
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "Messages are required")
+		return
+	}
+	if req.Model == "" {
+		writeError(w, http.StatusBadRequest, "Model is required")
+		return
+	}
+	if req.ConversationID == "" {
+		writeError(w, http.StatusBadRequest, "Missing required fields: messages, model, and conversationId")
+		return
+	}
+
+	s.mu.Lock()
+	id := s.newID("chatcmpl")
+	s.charge(req.Model)
+	s.mu.Unlock()
+
+	content := chatReply(req)
+	if !req.Stream {
+		writeJSON(w, http.StatusOK, types.ChatResponse{ID: id, Content: content, Model: req.Model})
+		return
+	}
+
+	// OpenAI-style chunks, the most common provider format behind /api/chat
+	sse := newEventWriter(w)
+	for _, chunk := range splitChunks(content) {
+		sse.data(map[string]interface{}{
+			"id":      id,
+			"model":   req.Model,
+			"choices": []interface{}{map[string]interface{}{"index": 0, "delta": map[string]string{"content": chunk}}},
+		})
+	}
+	sse.done()
+}
+
+// ownedConversation returns the conversation named in the path when the
+// requesting user owns it. Anyone else gets a 404, as on the real server,
+// which filters by user. Callers hold s.mu.
+func (s *Server) ownedConversation(r *http.Request) (*conversation, bool) {
+	conv, ok := s.conversations[r.PathValue("id")]
+	if !ok || conv.userID != requestUser(r) {
+		return nil, false
+	}
+	return conv, true
+}
+
+// appendMessage adds a message to conv. Callers hold s.mu.
+func (s *Server) appendMessage(conv *conversation, role, content, model string) message {
+	msg := message{id: s.newID("msg"), role: role, content: content, model: model, createdAt: time.Now()}
+	conv.messages = append(conv.messages, msg)
+	conv.updatedAt = msg.createdAt
+	return msg
+}
+
+// charge deducts the cost of one reply from the battery. Callers hold s.mu.
+func (s *Server) charge(model string) {
+	s.battery -= messageCost
+
+	date := time.Now().UTC().Format("2006-01-02")
+	day, ok := s.usage[date]
+	if !ok {
+		day = &dailyUsage{models: make(map[string]int)}
+		s.usage[date] = day
+	}
+	day.batteryUsed += messageCost
+	day.messages++
+	day.models[model]++
+}
+
+func mockReply(model string) string {
+	return fmt.Sprintf("Hello from the OmniChat mock server, answering as %s.", model)
+}
+
+// splitChunks splits content into word-sized chunks that concatenate back
+// to the original string
+func splitChunks(content string) []string {
+	var chunks []string
+	for len(content) > 0 {
+		i := strings.IndexByte(content[1:], ' ')
+		if i < 0 {
+			chunks = append(chunks, content)
+			break
+		}
+		chunks = append(chunks, content[:i+1])
+		content = content[i+1:]
+	}
+	return chunks
+}
+
+func queryInt(r *http.Request, name string, fallback int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil || n < 0 {
+		return fallback
+	}
+	return n
+}
+
+// eventWriter writes flushed server-sent events
+type eventWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func newEventWriter(w http.ResponseWriter) *eventWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache, no-transform")
+	w.WriteHeader(http.StatusOK)
+	return &eventWriter{w: w, rc: http.NewResponseController(w)}
+}
+
+func (e *eventWriter) data(payload interface{}) {
+	data, _ := json.Marshal(payload)
+	fmt.Fprintf(e.w, "data: %s\n\n", data)
+	e.rc.Flush()
+}
+
+func (e *eventWriter) done() {
+	fmt.Fprint(e.w, "data: [DONE]\n\n")
+	e.rc.Flush()
+}
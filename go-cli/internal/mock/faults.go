@@ -0,0 +1,138 @@
+package mock
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault actions
+const (
+	FaultStatus = "status" // respond with Status and an ErrorResponse
+	FaultDelay  = "delay"  // sleep for Delay, then handle normally
+	FaultReset  = "reset"  // close the connection without a response
+)
+
+// Fault injects a failure into requests matching Method and Pattern.
+// Pattern uses path.Match syntax against the URL path; "*" matches every
+// path. Rate is the probability of the fault firing, 1 when zero.
+type Fault struct {
+	Method  string
+	Pattern string
+	Action  string
+	Status  int
+	Delay   time.Duration
+	Rate    float64
+}
+
+// ParseFault parses "[METHOD ]PATTERN=ACTION[@RATE]" where ACTION is an
+// HTTP status code, a duration such as 500ms, or "reset". For example
+// "POST /api/v1/upload=503@0.5" or "/api/chat=reset".
+func ParseFault(value string) (Fault, error) {
+	target, action, ok := strings.Cut(value, "=")
+	if !ok || target == "" || action == "" {
+		return Fault{}, fmt.Errorf("invalid fault %q, expected [METHOD ]PATTERN=ACTION[@RATE]", value)
+	}
+
+	var fault Fault
+	if method, pattern, ok := strings.Cut(strings.TrimSpace(target), " "); ok {
+		fault.Method = strings.ToUpper(method)
+		fault.Pattern = strings.TrimSpace(pattern)
+	} else {
+		fault.Pattern = strings.TrimSpace(target)
+	}
+	if _, err := path.Match(fault.Pattern, "/"); err != nil {
+		return Fault{}, fmt.Errorf("invalid fault pattern %q: %w", fault.Pattern, err)
+	}
+
+	action, rate, hasRate := strings.Cut(action, "@")
+	if hasRate {
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil || r <= 0 || r > 1 {
+			return Fault{}, fmt.Errorf("invalid fault rate %q, expected a number in (0, 1]", rate)
+		}
+		fault.Rate = r
+	}
+
+	if action == FaultReset {
+		fault.Action = FaultReset
+	} else if status, err := strconv.Atoi(action); err == nil {
+		if status < 100 || status > 599 {
+			return Fault{}, fmt.Errorf("invalid fault status %d", status)
+		}
+		fault.Action = FaultStatus
+		fault.Status = status
+	} else if delay, err := time.ParseDuration(action); err == nil {
+		fault.Action = FaultDelay
+		fault.Delay = delay
+	} else {
+		return Fault{}, fmt.Errorf("invalid fault action %q, expected a status code, duration or reset", action)
+	}
+
+	return fault, nil
+}
+
+// Matches reports whether the fault targets the request
+func (f Fault) Matches(r *http.Request) bool {
+	if f.Method != "" && f.Method != r.Method {
+		return false
+	}
+	if f.Pattern == "*" {
+		return true
+	}
+	matched, _ := path.Match(f.Pattern, r.URL.Path)
+	return matched
+}
+
+// Apply fires the fault, subject to its rate, and reports whether the
+// response has been written
+func (f Fault) Apply(w http.ResponseWriter, r *http.Request) bool {
+	if f.Rate > 0 && rand.Float64() >= f.Rate {
+		return false
+	}
+
+	switch f.Action {
+	case FaultDelay:
+		time.Sleep(f.Delay)
+		return false
+	case FaultReset:
+		conn, _, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Injected connection reset")
+			return true
+		}
+		conn.Close()
+		return true
+	default:
+		if f.Status == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		writeError(w, f.Status, fmt.Sprintf("Injected fault: %s", http.StatusText(f.Status)))
+		return true
+	}
+}
+
+// String formats the fault in ParseFault syntax
+func (f Fault) String() string {
+	target := f.Pattern
+	if f.Method != "" {
+		target = f.Method + " " + target
+	}
+
+	action := f.Action
+	switch f.Action {
+	case FaultStatus:
+		action = strconv.Itoa(f.Status)
+	case FaultDelay:
+		action = f.Delay.String()
+	}
+
+	if f.Rate > 0 {
+		return fmt.Sprintf("%s=%s@%g", target, action, f.Rate)
+	}
+	return target + "=" + action
+}
@@ -0,0 +1,156 @@
+package mock
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/types"
+)
+
+const maxUploadSize = 32 << 20
+
+// readUpload parses the multipart form and returns the "file" part
+func readUpload(w http.ResponseWriter, r *http.Request) (*multipart.FileHeader, []byte, bool) {
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid multipart form")
+		return nil, nil, false
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "No file provided")
+		return nil, nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to read file")
+		return nil, nil, false
+	}
+	return header, data, true
+}
+
+// storeFile saves data under userId/conversationId/messageId/fileId.ext
+func (s *Server) storeFile(userID, conversationID, messageID, fileName, contentType string, data []byte) (id, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id = s.newID("file")
+	key = strings.Join([]string{userID, conversationID, messageID, id + path.Ext(fileName)}, "/")
+	s.files[key] = &storedFile{contentType: contentType, data: data}
+	return id, key
+}
+
+func (s *Server) handleUploadV1(w http.ResponseWriter, r *http.Request) {
+	header, data, ok := readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	conversationID := r.FormValue("conversationId")
+	if conversationID == "" {
+		conversationID = "temp"
+	}
+	messageID := r.FormValue("messageId")
+	if messageID == "" {
+		messageID = "temp"
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	id, key := s.storeFile(requestUser(r), conversationID, messageID, header.Filename, contentType, data)
+
+	writeJSON(w, http.StatusOK, types.Attachment{
+		ID:       id,
+		URL:      "/api/v1/files/" + key,
+		FileName: header.Filename,
+		FileType: contentType,
+		FileSize: len(data),
+		Key:      key,
+	})
+}
+
+func (s *Server) handleFileV1(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if !strings.HasPrefix(key, requestUser(r)+"/") {
+		writeError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+	s.serveFile(w, key)
+}
+
+func (s *Server) handleDeleteFileV1(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if !strings.HasPrefix(key, requestUser(r)+"/") {
+		writeError(w, http.StatusForbidden, "Access denied")
+		return
+	}
+
+	s.mu.Lock()
+	delete(s.files, key)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, types.SuccessResponse{Success: true})
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request) {
+	header, data, ok := readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	conversationID := r.FormValue("conversationId")
+	messageID := r.FormValue("messageId")
+	if conversationID == "" || messageID == "" {
+		writeError(w, http.StatusBadRequest, "Missing required fields")
+		return
+	}
+
+	contentType := header.Header.Get("Content-Type")
+	id, key := s.storeFile(requestUser(r), conversationID, messageID, header.Filename, contentType, data)
+
+	writeJSON(w, http.StatusOK, types.UploadResponse{
+		Success: true,
+		Attachment: &types.FileAttachment{
+			ID:             id,
+			ConversationID: conversationID,
+			MessageID:      messageID,
+			FileName:       header.Filename,
+			FileSize:       len(data),
+			MimeType:       contentType,
+			UploadedAt:     timestamp(time.Now()),
+			R2Key:          key,
+		},
+	})
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("key")
+	if key == "" {
+		writeError(w, http.StatusBadRequest, "Missing key")
+		return
+	}
+	s.serveFile(w, key)
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, key string) {
+	s.mu.Lock()
+	file, ok := s.files[key]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "File not found")
+		return
+	}
+
+	contentType := file.contentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(file.data)
+}
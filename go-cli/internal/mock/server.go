@@ -0,0 +1,464 @@
+// Package mock implements an in-memory OmniChat API for offline validator
+// runs and tests. Responses use the shapes in internal/types.
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/omnichat/validator/internal/jwt"
+)
+
+// Default credentials accepted by the mock server
+const (
+	DefaultClerkToken   = "mock-clerk-token"
+	DefaultJWTToken     = "mock-jwt-token"
+	DefaultSecondToken  = "mock-jwt-token-b"
+	DefaultRefreshToken = "mock-refresh-token"
+
+	// DefaultAppleClientID is the audience of Apple ID tokens, matching the
+	// API's APPLE_CLIENT_ID fallback
+	DefaultAppleClientID = "com.example.omnichat"
+
+	// DefaultStripeWebhookSecret verifies Stripe-Signature headers
+	DefaultStripeWebhookSecret = "whsec_mock"
+)
+
+const (
+	mockUserID      = "user_mock"
+	mockUserEmail   = "mock@omnichat.local"
+	secondUserID    = "user_mock_b"
+	secondUserEmail = "mock-b@omnichat.local"
+	startingBattery = 10000
+	messageCost     = 10 // battery units charged per assistant reply
+)
+
+// minimalOpenAPI is served when no OpenAPI document is configured
+const minimalOpenAPI = `{"openapi":"3.0.3","info":{"title":"OmniChat Mock API","version":"0.0.0"},"paths":{}}`
+
+// Options configures a mock server. Empty tokens fall back to the defaults.
+type Options struct {
+	ClerkToken   string
+	JWTToken     string
+	RefreshToken string
+
+	// SecondToken authenticates a second v1 user, who cannot see the
+	// first user's conversations or files
+	SecondToken string
+
+	// JWTSecret verifies HS256 access tokens minted for either user, as
+	// the API's JWT_SECRET does. Like the API, the audience is not
+	// checked. Defaults to jwt.DevSecret.
+	JWTSecret string
+
+	// AppleJWKS is the URL Apple ID tokens are verified against, standing
+	// in for https://appleid.apple.com/auth/keys. Without it every ID token
+	// is rejected. AppleClientID is the expected audience and defaults to
+	// DefaultAppleClientID.
+	AppleJWKS     string
+	AppleClientID string
+
+	// RotateRefreshTokens makes refresh return a new refresh token and
+	// invalidate the one exchanged; reusing an exchanged token revokes every
+	// token rotated from the same sign in. RefreshToken itself stays valid,
+	// so each run can start from it.
+	RotateRefreshTokens bool
+
+	// StripeWebhookSecret verifies the signature of events posted to
+	// /api/stripe/webhook. Defaults to DefaultStripeWebhookSecret.
+	StripeWebhookSecret string
+
+	// OpenAPI is served from /api/openapi.json
+	OpenAPI []byte
+
+	// Faults are applied to matching requests before routing
+	Faults []Fault
+
+	// Latency is added to every response
+	Latency time.Duration
+
+	// RateLimit caps v1 requests per client and RateWindow, mirroring
+	// withRateLimit; zero disables it. Uploads get UploadRateLimit, which
+	// defaults to a fifth of RateLimit as on the real server (20 vs 100).
+	RateLimit       int
+	UploadRateLimit int
+	RateWindow      time.Duration
+
+	// Logf, when set, receives one line per request
+	Logf func(format string, args ...interface{})
+}
+
+// Server is an in-memory OmniChat API
+type Server struct {
+	opts    Options
+	handler http.Handler
+
+	mu            sync.Mutex
+	nextID        int
+	conversations map[string]*conversation
+	order         []string // conversation ids in creation order
+	files         map[string]*storedFile
+	profile       profile
+	users         map[string]string        // email by user id
+	appleUsers    map[string]string        // user id by Apple subject
+	refreshTokens map[string]refreshGrant  // by refresh token issued at sign in or refresh
+	rotatedTokens map[string]string        // family by refresh token already exchanged
+	subscriptions map[string]*subscription // by user id
+	tiers         map[string]string        // users.tier by user id, as the webhooks set it
+	battery       int
+	usage         map[string]*dailyUsage // by date
+	rateWindows   map[string]*rateWindow // by client
+}
+
+type conversation struct {
+	id         string
+	userID     string
+	title      string
+	model      string
+	isArchived bool
+	createdAt  time.Time
+	updatedAt  time.Time
+	messages   []message
+}
+
+type message struct {
+	id        string
+	role      string
+	content   string
+	model     string
+	createdAt time.Time
+}
+
+type storedFile struct {
+	contentType string
+	data        []byte
+}
+
+type profile struct {
+	name     string
+	imageURL string
+}
+
+// refreshGrant is the user a refresh token signs in, and the first token
+// of its rotation family
+type refreshGrant struct {
+	userID string
+	family string
+}
+
+type rateWindow struct {
+	count int
+	reset time.Time
+}
+
+type dailyUsage struct {
+	batteryUsed int
+	messages    int
+	models      map[string]int
+}
+
+// New creates a mock server
+func New(opts Options) *Server {
+	if opts.ClerkToken == "" {
+		opts.ClerkToken = DefaultClerkToken
+	}
+	if opts.JWTToken == "" {
+		opts.JWTToken = DefaultJWTToken
+	}
+	if opts.JWTSecret == "" {
+		opts.JWTSecret = jwt.DevSecret
+	}
+	if opts.SecondToken == "" {
+		opts.SecondToken = DefaultSecondToken
+	}
+	if opts.RefreshToken == "" {
+		opts.RefreshToken = DefaultRefreshToken
+	}
+	if opts.AppleClientID == "" {
+		opts.AppleClientID = DefaultAppleClientID
+	}
+	if opts.StripeWebhookSecret == "" {
+		opts.StripeWebhookSecret = DefaultStripeWebhookSecret
+	}
+	if opts.RateLimit > 0 && opts.UploadRateLimit == 0 {
+		opts.UploadRateLimit = max(opts.RateLimit/5, 1)
+	}
+	if opts.RateWindow == 0 {
+		opts.RateWindow = time.Minute
+	}
+	if len(opts.OpenAPI) == 0 {
+		opts.OpenAPI = []byte(minimalOpenAPI)
+	}
+
+	s := &Server{
+		opts:          opts,
+		conversations: make(map[string]*conversation),
+		files:         make(map[string]*storedFile),
+		profile:       profile{name: "Mock User"},
+		users:         map[string]string{mockUserID: mockUserEmail, secondUserID: secondUserEmail},
+		appleUsers:    make(map[string]string),
+		refreshTokens: make(map[string]refreshGrant),
+		rotatedTokens: make(map[string]string),
+		subscriptions: make(map[string]*subscription),
+		tiers:         make(map[string]string),
+		battery:       startingBattery,
+		usage:         make(map[string]*dailyUsage),
+		rateWindows:   make(map[string]*rateWindow),
+	}
+	s.handler = s.routes()
+	return s
+}
+
+// ServeHTTP applies latency and faults, then routes the request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Logf != nil {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			status := "reset" // nothing was written before the connection closed
+			if rec.status != 0 {
+				status = fmt.Sprint(rec.status)
+			}
+			s.opts.Logf("%s %s %s %v", r.Method, r.URL.RequestURI(), status, time.Since(start).Round(time.Millisecond))
+		}()
+		w = rec
+	}
+
+	if s.opts.Latency > 0 {
+		time.Sleep(s.opts.Latency)
+	}
+
+	for _, fault := range s.opts.Faults {
+		if fault.Matches(r) && fault.Apply(w, r) {
+			return
+		}
+	}
+
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Public
+	mux.HandleFunc("GET /api/config", s.handleConfig)
+	mux.HandleFunc("GET /api/openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("GET /api/v1/docs", s.handleDocs)
+
+	// Authentication
+	mux.HandleFunc("POST /api/v1/auth/apple", s.handleAppleAuth)
+	mux.HandleFunc("POST /api/v1/auth/refresh", s.handleRefresh)
+
+	// Clerk auth
+	mux.Handle("GET /api/models", s.clerk(s.handleModels))
+	mux.Handle("POST /api/chat", s.clerk(s.handleChat))
+	mux.Handle("GET /api/conversations", s.clerk(s.handleListConversations))
+	mux.Handle("POST /api/conversations", s.clerk(s.handleCreateConversation))
+	mux.Handle("DELETE /api/conversations/{id}", s.clerk(s.handleDeleteConversation))
+	mux.Handle("GET /api/conversations/{id}/messages", s.clerk(s.handleListMessages))
+	mux.Handle("POST /api/conversations/{id}/messages", s.clerk(s.handleCreateMessage))
+	mux.Handle("POST /api/upload", s.clerk(s.handleUpload))
+	mux.Handle("GET /api/upload", s.clerk(s.handleDownload))
+	mux.Handle("GET /api/search", s.clerk(s.handleSearch))
+	mux.Handle("GET /api/battery", s.clerk(s.handleBattery))
+	mux.Handle("GET /api/user/tier", s.clerk(s.handleTier))
+	mux.Handle("POST /api/stripe/checkout", s.clerk(s.handleCheckout))
+	mux.Handle("GET /api/stripe/checkout", s.clerk(s.handleSubscriptionStatus))
+	mux.Handle("POST /api/stripe/portal", s.clerk(s.handlePortal))
+	mux.HandleFunc("POST /api/stripe/webhook", s.handleStripeWebhook)
+
+	// JWT auth (v1)
+	mux.Handle("GET /api/v1/conversations", s.v1(s.handleListConversations))
+	mux.Handle("POST /api/v1/conversations", s.v1(s.handleCreateConversation))
+	mux.Handle("GET /api/v1/conversations/{id}", s.v1(s.handleGetConversationV1))
+	mux.Handle("PATCH /api/v1/conversations/{id}", s.v1(s.handleUpdateConversationV1))
+	mux.Handle("DELETE /api/v1/conversations/{id}", s.v1(s.handleDeleteConversation))
+	mux.Handle("GET /api/v1/conversations/{id}/messages", s.v1(s.handleListMessagesV1))
+	mux.Handle("POST /api/v1/conversations/{id}/messages", s.v1(s.handleSendMessageV1))
+	mux.Handle("GET /api/v1/user/profile", s.v1(s.handleProfile))
+	mux.Handle("PATCH /api/v1/user/profile", s.v1(s.handleUpdateProfile))
+	mux.Handle("GET /api/v1/user/usage", s.v1(s.handleUsage))
+	mux.Handle("POST /api/v1/upload", s.rateLimited(s.opts.UploadRateLimit, s.jwt(s.handleUploadV1)))
+	mux.Handle("GET /api/v1/files/{key...}", s.v1(s.handleFileV1))
+	mux.Handle("DELETE /api/v1/files/{key...}", s.v1(s.handleDeleteFileV1))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "Not found")
+	})
+
+	return mux
+}
+
+// clerk requires the configured Clerk session token
+func (s *Server) clerk(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if bearerToken(r) != s.opts.ClerkToken {
+			writeError(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// v1 wraps a handler in rate limiting and JWT auth, like the v1 routes
+func (s *Server) v1(next http.HandlerFunc) http.Handler {
+	return s.rateLimited(s.opts.RateLimit, s.jwt(next))
+}
+
+// rateLimited mirrors withRateLimit: one counter per client, shared by
+// every limited route, with the limit checked against that route's max
+func (s *Server) rateLimited(limit int, next http.Handler) http.Handler {
+	if limit <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := r.Header.Get("X-Forwarded-For")
+		if client == "" {
+			client = "anonymous"
+		}
+
+		s.mu.Lock()
+		now := time.Now()
+		window, ok := s.rateWindows[client]
+		if !ok || now.After(window.reset) {
+			window = &rateWindow{reset: now.Add(s.opts.RateWindow)}
+			s.rateWindows[client] = window
+		}
+		window.count++
+		count, reset := window.count, window.reset
+		s.mu.Unlock()
+
+		remaining := max(limit-count, 0)
+		w.Header().Set("X-RateLimit-Limit", fmt.Sprint(limit))
+		w.Header().Set("X-RateLimit-Remaining", fmt.Sprint(remaining))
+		w.Header().Set("X-RateLimit-Reset", reset.UTC().Format("2006-01-02T15:04:05.000Z"))
+
+		if count > limit {
+			writeError(w, http.StatusTooManyRequests, "Too many requests")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// jwt requires one of the configured v1 access tokens, mirroring
+// withApiAuth, and attaches the user it belongs to
+func (s *Server) jwt(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var userID string
+		switch token := bearerToken(r); token {
+		case "":
+			writeError(w, http.StatusUnauthorized, "Missing or invalid authorization header")
+			return
+		case s.opts.JWTToken:
+			userID = mockUserID
+		case s.opts.SecondToken:
+			userID = secondUserID
+		default:
+			// Like verifyToken, the audience is not checked
+			claims, err := jwt.VerifyHS256(token, s.opts.JWTSecret)
+			if err != nil {
+				writeError(w, http.StatusUnauthorized, "Invalid or expired token")
+				return
+			}
+			userID, _ = claims["sub"].(string)
+			s.mu.Lock()
+			_, known := s.users[userID]
+			s.mu.Unlock()
+			if !known {
+				writeError(w, http.StatusUnauthorized, "User not found")
+				return
+			}
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), userKey{}, userID)))
+	})
+}
+
+type userKey struct{}
+
+// requestUser returns the user a request is authenticated as. Clerk
+// routes always act as the first user.
+func requestUser(r *http.Request) string {
+	if userID, ok := r.Context().Value(userKey{}).(string); ok {
+		return userID
+	}
+	return mockUserID
+}
+
+func bearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// newID returns a unique id with the given prefix. Callers hold s.mu.
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s_%06d", prefix, s.nextID)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// decodeBody decodes a JSON request body, writing a 400 on failure
+func decodeBody(w http.ResponseWriter, r *http.Request, target interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(target); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON body")
+		return false
+	}
+	return true
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// nullable returns nil for an empty string, which the server stores as NULL
+func nullable(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// statusRecorder captures the response status for request logging
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,233 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// methodOrder is the order in which operations of a path are visited
+var methodOrder = []string{"get", "post", "put", "patch", "delete", "head", "options"}
+
+// Document is the subset of an OpenAPI 3 document the validator needs
+type Document struct {
+	OpenAPI    string                 `json:"openapi"`
+	Info       map[string]interface{} `json:"info"`
+	Paths      map[string]PathItem    `json:"paths"`
+	Components Components             `json:"components"`
+	Security   []map[string][]string  `json:"security,omitempty"`
+}
+
+// Components holds the reusable definitions referenced via $ref
+type Components struct {
+	Schemas   map[string]*Schema   `json:"schemas"`
+	Responses map[string]*Response `json:"responses"`
+}
+
+// PathItem maps lower-case HTTP methods to operations
+type PathItem map[string]*Operation
+
+// UnmarshalJSON decodes only the method entries of a path item, ignoring
+// path-level fields such as summary or shared parameters
+func (p *PathItem) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	item := make(PathItem)
+	for _, method := range methodOrder {
+		if body, ok := raw[method]; ok {
+			var op Operation
+			if err := json.Unmarshal(body, &op); err != nil {
+				return fmt.Errorf("%s: %w", method, err)
+			}
+			item[method] = &op
+		}
+	}
+	*p = item
+	return nil
+}
+
+// Operation describes a single method on a path
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required,omitempty"`
+	Schema   *Schema     `json:"schema,omitempty"`
+	Example  interface{} `json:"example,omitempty"`
+}
+
+// RequestBody describes the accepted request payloads
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response describes a declared response, possibly by reference
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType pairs a schema with an optional example
+type MediaType struct {
+	Schema  *Schema     `json:"schema,omitempty"`
+	Example interface{} `json:"example,omitempty"`
+}
+
+// OperationRef identifies an operation within a document
+type OperationRef struct {
+	Method    string
+	Path      string
+	Operation *Operation
+}
+
+// String returns the operation as "METHOD /path"
+func (o OperationRef) String() string {
+	return strings.ToUpper(o.Method) + " " + o.Path
+}
+
+// Parse decodes an OpenAPI document from JSON
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q", doc.OpenAPI)
+	}
+	return &doc, nil
+}
+
+// Operations returns every operation sorted by path, then method
+func (d *Document) Operations() []OperationRef {
+	paths := make([]string, 0, len(d.Paths))
+	for path := range d.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var ops []OperationRef
+	for _, path := range paths {
+		item := d.Paths[path]
+		for _, method := range methodOrder {
+			if op, ok := item[method]; ok && op != nil {
+				ops = append(ops, OperationRef{Method: method, Path: path, Operation: op})
+			}
+		}
+	}
+	return ops
+}
+
+// SecuritySchemes returns the names of the schemes an operation accepts,
+// falling back to the document-level requirement
+func (d *Document) SecuritySchemes(op *Operation) []string {
+	requirements := op.Security
+	if requirements == nil {
+		requirements = d.Security
+	}
+
+	var names []string
+	for _, req := range requirements {
+		for name := range req {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ResolveResponse follows a response $ref into components
+func (d *Document) ResolveResponse(resp *Response) (*Response, error) {
+	seen := map[string]bool{}
+	for resp != nil && resp.Ref != "" {
+		if seen[resp.Ref] {
+			return nil, fmt.Errorf("circular reference %s", resp.Ref)
+		}
+		seen[resp.Ref] = true
+
+		name := strings.TrimPrefix(resp.Ref, "#/components/responses/")
+		if name == resp.Ref {
+			return nil, fmt.Errorf("unsupported response reference %s", resp.Ref)
+		}
+		next, ok := d.Components.Responses[name]
+		if !ok {
+			return nil, fmt.Errorf("unresolved response reference %s", resp.Ref)
+		}
+		resp = next
+	}
+	return resp, nil
+}
+
+// ResolveSchema follows a schema $ref into components
+func (d *Document) ResolveSchema(schema *Schema) (*Schema, error) {
+	seen := map[string]bool{}
+	for schema != nil && schema.Ref != "" {
+		if seen[schema.Ref] {
+			return nil, fmt.Errorf("circular reference %s", schema.Ref)
+		}
+		seen[schema.Ref] = true
+
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		if name == schema.Ref {
+			return nil, fmt.Errorf("unsupported schema reference %s", schema.Ref)
+		}
+		next, ok := d.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("unresolved schema reference %s", schema.Ref)
+		}
+		schema = next
+	}
+	return schema, nil
+}
+
+// MatchResponse finds the declared response for a status code, trying the
+// exact code, then the "2XX" style range, then "default"
+func (op *Operation) MatchResponse(status int) (*Response, string, bool) {
+	candidates := []string{
+		fmt.Sprintf("%d", status),
+		fmt.Sprintf("%dXX", status/100),
+		"default",
+	}
+	for _, key := range candidates {
+		for declared, resp := range op.Responses {
+			if strings.EqualFold(declared, key) {
+				return resp, declared, true
+			}
+		}
+	}
+	return nil, "", false
+}
+
+// SchemaFor returns the schema declared for a response content type,
+// falling back to the JSON media type. The boolean is false when the
+// response declares content but none matching contentType.
+func (r *Response) SchemaFor(contentType string) (*Schema, bool) {
+	if len(r.Content) == 0 {
+		return nil, true
+	}
+
+	mediaType := strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	if media, ok := r.Content[mediaType]; ok {
+		return media.Schema, true
+	}
+	for declared, media := range r.Content {
+		if strings.Contains(declared, "json") && strings.Contains(mediaType, "json") {
+			return media.Schema, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,237 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testDocument = `{
+	"openapi": "3.0.3",
+	"security": [{"bearerAuth": []}],
+	"paths": {
+		"/api/models": {
+			"get": {
+				"security": [{"clerkAuth": []}, {"bearerAuth": []}],
+				"responses": {
+					"200": {"content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Model"}}}}},
+					"4XX": {"$ref": "#/components/responses/Error"},
+					"default": {"description": "anything else"}
+				}
+			}
+		},
+		"/api/config": {"summary": "public", "get": {"security": [], "responses": {"200": {}}}}
+	},
+	"components": {
+		"schemas": {
+			"Model": {
+				"type": "object",
+				"required": ["id", "provider"],
+				"additionalProperties": false,
+				"properties": {
+					"id": {"type": "string", "example": "gpt-4o"},
+					"provider": {"type": "string", "enum": ["openai", "anthropic"]},
+					"contextWindow": {"type": "integer", "minimum": 1},
+					"pricing": {"$ref": "#/components/schemas/Pricing"},
+					"note": {"type": "string", "nullable": true}
+				}
+			},
+			"Pricing": {
+				"type": "object",
+				"additionalProperties": {"type": "number"}
+			},
+			"Loop": {"$ref": "#/components/schemas/Loop"}
+		},
+		"responses": {
+			"Error": {"content": {"application/json": {"schema": {"type": "object", "required": ["error"]}}}}
+		}
+	}
+}`
+
+func parseTestDocument(t *testing.T) *Document {
+	t.Helper()
+	doc, err := Parse([]byte(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestParse(t *testing.T) {
+	doc := parseTestDocument(t)
+	var ops []string
+	for _, ref := range doc.Operations() {
+		ops = append(ops, ref.String())
+	}
+	if want := []string{"GET /api/config", "GET /api/models"}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("Operations() = %q, want %q", ops, want)
+	}
+
+	if _, err := Parse([]byte(`{"swagger": "2.0"}`)); err == nil {
+		t.Error("Parse accepted a Swagger 2 document")
+	}
+}
+
+func TestSecuritySchemes(t *testing.T) {
+	doc := parseTestDocument(t)
+	if got := doc.SecuritySchemes(doc.Paths["/api/models"]["get"]); !reflect.DeepEqual(got, []string{"bearerAuth", "clerkAuth"}) {
+		t.Errorf("models schemes = %q", got)
+	}
+	if got := doc.SecuritySchemes(doc.Paths["/api/config"]["get"]); len(got) != 0 {
+		t.Errorf("an empty requirement inherited %q", got)
+	}
+	if got := doc.SecuritySchemes(&Operation{}); !reflect.DeepEqual(got, []string{"bearerAuth"}) {
+		t.Errorf("an operation without security got %q, want the document's", got)
+	}
+}
+
+func TestMatchResponse(t *testing.T) {
+	op := parseTestDocument(t).Paths["/api/models"]["get"]
+	tests := []struct {
+		status int
+		want   string
+	}{
+		{200, "200"},
+		{404, "4XX"},
+		{500, "default"},
+	}
+	for _, tt := range tests {
+		if _, code, ok := op.MatchResponse(tt.status); !ok || code != tt.want {
+			t.Errorf("MatchResponse(%d) = %q, %v, want %q", tt.status, code, ok, tt.want)
+		}
+	}
+	if _, _, ok := (&Operation{Responses: map[string]*Response{"200": {}}}).MatchResponse(201); ok {
+		t.Error("MatchResponse(201) matched 200")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	doc := parseTestDocument(t)
+	op := doc.Paths["/api/models"]["get"]
+
+	resp, err := doc.ResolveResponse(op.Responses["4XX"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, ok := resp.SchemaFor("application/problem+json")
+	if !ok || schema == nil || !reflect.DeepEqual(schema.Required, []string{"error"}) {
+		t.Errorf("error schema = %+v, %v", schema, ok)
+	}
+	if _, ok := op.Responses["200"].SchemaFor("text/html"); ok {
+		t.Error("SchemaFor(text/html) matched a JSON response")
+	}
+	if schema, ok := op.Responses["default"].SchemaFor("text/html"); !ok || schema != nil {
+		t.Error("a response without content should accept any content type")
+	}
+
+	if _, err := doc.ResolveSchema(&Schema{Ref: "#/components/schemas/Loop"}); err == nil {
+		t.Error("ResolveSchema followed a circular reference")
+	}
+	if _, err := doc.ResolveSchema(&Schema{Ref: "#/components/schemas/Missing"}); err == nil {
+		t.Error("ResolveSchema resolved a missing schema")
+	}
+	if _, err := doc.ResolveResponse(&Response{Ref: "other.json#/Error"}); err == nil {
+		t.Error("ResolveResponse followed an external reference")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	doc := parseTestDocument(t)
+	models := doc.Paths["/api/models"]["get"].Responses["200"].Content["application/json"].Schema
+
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "valid",
+			body: `[{"id": "gpt-4o", "provider": "openai", "contextWindow": 128000, "pricing": {"input": 2.5}, "note": null}]`,
+		},
+		{
+			name: "missing required field",
+			body: `[{"id": "gpt-4o"}]`,
+			want: []string{"$[0].provider: missing required field"},
+		},
+		{
+			name: "undeclared field",
+			body: `[{"id": "a", "provider": "openai", "vision": true}]`,
+			want: []string{"$[0].vision: undeclared field"},
+		},
+		{
+			name: "wrong types",
+			body: `[{"id": 1, "provider": "openai", "contextWindow": 1.5, "pricing": {"input": "free"}}]`,
+			want: []string{
+				"$[0].contextWindow: expected integer, got number",
+				"$[0].id: expected string, got number",
+				"$[0].pricing.input: expected number, got string",
+			},
+		},
+		{
+			name: "enum, minimum and null",
+			body: `[{"id": null, "provider": "mistral", "contextWindow": 0}]`,
+			want: []string{
+				"$[0].contextWindow: 0 is below minimum 1",
+				"$[0].id: expected string, got null",
+				"$[0].provider: value mistral not in enum [openai anthropic]",
+			},
+		},
+		{
+			name: "not an array",
+			body: `{"models": []}`,
+			want: []string{"$: expected array, got object"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value interface{}
+			if err := json.Unmarshal([]byte(tt.body), &value); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, mismatch := range doc.Validate(models, value) {
+				got = append(got, mismatch.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mismatches = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateAlternatives(t *testing.T) {
+	doc := &Document{}
+	str := &Schema{Type: "string"}
+	num := &Schema{Type: "number"}
+	integer := &Schema{Type: "integer"}
+
+	tests := []struct {
+		name   string
+		schema *Schema
+		value  interface{}
+		want   int
+	}{
+		{"oneOf match", &Schema{OneOf: []*Schema{str, num}}, "a", 0},
+		{"oneOf none", &Schema{OneOf: []*Schema{str, num}}, true, 1},
+		{"oneOf several", &Schema{OneOf: []*Schema{num, integer}}, 2.0, 1},
+		{"anyOf several", &Schema{AnyOf: []*Schema{num, integer}}, 2.0, 0},
+		{"allOf", &Schema{AllOf: []*Schema{num, integer}}, 2.5, 1},
+	}
+	for _, tt := range tests {
+		if got := doc.Validate(tt.schema, tt.value); len(got) != tt.want {
+			t.Errorf("%s: mismatches = %v, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExample(t *testing.T) {
+	doc := parseTestDocument(t)
+	got := doc.Example(&Schema{Ref: "#/components/schemas/Model"})
+	want := map[string]interface{}{"id": "gpt-4o", "provider": "openai"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Example(Model) = %v, want %v", got, want)
+	}
+	if got := doc.Example(&Schema{Ref: "#/components/schemas/Loop"}); got != nil {
+		t.Errorf("Example(Loop) = %v", got)
+	}
+}
@@ -0,0 +1,269 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Schema is the subset of the OpenAPI schema object used for validation
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+}
+
+// Mismatch is a single difference between a value and its schema
+type Mismatch struct {
+	Path    string
+	Message string
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s: %s", m.Path, m.Message)
+}
+
+// additional reports whether undeclared properties are allowed and the
+// schema they must satisfy, if any
+func (s *Schema) additional() (bool, *Schema) {
+	raw := strings.TrimSpace(string(s.AdditionalProperties))
+	switch raw {
+	case "", "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	var extra Schema
+	if err := json.Unmarshal(s.AdditionalProperties, &extra); err != nil {
+		return true, nil
+	}
+	return true, &extra
+}
+
+// Validate checks a decoded JSON value against schema and returns every
+// mismatch found, with paths rooted at "$"
+func (d *Document) Validate(schema *Schema, value interface{}) []Mismatch {
+	var mismatches []Mismatch
+	d.validate(schema, value, "$", &mismatches)
+	return mismatches
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, path string, out *[]Mismatch) {
+	schema, err := d.ResolveSchema(schema)
+	if err != nil {
+		*out = append(*out, Mismatch{Path: path, Message: err.Error()})
+		return
+	}
+	if schema == nil {
+		return
+	}
+
+	for _, sub := range schema.AllOf {
+		d.validate(sub, value, path, out)
+	}
+	if len(schema.OneOf) > 0 {
+		d.validateAlternatives(schema.OneOf, value, path, "oneOf", true, out)
+	}
+	if len(schema.AnyOf) > 0 {
+		d.validateAlternatives(schema.AnyOf, value, path, "anyOf", false, out)
+	}
+
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			*out = append(*out, Mismatch{Path: path, Message: fmt.Sprintf("expected %s, got null", schema.Type)})
+		}
+		return
+	}
+
+	if schema.Type != "" && !matchesType(schema.Type, value) {
+		*out = append(*out, Mismatch{Path: path, Message: fmt.Sprintf("expected %s, got %s", schema.Type, jsonType(value))})
+		return
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		*out = append(*out, Mismatch{Path: path, Message: fmt.Sprintf("value %v not in enum %v", value, schema.Enum)})
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		d.validateObject(schema, v, path, out)
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range v {
+				d.validate(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), out)
+			}
+		}
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			*out = append(*out, Mismatch{Path: path, Message: fmt.Sprintf("%v is below minimum %v", v, *schema.Minimum)})
+		}
+		if schema.Maximum != nil && v > *schema.Maximum {
+			*out = append(*out, Mismatch{Path: path, Message: fmt.Sprintf("%v is above maximum %v", v, *schema.Maximum)})
+		}
+	}
+}
+
+func (d *Document) validateObject(schema *Schema, obj map[string]interface{}, path string, out *[]Mismatch) {
+	for _, name := range schema.Required {
+		if _, ok := obj[name]; !ok {
+			*out = append(*out, Mismatch{Path: path + "." + name, Message: "missing required field"})
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	allowed, extra := schema.additional()
+	for _, key := range keys {
+		child := path + "." + key
+		if prop, ok := schema.Properties[key]; ok {
+			d.validate(prop, obj[key], child, out)
+			continue
+		}
+		if !allowed {
+			*out = append(*out, Mismatch{Path: child, Message: "undeclared field"})
+		} else if extra != nil {
+			d.validate(extra, obj[key], child, out)
+		}
+	}
+}
+
+func (d *Document) validateAlternatives(options []*Schema, value interface{}, path, keyword string, exclusive bool, out *[]Mismatch) {
+	matches := 0
+	for _, option := range options {
+		if len(d.Validate(option, value)) == 0 {
+			matches++
+		}
+	}
+
+	switch {
+	case matches == 0:
+		*out = append(*out, Mismatch{Path: path, Message: fmt.Sprintf("value matches none of the %s schemas", keyword)})
+	case exclusive && matches > 1:
+		*out = append(*out, Mismatch{Path: path, Message: fmt.Sprintf("value matches %d %s schemas, expected exactly one", matches, keyword)})
+	}
+}
+
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return jsonType(value) == schemaType
+	}
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, candidate := range enum {
+		if reflect.DeepEqual(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Example builds a sample value for schema, preferring declared examples
+// and defaults and otherwise filling in required fields only
+func (d *Document) Example(schema *Schema) interface{} {
+	return d.example(schema, 0)
+}
+
+func (d *Document) example(schema *Schema, depth int) interface{} {
+	schema, err := d.ResolveSchema(schema)
+	if err != nil || schema == nil || depth > 8 {
+		return nil
+	}
+
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	if len(schema.AllOf) > 0 {
+		merged := map[string]interface{}{}
+		for _, sub := range schema.AllOf {
+			if obj, ok := d.example(sub, depth+1).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	if len(schema.OneOf) > 0 {
+		return d.example(schema.OneOf[0], depth+1)
+	}
+	if len(schema.AnyOf) > 0 {
+		return d.example(schema.AnyOf[0], depth+1)
+	}
+
+	switch schema.Type {
+	case "object":
+		obj := map[string]interface{}{}
+		for _, name := range schema.Required {
+			obj[name] = d.example(schema.Properties[name], depth+1)
+		}
+		return obj
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{d.example(schema.Items, depth+1)}
+	case "integer", "number":
+		if schema.Minimum != nil {
+			return *schema.Minimum
+		}
+		return 1
+	case "boolean":
+		return false
+	case "string":
+		return "test"
+	}
+	return nil
+}
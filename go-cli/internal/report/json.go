@@ -0,0 +1,98 @@
+package report
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type jsonReport struct {
+	BaseURL    string       `json:"baseUrl"`
+	StartedAt  time.Time    `json:"startedAt"`
+	DurationMs int64        `json:"durationMs"`
+	Summary    jsonSummary  `json:"summary"`
+	Results    []jsonResult `json:"results"`
+}
+
+type jsonSummary struct {
+	Total        int `json:"total"`
+	Passed       int `json:"passed"`
+	Failed       int `json:"failed"`
+	AuthRequired int `json:"authRequired"`
+	KnownGaps    int `json:"knownGaps"`
+}
+
+type jsonResult struct {
+	Name              string      `json:"name"`
+	Category          string      `json:"category,omitempty"`
+	Operation         string      `json:"operation,omitempty"`
+	Success           bool        `json:"success"`
+	StatusCode        int         `json:"statusCode"`
+	DurationMs        float64     `json:"durationMs"`
+	Error             string      `json:"error,omitempty"`
+	KnownGap          string      `json:"knownGap,omitempty"`
+	AssertionFailures []string    `json:"assertionFailures,omitempty"`
+	Retries           []jsonRetry `json:"retries,omitempty"`
+	Response          string      `json:"response,omitempty"`
+}
+
+type jsonRetry struct {
+	Attempt    int    `json:"attempt"`
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DelayMs    int64  `json:"delayMs"`
+}
+
+func renderJSON(run Run) ([]byte, error) {
+	out := jsonReport{
+		BaseURL:    run.BaseURL,
+		StartedAt:  run.StartedAt.UTC(),
+		DurationMs: run.Duration.Milliseconds(),
+		Results:    make([]jsonResult, 0, len(run.Results)),
+	}
+
+	for _, result := range run.Results {
+		out.Summary.Total++
+		switch {
+		case result.Success:
+			out.Summary.Passed++
+		case result.KnownGap != "":
+			out.Summary.Failed++
+			out.Summary.KnownGaps++
+		case isAuthFailure(result):
+			out.Summary.Failed++
+			out.Summary.AuthRequired++
+		default:
+			out.Summary.Failed++
+		}
+
+		var retries []jsonRetry
+		for _, retry := range result.Retries {
+			retries = append(retries, jsonRetry{
+				Attempt:    retry.Attempt,
+				StatusCode: retry.StatusCode,
+				Error:      retry.Error,
+				DelayMs:    retry.Delay.Milliseconds(),
+			})
+		}
+
+		out.Results = append(out.Results, jsonResult{
+			Name:              result.Name,
+			Category:          result.Category,
+			Operation:         result.Operation,
+			Success:           result.Success,
+			StatusCode:        result.StatusCode,
+			DurationMs:        float64(result.Duration.Microseconds()) / 1000,
+			Error:             result.Error,
+			KnownGap:          result.KnownGap,
+			AssertionFailures: result.AssertionFailures,
+			Retries:           retries,
+			Response:          truncatedResponse(result.Response),
+		})
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
@@ -0,0 +1,126 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// renderJUnit groups results into one <testsuite> per category. Transport
+// errors become <error>, auth rejections without credentials <skipped>,
+// and everything else that did not pass <failure>.
+func renderJUnit(run Run) ([]byte, error) {
+	root := junitTestSuites{
+		Name: "omnichat-validator",
+		Time: seconds(run.Duration.Seconds()),
+	}
+
+	index := make(map[string]int)
+	timestamp := run.StartedAt.UTC().Format("2006-01-02T15:04:05")
+	totals := make(map[string]float64)
+
+	for _, result := range run.Results {
+		category := result.Category
+		if category == "" {
+			category = "Other"
+		}
+
+		i, ok := index[category]
+		if !ok {
+			i = len(root.Suites)
+			index[category] = i
+			root.Suites = append(root.Suites, junitTestSuite{Name: category, Timestamp: timestamp})
+		}
+		suite := &root.Suites[i]
+
+		tc := junitTestCase{
+			Name:      result.Name,
+			ClassName: "omnichat." + strings.ReplaceAll(category, " ", ""),
+			Time:      seconds(result.Duration.Seconds()),
+			SystemOut: truncatedResponse(result.Response),
+		}
+
+		details := result.Error
+		if len(result.AssertionFailures) > 0 {
+			details += "\n" + strings.Join(result.AssertionFailures, "\n")
+		}
+		message := strings.SplitN(result.Error, "\n", 2)[0]
+
+		switch {
+		case result.Success:
+		case result.KnownGap != "":
+			tc.Skipped = &junitMessage{Message: "known gap: " + result.KnownGap, Body: details}
+			suite.Skipped++
+		case result.StatusCode == 0:
+			tc.Error = &junitMessage{Message: message, Type: "RequestError", Body: details}
+			suite.Errors++
+		case isAuthFailure(result):
+			tc.Skipped = &junitMessage{Message: fmt.Sprintf("HTTP %d: credentials required", result.StatusCode)}
+			suite.Skipped++
+		default:
+			tc.Failure = &junitMessage{Message: message, Type: fmt.Sprintf("HTTP%d", result.StatusCode), Body: details}
+			suite.Failures++
+		}
+
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+		totals[category] += result.Duration.Seconds()
+	}
+
+	for i := range root.Suites {
+		suite := &root.Suites[i]
+		suite.Time = seconds(totals[suite.Name])
+		root.Tests += suite.Tests
+		root.Failures += suite.Failures
+		root.Errors += suite.Errors
+		root.Skipped += suite.Skipped
+	}
+
+	data, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+func seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
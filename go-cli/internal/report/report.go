@@ -0,0 +1,110 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/types"
+)
+
+// Report formats supported by --report
+const (
+	FormatJUnit = "junit"
+	FormatJSON  = "json"
+)
+
+// maxResponseLength caps the serialized response stored per test
+const maxResponseLength = 2048
+
+// Target is a single --report destination, e.g. "junit=results.xml"
+type Target struct {
+	Format string
+	Path   string
+}
+
+// Run describes the validator run a report belongs to
+type Run struct {
+	BaseURL   string
+	StartedAt time.Time
+	Duration  time.Duration
+	Results   []types.TestResult
+}
+
+// ParseTarget parses a "format=path" report flag value
+func ParseTarget(value string) (Target, error) {
+	format, path, ok := strings.Cut(value, "=")
+	if !ok || path == "" {
+		return Target{}, fmt.Errorf("invalid report %q, expected format=path", value)
+	}
+
+	format = strings.ToLower(strings.TrimSpace(format))
+	switch format {
+	case FormatJUnit, FormatJSON:
+		return Target{Format: format, Path: path}, nil
+	}
+	return Target{}, fmt.Errorf("unknown report format %q (supported: junit, json)", format)
+}
+
+// Write renders run in the target's format to the target's path
+func Write(target Target, run Run) error {
+	var data []byte
+	var err error
+
+	switch target.Format {
+	case FormatJUnit:
+		data, err = renderJUnit(run)
+	case FormatJSON:
+		data, err = renderJSON(run)
+	default:
+		err = fmt.Errorf("unknown report format %q", target.Format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(target.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(target.Path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s report: %w", target.Format, err)
+	}
+	return nil
+}
+
+// isAuthFailure mirrors the validator's treatment of 401/403 as missing
+// credentials rather than test failures
+func isAuthFailure(result types.TestResult) bool {
+	return !result.Success && (result.StatusCode == 401 || result.StatusCode == 403)
+}
+
+// truncatedResponse serializes a response body and caps its length
+func truncatedResponse(response interface{}) string {
+	if response == nil {
+		return ""
+	}
+
+	var text string
+	if s, ok := response.(string); ok {
+		text = s
+	} else {
+		data, err := json.Marshal(response)
+		if err != nil {
+			text = fmt.Sprintf("%v", response)
+		} else {
+			text = string(data)
+		}
+	}
+
+	if len(text) > maxResponseLength {
+		// Drop any rune split by the cut so the output stays valid UTF-8
+		return strings.ToValidUTF8(text[:maxResponseLength], "") + "... (truncated)"
+	}
+	return text
+}
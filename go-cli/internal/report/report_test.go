@@ -0,0 +1,153 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/omnichat/validator/internal/types"
+)
+
+// testRun has one result of every kind a report distinguishes
+var testRun = Run{
+	BaseURL:   "http://localhost:3000",
+	StartedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	Duration:  1500 * time.Millisecond,
+	Results: []types.TestResult{
+		{Name: "GET /api/config", Category: "Public", Success: true, StatusCode: 200, Duration: 100 * time.Millisecond, Response: map[string]interface{}{"appUrl": "x"}},
+		{Name: "GET /api/v1/docs", Category: "Public", StatusCode: 500, Duration: 200 * time.Millisecond, Error: "HTTP 500\nsecond line", AssertionFailures: []string{"$.error: missing"},
+			Retries: []types.Retry{{Attempt: 1, StatusCode: 503, Delay: 250 * time.Millisecond}}},
+		{Name: "GET /api/models", Category: "Models", StatusCode: 401, Error: "HTTP 401"},
+		{Name: "GET /api/chat", StatusCode: 0, Error: "connection refused"},
+		{Name: "GET /api/v1/conversations (wrong audience)", Category: "Token Validation", StatusCode: 200, Error: "HTTP 200: the wrong audience was accepted", KnownGap: "no audience check"},
+	},
+}
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Target
+		err   string
+	}{
+		{value: "junit=reports/out.xml", want: Target{Format: FormatJUnit, Path: "reports/out.xml"}},
+		{value: " JSON =out=1.json", want: Target{Format: FormatJSON, Path: "out=1.json"}},
+		{value: "junit", err: "expected format=path"},
+		{value: "json=", err: "expected format=path"},
+		{value: "html=out.html", err: `unknown report format "html"`},
+	}
+	for _, tt := range tests {
+		got, err := ParseTarget(tt.value)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("ParseTarget(%q) error = %v, want %q", tt.value, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseTarget(%q) = %+v, %v, want %+v", tt.value, got, err, tt.want)
+		}
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	data, err := renderJUnit(testRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var root junitTestSuites
+	if err := xml.Unmarshal(data, &root); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, data)
+	}
+
+	if root.Tests != 5 || root.Failures != 1 || root.Errors != 1 || root.Skipped != 2 {
+		t.Errorf("totals: %d tests, %d failures, %d errors, %d skipped", root.Tests, root.Failures, root.Errors, root.Skipped)
+	}
+	var names []string
+	for _, suite := range root.Suites {
+		names = append(names, suite.Name)
+	}
+	if got := strings.Join(names, ","); got != "Public,Models,Other,Token Validation" {
+		t.Errorf("suites = %s", got)
+	}
+
+	public := root.Suites[0]
+	if public.Tests != 2 || public.Time != "0.300" || public.Timestamp != "2026-01-02T03:04:05" {
+		t.Errorf("Public suite: %d tests, time %s, timestamp %s", public.Tests, public.Time, public.Timestamp)
+	}
+	if passed := public.Cases[0]; passed.Failure != nil || passed.ClassName != "omnichat.Public" || passed.SystemOut != `{"appUrl":"x"}` {
+		t.Errorf("passed case = %+v", passed)
+	}
+	failure := public.Cases[1].Failure
+	if failure == nil || failure.Message != "HTTP 500" || failure.Type != "HTTP500" || !strings.Contains(failure.Body, "$.error: missing") {
+		t.Errorf("failure = %+v", failure)
+	}
+	if skipped := root.Suites[1].Cases[0].Skipped; skipped == nil || skipped.Message != "HTTP 401: credentials required" {
+		t.Errorf("auth case skipped = %+v", skipped)
+	}
+	if other := root.Suites[2].Cases[0]; other.Error == nil || other.Error.Type != "RequestError" || other.ClassName != "omnichat.Other" {
+		t.Errorf("transport error case = %+v", other)
+	}
+	if known := root.Suites[3].Cases[0]; known.Skipped == nil || known.Skipped.Message != "known gap: no audience check" || known.Failure != nil {
+		t.Errorf("known gap case = %+v", known)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := renderJSON(testRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got jsonReport
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+
+	want := jsonSummary{Total: 5, Passed: 1, Failed: 4, AuthRequired: 1, KnownGaps: 1}
+	if got.Summary != want {
+		t.Errorf("summary = %+v, want %+v", got.Summary, want)
+	}
+	if got.BaseURL != testRun.BaseURL || got.DurationMs != 1500 || !got.StartedAt.Equal(testRun.StartedAt) {
+		t.Errorf("run = %s, %dms, %v", got.BaseURL, got.DurationMs, got.StartedAt)
+	}
+	docs := got.Results[1]
+	if docs.DurationMs != 200 || len(docs.Retries) != 1 || docs.Retries[0].DelayMs != 250 || docs.Retries[0].StatusCode != 503 {
+		t.Errorf("docs result = %+v", docs)
+	}
+	if known := got.Results[4]; known.KnownGap != "no audience check" {
+		t.Errorf("known gap result = %+v", known)
+	}
+}
+
+func TestTruncatedResponse(t *testing.T) {
+	if got := truncatedResponse(nil); got != "" {
+		t.Errorf("nil response = %q", got)
+	}
+	long := strings.Repeat("a", maxResponseLength-1) + "é"
+	got := truncatedResponse(long)
+	if !utf8.ValidString(got) || !strings.HasSuffix(got, "... (truncated)") || len(got) != maxResponseLength-1+len("... (truncated)") {
+		t.Errorf("truncated to %d bytes, valid UTF-8 %v", len(got), utf8.ValidString(got))
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	for _, target := range []Target{
+		{Format: FormatJUnit, Path: filepath.Join(dir, "reports", "junit.xml")},
+		{Format: FormatJSON, Path: filepath.Join(dir, "reports", "run.json")},
+	} {
+		if err := Write(target, testRun); err != nil {
+			t.Fatalf("Write %s: %v", target.Format, err)
+		}
+		if _, err := os.Stat(target.Path); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := Write(Target{Format: "html", Path: filepath.Join(dir, "out.html")}, testRun); err == nil {
+		t.Error("Write accepted an unknown format")
+	}
+}
@@ -0,0 +1,150 @@
+package spec
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Assertion checks a single value in a decoded JSON response. Path is a
+// dot-separated lookup where numeric segments index into arrays; an empty
+// path refers to the whole response.
+type Assertion struct {
+	Path     string      `json:"path" yaml:"path"`
+	Exists   *bool       `json:"exists,omitempty" yaml:"exists,omitempty"`
+	Type     string      `json:"type,omitempty" yaml:"type,omitempty"`
+	Equals   interface{} `json:"equals,omitempty" yaml:"equals,omitempty"`
+	NotEmpty bool        `json:"notEmpty,omitempty" yaml:"notEmpty,omitempty"`
+}
+
+// Check evaluates the assertion against response and returns a
+// description of the failure, or nil if it holds
+func (a Assertion) Check(response interface{}) error {
+	value, found := Lookup(response, a.Path)
+
+	shouldExist := a.Exists == nil || *a.Exists
+	if !shouldExist {
+		if found {
+			return fmt.Errorf("%s: expected field to be absent", a.label())
+		}
+		return nil
+	}
+	if !found {
+		return fmt.Errorf("%s: missing field", a.label())
+	}
+
+	if a.Type != "" {
+		if actual := TypeOf(value); actual != a.Type {
+			return fmt.Errorf("%s: expected type %s, got %s", a.label(), a.Type, actual)
+		}
+	}
+
+	if a.Equals != nil && !equalJSON(a.Equals, value) {
+		return fmt.Errorf("%s: expected %v, got %v", a.label(), a.Equals, value)
+	}
+
+	if a.NotEmpty && isEmpty(value) {
+		return fmt.Errorf("%s: expected non-empty value", a.label())
+	}
+
+	return nil
+}
+
+func (a Assertion) label() string {
+	if a.Path == "" {
+		return "$"
+	}
+	return a.Path
+}
+
+// Lookup walks a decoded JSON value along a dot-separated path
+func Lookup(value interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return value, true
+	}
+
+	current := value
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			idx, err := strconv.Atoi(segment)
+			if err != nil || idx < 0 || idx >= len(node) {
+				return nil, false
+			}
+			current = node[idx]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// TypeOf returns the JSON type name of a decoded value
+func TypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64, int:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// equalJSON compares an expected value from a spec file with a decoded
+// JSON value, treating all numeric types as float64
+func equalJSON(expected, actual interface{}) bool {
+	return reflect.DeepEqual(normalize(expected), normalize(actual))
+}
+
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = normalize(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = normalize(item)
+		}
+		return out
+	}
+	return value
+}
@@ -0,0 +1,355 @@
+package spec
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Auth kinds a test case can request
+const (
+	AuthNone  = "none"
+	AuthClerk = "clerk"
+	AuthJWT   = "jwt"
+)
+
+//go:embed default.yaml
+var defaultSpec []byte
+
+// File is the top-level structure of a spec file
+type File struct {
+	Vars      map[string]string `json:"vars,omitempty" yaml:"vars,omitempty"`
+	Suites    []Suite           `json:"suites" yaml:"suites"`
+	Scenarios []Scenario        `json:"scenarios,omitempty" yaml:"scenarios,omitempty"`
+}
+
+// Suite is a titled section of the run, e.g. "Clerk Auth Endpoints"
+type Suite struct {
+	Name   string  `json:"name" yaml:"name"`
+	Emoji  string  `json:"emoji,omitempty" yaml:"emoji,omitempty"`
+	Auth   string  `json:"auth,omitempty" yaml:"auth,omitempty"`
+	Groups []Group `json:"groups" yaml:"groups"`
+}
+
+// Group is a subheading within a suite, e.g. "Conversations V1"
+type Group struct {
+	Name  string     `json:"name,omitempty" yaml:"name,omitempty"`
+	Emoji string     `json:"emoji,omitempty" yaml:"emoji,omitempty"`
+	Tests []TestCase `json:"tests" yaml:"tests"`
+}
+
+// Scenario is an ordered chain of steps sharing captured variables. The
+// first failing step stops the chain; Cleanup steps always run, skipping
+// any that reference a variable that was never captured.
+type Scenario struct {
+	Name    string     `json:"name" yaml:"name"`
+	Emoji   string     `json:"emoji,omitempty" yaml:"emoji,omitempty"`
+	Auth    string     `json:"auth,omitempty" yaml:"auth,omitempty"`
+	Steps   []TestCase `json:"steps" yaml:"steps"`
+	Cleanup []TestCase `json:"cleanup,omitempty" yaml:"cleanup,omitempty"`
+}
+
+// TestCase describes a single endpoint check
+type TestCase struct {
+	Name      string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Method    string            `json:"method" yaml:"method"`
+	Path      string            `json:"path" yaml:"path"`
+	Params    map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+	Auth      string            `json:"auth,omitempty" yaml:"auth,omitempty"`
+	Body      interface{}       `json:"body,omitempty" yaml:"body,omitempty"`
+	Multipart *Multipart        `json:"multipart,omitempty" yaml:"multipart,omitempty"`
+	Expect    Expectation       `json:"expect,omitempty" yaml:"expect,omitempty"`
+	Hints     map[int]string    `json:"hints,omitempty" yaml:"hints,omitempty"`
+	Capture   map[string]string `json:"capture,omitempty" yaml:"capture,omitempty"`
+}
+
+// Multipart describes a multipart/form-data request body
+type Multipart struct {
+	Fields map[string]string `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Files  []FilePart        `json:"files,omitempty" yaml:"files,omitempty"`
+}
+
+// FilePart is a single file in a multipart body. Either Content or
+// Source (a path on disk) provides the data.
+type FilePart struct {
+	Field       string `json:"field" yaml:"field"`
+	FileName    string `json:"fileName" yaml:"fileName"`
+	ContentType string `json:"contentType,omitempty" yaml:"contentType,omitempty"`
+	Content     string `json:"content,omitempty" yaml:"content,omitempty"`
+	Source      string `json:"source,omitempty" yaml:"source,omitempty"`
+}
+
+// Expectation holds the accepted status codes and response assertions.
+// An empty Status list accepts any 2xx response.
+type Expectation struct {
+	Status     []int       `json:"status,omitempty" yaml:"status,omitempty"`
+	Assertions []Assertion `json:"assertions,omitempty" yaml:"assertions,omitempty"`
+}
+
+// Default returns the built-in spec covering the standard endpoint checks
+func Default() (*File, error) {
+	return Parse(defaultSpec, "default.yaml")
+}
+
+// Load reads a spec file from disk. Files ending in .json are decoded as
+// JSON, everything else as YAML.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec %s: %w", path, err)
+	}
+
+	f, err := Parse(data, path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve file sources relative to the spec file
+	dir := filepath.Dir(path)
+	f.eachTest(func(tc *TestCase) {
+		if tc.Multipart == nil {
+			return
+		}
+		for fi := range tc.Multipart.Files {
+			if src := tc.Multipart.Files[fi].Source; src != "" && !filepath.IsAbs(src) {
+				tc.Multipart.Files[fi].Source = filepath.Join(dir, src)
+			}
+		}
+	})
+
+	return f, nil
+}
+
+// eachTest calls fn for every test case in suites and scenarios
+func (f *File) eachTest(fn func(tc *TestCase)) {
+	for si := range f.Suites {
+		for gi := range f.Suites[si].Groups {
+			for ti := range f.Suites[si].Groups[gi].Tests {
+				fn(&f.Suites[si].Groups[gi].Tests[ti])
+			}
+		}
+	}
+	for si := range f.Scenarios {
+		for ti := range f.Scenarios[si].Steps {
+			fn(&f.Scenarios[si].Steps[ti])
+		}
+		for ti := range f.Scenarios[si].Cleanup {
+			fn(&f.Scenarios[si].Cleanup[ti])
+		}
+	}
+}
+
+// Parse decodes spec data; name is used for format detection and errors
+func Parse(data []byte, name string) (*File, error) {
+	var f File
+	var err error
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		err = json.Unmarshal(data, &f)
+	} else {
+		err = yaml.Unmarshal(data, &f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse spec %s: %w", name, err)
+	}
+
+	if err := f.validate(); err != nil {
+		return nil, fmt.Errorf("invalid spec %s: %w", name, err)
+	}
+
+	return &f, nil
+}
+
+func (f *File) validate() error {
+	for _, suite := range f.Suites {
+		if err := validAuth(suite.Auth); err != nil {
+			return fmt.Errorf("suite %q: %w", suite.Name, err)
+		}
+		for _, group := range suite.Groups {
+			for i, tc := range group.Tests {
+				if err := tc.validate(); err != nil {
+					return fmt.Errorf("suite %q, test %d: %w", suite.Name, i, err)
+				}
+			}
+		}
+	}
+	for _, scenario := range f.Scenarios {
+		if scenario.Name == "" {
+			return fmt.Errorf("scenario name is required")
+		}
+		if len(scenario.Steps) == 0 {
+			return fmt.Errorf("scenario %q: at least one step is required", scenario.Name)
+		}
+		if err := validAuth(scenario.Auth); err != nil {
+			return fmt.Errorf("scenario %q: %w", scenario.Name, err)
+		}
+		for i, tc := range append(append([]TestCase{}, scenario.Steps...), scenario.Cleanup...) {
+			if err := tc.validate(); err != nil {
+				return fmt.Errorf("scenario %q, step %d: %w", scenario.Name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (tc TestCase) validate() error {
+	if tc.Method == "" || tc.Path == "" {
+		return fmt.Errorf("method and path are required")
+	}
+	if tc.Body != nil && tc.Multipart != nil {
+		return fmt.Errorf("test %q: body and multipart are mutually exclusive", tc.DisplayName())
+	}
+	if err := validAuth(tc.Auth); err != nil {
+		return fmt.Errorf("test %q: %w", tc.DisplayName(), err)
+	}
+	return nil
+}
+
+func validAuth(auth string) error {
+	switch auth {
+	case "", AuthNone, AuthClerk, AuthJWT:
+		return nil
+	}
+	return fmt.Errorf("unknown auth kind %q", auth)
+}
+
+// Merge appends the suites and scenarios of other to f; other's vars take
+// precedence
+func (f *File) Merge(other *File) {
+	if len(other.Vars) > 0 && f.Vars == nil {
+		f.Vars = make(map[string]string)
+	}
+	for k, val := range other.Vars {
+		f.Vars[k] = val
+	}
+	f.Suites = append(f.Suites, other.Suites...)
+	f.Scenarios = append(f.Scenarios, other.Scenarios...)
+}
+
+// Endpoints returns how many distinct "METHOD path" operations the suites
+// and scenarios call; paths differing only in their query string count once
+func (f *File) Endpoints() int {
+	seen := make(map[string]bool)
+	f.eachTest(func(tc *TestCase) {
+		path, _, _ := strings.Cut(tc.Path, "?")
+		seen[strings.ToUpper(tc.Method)+" "+path] = true
+	})
+	return len(seen)
+}
+
+// DisplayName returns the test name, defaulting to "METHOD path"
+func (tc TestCase) DisplayName() string {
+	if tc.Name != "" {
+		return tc.Name
+	}
+	return strings.ToUpper(tc.Method) + " " + tc.Path
+}
+
+// AuthKind returns the effective auth kind for the test, falling back to
+// the auth of its enclosing suite or scenario
+func (tc TestCase) AuthKind(inherited string) string {
+	if tc.Auth != "" {
+		return tc.Auth
+	}
+	if inherited != "" {
+		return inherited
+	}
+	return AuthNone
+}
+
+// Captured copies the values named by tc.Capture from response into vars.
+// Strings are stored as-is and other scalars in their JSON form; missing or
+// non-scalar values are reported and leave vars untouched.
+func (tc TestCase) Captured(response interface{}, vars map[string]string) []string {
+	names := make([]string, 0, len(tc.Capture))
+	for name := range tc.Capture {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failures []string
+	for _, name := range names {
+		path := tc.Capture[name]
+		value, found := Lookup(response, path)
+		if !found {
+			failures = append(failures, fmt.Sprintf("%s: missing field to capture as %s", path, name))
+			continue
+		}
+		switch val := value.(type) {
+		case string:
+			vars[name] = val
+		case map[string]interface{}, []interface{}, nil:
+			failures = append(failures, fmt.Sprintf("%s: cannot capture %s as %s", path, TypeOf(value), name))
+		default:
+			data, _ := json.Marshal(val)
+			vars[name] = string(data)
+		}
+	}
+	return failures
+}
+
+// Accepts reports whether the status code satisfies the expectation
+func (e Expectation) Accepts(status int) bool {
+	if len(e.Status) == 0 {
+		return status >= 200 && status < 300
+	}
+	for _, s := range e.Status {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+var placeholder = regexp.MustCompile(`\{([A-Za-z0-9_.]+)\}`)
+
+// Expand substitutes {name} placeholders in template. Params take
+// precedence over vars; unknown placeholders are left untouched.
+func Expand(template string, params, vars map[string]string) string {
+	return placeholder.ReplaceAllStringFunc(template, func(m string) string {
+		key := m[1 : len(m)-1]
+		if val, ok := params[key]; ok {
+			return val
+		}
+		if val, ok := vars[key]; ok {
+			return val
+		}
+		return m
+	})
+}
+
+// Unresolved returns the placeholders left in s after expansion
+func Unresolved(s string) []string {
+	var names []string
+	for _, m := range placeholder.FindAllStringSubmatch(s, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// ExpandValue applies Expand to every string inside a decoded body
+func ExpandValue(value interface{}, params, vars map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return Expand(v, params, vars)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = ExpandValue(item, params, vars)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = ExpandValue(item, params, vars)
+		}
+		return out
+	}
+	return value
+}
@@ -0,0 +1,50 @@
+package spec
+
+import "testing"
+
+func TestEndpoints(t *testing.T) {
+	f, err := Parse([]byte(`
+suites:
+  - name: Files
+    groups:
+      - name: Upload
+        tests:
+          - method: get
+            path: /api/upload
+          - method: GET
+            path: /api/upload?key=test
+          - method: POST
+            path: /api/upload
+scenarios:
+  - name: Search
+    steps:
+      - method: GET
+        path: /api/search?q=a
+      - method: GET
+        path: /api/search?q=b
+    cleanup:
+      - method: DELETE
+        path: /api/upload?key=test
+`), "endpoints.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// GET and POST /api/upload, GET /api/search, DELETE /api/upload
+	if got := f.Endpoints(); got != 4 {
+		t.Errorf("Endpoints() = %d, want 4", got)
+	}
+}
+
+func TestDefaultSpecEndpoints(t *testing.T) {
+	f, err := Default()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[string]bool)
+	f.eachTest(func(tc *TestCase) {
+		seen[tc.DisplayName()] = true
+	})
+	if got := f.Endpoints(); got == 0 || got > len(seen) {
+		t.Errorf("Endpoints() = %d for %d distinct tests", got, len(seen))
+	}
+}
@@ -0,0 +1,270 @@
+// Package stripe builds and signs Stripe webhook events for billing tests.
+// The payloads carry the fields the API's webhook handler reads.
+package stripe
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event types the API's webhook handler acts on
+const (
+	CheckoutSessionCompleted = "checkout.session.completed"
+	SubscriptionUpdated      = "customer.subscription.updated"
+	SubscriptionDeleted      = "customer.subscription.deleted"
+	InvoicePaymentFailed     = "invoice.payment_failed"
+)
+
+// EventTypes are the supported event types, in the order of a
+// subscription's life
+var EventTypes = []string{CheckoutSessionCompleted, SubscriptionUpdated, InvoicePaymentFailed, SubscriptionDeleted}
+
+// RetrievesSubscription reports whether the API's webhook handler looks
+// the subscription of an event up in Stripe, which fails for a made-up
+// subscription
+func RetrievesSubscription(eventType string) bool {
+	return eventType == CheckoutSessionCompleted || eventType == SubscriptionUpdated
+}
+
+// apiVersion is the Stripe API version events claim to be rendered in
+const apiVersion = "2024-06-20"
+
+// DefaultTolerance is how old a signature timestamp may be, as in Stripe's
+// libraries
+const DefaultTolerance = 5 * time.Minute
+
+// Plan is a subscription plan as the webhook handler configures it
+type Plan struct {
+	Name         string
+	BatteryUnits int // credited when the subscription starts
+	DailyBattery int
+}
+
+// Plans mirrors the default plans of the API's webhook handler
+var Plans = map[string]Plan{
+	"starter":  {Name: "Starter", BatteryUnits: 6000, DailyBattery: 200},
+	"daily":    {Name: "Daily", BatteryUnits: 18000, DailyBattery: 600},
+	"power":    {Name: "Power", BatteryUnits: 45000, DailyBattery: 1500},
+	"ultimate": {Name: "Ultimate", BatteryUnits: 150000, DailyBattery: 5000},
+}
+
+// BatteryPacks are the battery top-up sizes the API sells
+var BatteryPacks = []int{1000, 5000, 15000, 50000}
+
+// Event is a Stripe webhook event
+type Event struct {
+	ID         string    `json:"id"`
+	Object     string    `json:"object"`
+	APIVersion string    `json:"api_version"`
+	Created    int64     `json:"created"`
+	Type       string    `json:"type"`
+	Livemode   bool      `json:"livemode"`
+	Data       EventData `json:"data"`
+}
+
+// EventData holds the object an event is about
+type EventData struct {
+	Object map[string]interface{} `json:"object"`
+}
+
+// Customer names the Stripe objects a series of events refers to
+type Customer struct {
+	UserID         string // the API user, sent as metadata.userId
+	CustomerID     string
+	SessionID      string // checkout session of the purchase, a fresh id if empty
+	SubscriptionID string
+	PlanID         string
+	PriceID        string
+}
+
+// NewCustomer returns a customer of a plan with fresh Stripe ids
+func NewCustomer(userID, planID string) Customer {
+	return Customer{
+		UserID:         userID,
+		CustomerID:     NewID("cus"),
+		SubscriptionID: NewID("sub"),
+		PlanID:         planID,
+		PriceID:        "price_" + planID + "_monthly",
+	}
+}
+
+// NewBatteryPurchaseEvent builds the checkout.session.completed event of a
+// one-off battery top-up
+func NewBatteryPurchaseEvent(c Customer, units int) Event {
+	session := c.checkoutSession()
+	session["mode"] = "payment"
+	session["subscription"] = nil
+	session["payment_intent"] = NewID("pi")
+	session["metadata"] = map[string]interface{}{
+		"userId":       c.UserID,
+		"type":         "battery",
+		"batteryUnits": strconv.Itoa(units),
+	}
+	return newEvent(CheckoutSessionCompleted, session)
+}
+
+// NewEvent builds an event of one of the supported types. status is the
+// subscription status of customer.subscription.updated and is ignored by
+// the other types.
+func NewEvent(eventType string, c Customer, status string) (Event, error) {
+	var object map[string]interface{}
+	switch eventType {
+	case CheckoutSessionCompleted:
+		object = c.checkoutSession()
+	case SubscriptionUpdated:
+		object = c.subscription(status)
+	case SubscriptionDeleted:
+		object = c.subscription("canceled")
+		object["canceled_at"] = time.Now().Unix()
+		object["ended_at"] = time.Now().Unix()
+	case InvoicePaymentFailed:
+		object = c.invoice()
+	default:
+		return Event{}, fmt.Errorf("unsupported event type %q", eventType)
+	}
+
+	return newEvent(eventType, object), nil
+}
+
+func newEvent(eventType string, object map[string]interface{}) Event {
+	return Event{
+		ID:         NewID("evt"),
+		Object:     "event",
+		APIVersion: apiVersion,
+		Created:    time.Now().Unix(),
+		Type:       eventType,
+		Data:       EventData{Object: object},
+	}
+}
+
+func (c Customer) metadata() map[string]interface{} {
+	return map[string]interface{}{"userId": c.UserID, "planId": c.PlanID}
+}
+
+func (c Customer) checkoutSession() map[string]interface{} {
+	id := c.SessionID
+	if id == "" {
+		id = NewID("cs_test")
+	}
+	return map[string]interface{}{
+		"id":             id,
+		"object":         "checkout.session",
+		"mode":           "subscription",
+		"status":         "complete",
+		"payment_status": "paid",
+		"customer":       c.CustomerID,
+		"subscription":   c.SubscriptionID,
+		"metadata":       c.metadata(),
+	}
+}
+
+func (c Customer) subscription(status string) map[string]interface{} {
+	start := time.Now()
+	end := start.AddDate(0, 1, 0)
+	return map[string]interface{}{
+		"id":                   c.SubscriptionID,
+		"object":               "subscription",
+		"customer":             c.CustomerID,
+		"status":               status,
+		"metadata":             c.metadata(),
+		"cancel_at":            nil,
+		"canceled_at":          nil,
+		"current_period_start": start.Unix(),
+		"current_period_end":   end.Unix(),
+		"items": map[string]interface{}{
+			"object": "list",
+			"data": []interface{}{
+				map[string]interface{}{
+					"id":                   NewID("si"),
+					"object":               "subscription_item",
+					"current_period_start": start.Unix(),
+					"current_period_end":   end.Unix(),
+					"price": map[string]interface{}{
+						"id":        c.PriceID,
+						"object":    "price",
+						"recurring": map[string]interface{}{"interval": "month"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c Customer) invoice() map[string]interface{} {
+	return map[string]interface{}{
+		"id":             NewID("in"),
+		"object":         "invoice",
+		"customer":       c.CustomerID,
+		"subscription":   c.SubscriptionID,
+		"billing_reason": "subscription_cycle",
+		"status":         "open",
+		"attempted":      true,
+		"attempt_count":  1,
+		"subscription_details": map[string]interface{}{
+			"metadata": c.metadata(),
+		},
+	}
+}
+
+// Sign returns the Stripe-Signature header for a payload sent at t
+func Sign(payload []byte, secret string, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + signature(timestamp, payload, secret)
+}
+
+// Verify checks a Stripe-Signature header the way Stripe's libraries do:
+// one of its v1 signatures must match and its timestamp must be within
+// tolerance of now
+func Verify(payload []byte, header, secret string, tolerance time.Duration) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return errors.New("no timestamp or v1 signature in header")
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp %q", timestamp)
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("timestamp outside the tolerance zone (%s)", age.Round(time.Second))
+	}
+
+	expected := signature(timestamp, payload, secret)
+	for _, s := range signatures {
+		if hmac.Equal([]byte(s), []byte(expected)) {
+			return nil
+		}
+	}
+	return errors.New("no signature matches the payload")
+}
+
+// NewID returns a random id with a Stripe object prefix
+func NewID(prefix string) string {
+	id := make([]byte, 12)
+	rand.Read(id)
+	return prefix + "_" + hex.EncodeToString(id)
+}
+
+func signature(timestamp string, payload []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,80 @@
+package stripe
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "whsec_test"
+
+func TestSignVerify(t *testing.T) {
+	payload := []byte(`{"id":"evt_1","type":"invoice.payment_failed"}`)
+	now := time.Now()
+
+	if err := Verify(payload, Sign(payload, testSecret, now), testSecret, DefaultTolerance); err != nil {
+		t.Fatalf("Verify of a fresh signature: %v", err)
+	}
+
+	// Stripe sends one v1 signature per active secret while rolling them
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	rolled := "t=" + timestamp + ",v1=" + signature(timestamp, payload, "whsec_old") + ",v1=" + signature(timestamp, payload, testSecret)
+	if err := Verify(payload, rolled, testSecret, DefaultTolerance); err != nil {
+		t.Errorf("Verify with a rolled secret: %v", err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	payload := []byte(`{"id":"evt_1"}`)
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	tests := []struct {
+		name    string
+		payload []byte
+		header  string
+		want    string
+	}{
+		{"wrong secret", payload, Sign(payload, "whsec_other", now), "no signature matches"},
+		{"changed payload", []byte(`{"id":"evt_2"}`), Sign(payload, testSecret, now), "no signature matches"},
+		{"stale timestamp", payload, Sign(payload, testSecret, now.Add(-2*DefaultTolerance)), "outside the tolerance zone"},
+		{"future timestamp", payload, Sign(payload, testSecret, now.Add(2*DefaultTolerance)), "outside the tolerance zone"},
+		{"missing header", payload, "", "no timestamp or v1 signature"},
+		{"no v1 signature", payload, "t=" + timestamp + ",v0=" + signature(timestamp, payload, testSecret), "no timestamp or v1 signature"},
+		{"invalid timestamp", payload, "t=soon,v1=abc", `invalid timestamp "soon"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.payload, tt.header, testSecret, DefaultTolerance)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Verify error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	customer := NewCustomer("user_1", "starter")
+	for _, eventType := range EventTypes {
+		event, err := NewEvent(eventType, customer, "active")
+		if err != nil {
+			t.Fatalf("NewEvent(%s): %v", eventType, err)
+		}
+		if event.Type != eventType || !strings.HasPrefix(event.ID, "evt_") {
+			t.Errorf("NewEvent(%s) = type %q, id %q", eventType, event.Type, event.ID)
+		}
+		data, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), `"userId":"user_1"`) {
+			t.Errorf("%s does not name the user in its metadata: %s", eventType, data)
+		}
+	}
+
+	if _, err := NewEvent("charge.refunded", customer, ""); err == nil {
+		t.Error("NewEvent of an unsupported type succeeded")
+	}
+}
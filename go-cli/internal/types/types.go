@@ -1,6 +1,9 @@
 package types
 
-import "time"
+import (
+	"net/http"
+	"time"
+)
 
 // TestResult represents the result of a single API test
 type TestResult struct {
@@ -10,6 +13,54 @@ type TestResult struct {
 	Response    interface{}   `json:"response,omitempty"`
 	Duration    time.Duration `json:"duration"`
 	StatusCode  int           `json:"status_code"`
+
+	// Category groups the result in summaries and reports
+	Category string `json:"category,omitempty"`
+
+	// Operation is the tested "METHOD /path/{template}", used for coverage
+	Operation string `json:"operation,omitempty"`
+
+	// AssertionFailures lists response checks that did not hold
+	AssertionFailures []string `json:"assertion_failures,omitempty"`
+
+	// Retries lists the attempts that were retried before the final one
+	Retries []Retry `json:"retries,omitempty"`
+
+	// KnownGap, if set, is why the check is expected to fail: the API has a
+	// known flaw the check documents. Known gaps are reported but, like
+	// missing credentials, do not fail the run.
+	KnownGap string `json:"known_gap,omitempty"`
+}
+
+// Retry describes a failed attempt that was retried
+type Retry struct {
+	Attempt    int           `json:"attempt"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Delay      time.Duration `json:"delay"`
+}
+
+// RetryPolicy controls how failed requests are retried. A MaxAttempts of
+// zero or one disables retries.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first
+	BaseDelay   time.Duration // first backoff, doubled on each attempt
+	MaxDelay    time.Duration // cap on backoff; longer server waits give up
+	AllMethods  bool          // also retry non-idempotent methods
+}
+
+// WebhookConfig configures the Stripe webhook suite and, when it has a
+// secret, the billing scenario of a normal run
+type WebhookConfig struct {
+	Secret string   // signing secret, the API's STRIPE_WEBHOOK_SECRET
+	UserID string   // user the events are about, defaults to the --bearer user
+	PlanID string   // plan of the subscription the events describe
+	Events []string // event types to send, in order
+
+	// SubscriptionID is a test-mode Stripe subscription of PlanID for the
+	// events to describe. If empty they describe a made-up one, which only
+	// the mock accepts.
+	SubscriptionID string
 }
 
 // Config holds the configuration for the validator
@@ -18,6 +69,49 @@ type Config struct {
 	AuthToken string
 	Verbose   bool
 	Timeout   time.Duration
+
+	// SpecFiles are extra YAML/JSON test spec files to run
+	SpecFiles []string
+	// SkipBuiltinSpec disables the built-in default spec
+	SkipBuiltinSpec bool
+	// Contract validates live responses against /api/openapi.json
+	Contract bool
+	// RateLimit runs the rate limit conformance suite instead of the specs
+	RateLimit bool
+	// RateLimitEndpoints are the "METHOD /path" endpoints the suite bursts
+	RateLimitEndpoints []string
+	// SecondBearer is a JWT of another user. With it, the run checks that
+	// neither user can reach the other's conversations, messages or files.
+	SecondBearer string
+	// RefreshToken starts the token lifecycle suite, which exchanges it and
+	// the refresh tokens it yields. A server that rotates refresh tokens
+	// invalidates it.
+	RefreshToken string
+	// JWTSecret signs the expired and wrong-audience tokens of the JWT
+	// rejection suite; they are only sent if a token it signs is accepted
+	JWTSecret string
+	// Models smoke tests every model from /api/models instead of the specs
+	Models bool
+	// BatteryAudit checks battery spend against the usage endpoints
+	// instead of running the specs
+	BatteryAudit bool
+	// BatteryAuditModels are the models the audit chats with; empty means
+	// the first text model of every provider
+	BatteryAuditModels []string
+	// Webhooks sends signed Stripe webhook events instead of the specs
+	Webhooks bool
+	// Billing runs the billing scenario after the specs. It changes the
+	// Clerk user's subscription and battery, so it is never on by default.
+	Billing bool
+	Webhook WebhookConfig
+	// Parallel is the number of checks run concurrently; 1 or less runs
+	// them serially
+	Parallel int
+	// Retry is the retry policy for every client
+	Retry RetryPolicy
+	// Transport overrides the HTTP transport shared by every client, e.g.
+	// to record or replay a cassette
+	Transport http.RoundTripper
 }
 
 // ModelsResponse represents the response from GET /api/models
@@ -85,6 +179,16 @@ type AuthResponse struct {
 	User         *AuthUser `json:"user"`
 }
 
+// RefreshResponse is the response of /api/v1/auth/refresh. The refresh
+// token is only returned if the server rotated it.
+type RefreshResponse struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	ExpiresIn    int       `json:"expiresIn"`
+	TokenType    string    `json:"tokenType"`
+	User         *AuthUser `json:"user"`
+}
+
 type AuthUser struct {
 	ID    string `json:"id"`
 	Email string `json:"email"`
@@ -106,8 +210,9 @@ type ChatRequest struct {
 }
 
 type ChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string   `json:"role"`
+	Content string   `json:"content"`
+	Images  []string `json:"images,omitempty"` // URLs or data URLs, user messages only
 }
 
 type ChatResponse struct {
@@ -137,6 +242,21 @@ type Conversation struct {
 	LastMessage *LastMessage `json:"lastMessage,omitempty"`
 }
 
+// ConversationDetail is the response from GET /api/v1/conversations/{id}
+type ConversationDetail struct {
+	Conversation
+	MessageCount int `json:"messageCount"`
+}
+
+// ConversationUpdateResponse is the response from PATCH
+// /api/v1/conversations/{id}, which echoes only the fields that changed
+type ConversationUpdateResponse struct {
+	ID         string `json:"id"`
+	Title      string `json:"title,omitempty"`
+	IsArchived *bool  `json:"isArchived,omitempty"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
 type LastMessage struct {
 	ID        string `json:"id"`
 	Role      string `json:"role"`
@@ -160,17 +280,85 @@ type MessageRequest struct {
 type V1MessageRequest struct {
 	Content       string   `json:"content"`
 	AttachmentIDs []string `json:"attachmentIds,omitempty"`
-	Stream        bool     `json:"stream,omitempty"`
+	Stream        bool     `json:"stream"` // The server streams when omitted
+}
+
+// Stream event types sent by POST /api/v1/conversations/{id}/messages
+const (
+	StreamEventMessageStart    = "message_start"
+	StreamEventContentChunk    = "content_chunk"
+	StreamEventMessageComplete = "message_complete"
+	StreamEventError           = "error"
+	StreamEventDone            = "done" // the "data: [DONE]" sentinel
+)
+
+// StreamEvent is a server-sent event from the v1 messages stream
+type StreamEvent struct {
+	ID      string `json:"id,omitempty"`
+	Type    string `json:"type"`
+	Content string `json:"content,omitempty"`
+	Error   string `json:"error,omitempty"`
 }
 
+// Message is a message as GET /api/v1/conversations/{id}/messages returns
+// it. Model and ParentID are nullable columns; attachments are listed once
+// the conversation has messages.
 type Message struct {
-	ID             string       `json:"id"`
-	ConversationID string       `json:"conversationId"`
-	Role           string       `json:"role"`
-	Content        string       `json:"content"`
-	Model          string       `json:"model,omitempty"`
-	CreatedAt      string       `json:"createdAt"`
-	Attachments    []Attachment `json:"attachments,omitempty"`
+	ID              string              `json:"id"`
+	ConversationID  string              `json:"conversationId"`
+	Role            string              `json:"role"`
+	Content         string              `json:"content"`
+	Model           *string             `json:"model"`
+	ParentID        *string             `json:"parentId"`
+	IsComplete      bool                `json:"isComplete"`
+	TokensGenerated int                 `json:"tokensGenerated"`
+	CreatedAt       string              `json:"createdAt"`
+	Attachments     []MessageAttachment `json:"attachments,omitempty"`
+}
+
+// StoredMessage is a whole row of the messages table, as the web app's
+// message routes return it
+type StoredMessage struct {
+	Message
+	StreamState *string `json:"streamState"`
+	TotalTokens *int    `json:"totalTokens"`
+	StreamID    *string `json:"streamId"`
+}
+
+// StoredMessagesResponse is the response from GET
+// /api/conversations/{id}/messages
+type StoredMessagesResponse struct {
+	Messages []StoredMessage `json:"messages"`
+}
+
+// StoredMessageResponse is the response from POST
+// /api/conversations/{id}/messages
+type StoredMessageResponse struct {
+	Message StoredMessage `json:"message"`
+}
+
+// SentMessage is the reply of POST /api/v1/conversations/{id}/messages
+// with "stream": false
+type SentMessage struct {
+	ID             string `json:"id"`
+	ConversationID string `json:"conversationId"`
+	Role           string `json:"role"`
+	Content        string `json:"content"`
+	Model          string `json:"model"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+// MessageAttachment is a row of the attachments table, as listed with a
+// message
+type MessageAttachment struct {
+	ID        string `json:"id"`
+	MessageID string `json:"messageId"`
+	FileName  string `json:"fileName"`
+	FileType  string `json:"fileType"`
+	FileSize  int    `json:"fileSize"`
+	R2Key     string `json:"r2Key"`
+	URL       string `json:"url"`
+	CreatedAt string `json:"createdAt"`
 }
 
 type MessagesResponse struct {
@@ -189,6 +377,25 @@ type Attachment struct {
 	Key      string `json:"key"`
 }
 
+// UploadResponse represents the response from POST /api/upload
+type UploadResponse struct {
+	Success    bool            `json:"success"`
+	Attachment *FileAttachment `json:"attachment,omitempty"`
+	Error      string          `json:"error,omitempty"`
+}
+
+// FileAttachment is the attachment record returned by POST /api/upload
+type FileAttachment struct {
+	ID             string `json:"id"`
+	ConversationID string `json:"conversationId"`
+	MessageID      string `json:"messageId"`
+	FileName       string `json:"fileName"`
+	FileSize       int    `json:"fileSize"`
+	MimeType       string `json:"mimeType"`
+	UploadedAt     string `json:"uploadedAt"`
+	R2Key          string `json:"r2Key"`
+}
+
 // Search Types
 type SearchResponse struct {
 	Results []SearchResult `json:"results"`
@@ -229,30 +436,30 @@ type SubscriptionInfo struct {
 }
 
 // User Types
+// UserTierResponse is GET /api/user/tier, which only reports the tier; the
+// subscription is in SubscriptionStatusResponse
 type UserTierResponse struct {
-	Tier           string `json:"tier"`
-	IsSubscribed   bool   `json:"isSubscribed"`
-	SubscriptionID string `json:"subscriptionId,omitempty"`
+	Tier string `json:"tier"`
 }
 
 type UserProfile struct {
 	ID           string            `json:"id"`
 	Email        string            `json:"email"`
-	Name         string            `json:"name"`
-	ImageURL     string            `json:"imageUrl,omitempty"`
+	Name         *string           `json:"name"`
+	ImageURL     *string           `json:"imageUrl"`
 	Tier         string            `json:"tier"`
 	CreatedAt    string            `json:"createdAt"`
-	Subscription *UserSubscription `json:"subscription,omitempty"`
+	Subscription *UserSubscription `json:"subscription"`
 	Battery      *UserBattery      `json:"battery"`
 }
 
 type UserSubscription struct {
 	ID               string   `json:"id"`
 	PlanID           string   `json:"planId"`
-	PlanName         string   `json:"planName"`
+	PlanName         *string  `json:"planName"` // null if the plan row is gone
 	Status           string   `json:"status"`
 	CurrentPeriodEnd string   `json:"currentPeriodEnd"`
-	BillingInterval  string   `json:"billingInterval"`
+	BillingInterval  *string  `json:"billingInterval"`
 	Features         []string `json:"features"`
 }
 
@@ -267,6 +474,15 @@ type UserProfileUpdate struct {
 	ImageURL string `json:"imageUrl,omitempty"`
 }
 
+// UserProfileUpdateResponse is the response from PATCH /api/v1/user/profile,
+// the updated fields only
+type UserProfileUpdateResponse struct {
+	ID        string `json:"id"`
+	UpdatedAt string `json:"updatedAt"`
+	Name      string `json:"name,omitempty"`
+	ImageURL  string `json:"imageUrl,omitempty"`
+}
+
 type UserUsageResponse struct {
 	Period struct {
 		Start string `json:"start"`
@@ -0,0 +1,321 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/omnichat/validator/internal/spec"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const (
+	auditCategory = "Battery Audit"
+
+	// auditConversationID tags the audit's /api/chat usage
+	auditConversationID = "validator-battery-audit"
+
+	// auditRepliesPerModel is how many prompts each audited model answers,
+	// so per-model counts are checked with more than one message
+	auditRepliesPerModel = 2
+)
+
+// Usage may be recorded after the reply has streamed, so the ledger is
+// read again until it shows every reply or the attempts run out
+const (
+	auditPollAttempts = 5
+	auditPollInterval = time.Second
+)
+
+// batteryLedger is a snapshot of what the API reports about battery spend
+type batteryLedger struct {
+	date    string // UTC day the snapshot was taken
+	balance int
+	history types.BatteryUsage // the day's /api/battery usage entry
+	usage   types.UserUsageResponse
+	daily   dailyUsage // the day's /api/v1/user/usage entry
+}
+
+// dailyUsage is one day of UserUsageResponse.DailyUsage
+type dailyUsage struct {
+	batteryUsed int
+	messages    int
+	models      map[string]int
+}
+
+// Snapshot the battery balance, stream a fixed set of prompts across
+// models through /api/chat, then check the balance went down by exactly
+// what /api/v1/user/usage and the daily usage entries say was used
+func (v *Validator) runBatteryAudit() {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header("🔋", "Auditing Battery Accounting (/api/battery, /api/v1/user/usage):"))
+	fmt.Fprintln(v.out)
+
+	if !v.hasClerkAuth || !v.hasJWTAuth {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --clerk and --bearer for the same user"))
+		return
+	}
+
+	models, err := v.auditModels()
+	if err != nil {
+		v.recordAndPrint(types.TestResult{Name: "GET /api/models", Operation: "GET /api/models", Category: auditCategory, Error: err.Error()})
+		return
+	}
+
+	before, result := v.readLedger("before")
+	if !result.Success {
+		v.recordAndPrint(result)
+		return
+	}
+	fmt.Fprintf(v.out, "   Balance %d; sending %d prompt(s) to each of %d model(s)\n\n", before.balance, auditRepliesPerModel, len(models))
+
+	replies := make(map[string]int)
+	total := 0
+	for _, model := range models {
+		for i := range auditRepliesPerModel {
+			result := v.checkModelReply(model.ID, fmt.Sprintf("reply %d/%d", i+1, auditRepliesPerModel),
+				types.ChatMessage{Role: "user", Content: textPrompt}, func(string) string { return "" })
+			result.Category = auditCategory
+			v.recordAndPrint(result)
+			if result.Success {
+				replies[model.ID]++
+				total++
+			}
+		}
+	}
+
+	after, result := v.readLedger("after")
+	for attempt := 1; result.Success && after.daily.messages-before.daily.messages < total && attempt < auditPollAttempts; attempt++ {
+		time.Sleep(auditPollInterval)
+		after, result = v.readLedger("after")
+	}
+	if !result.Success {
+		v.recordAndPrint(result)
+		return
+	}
+
+	spent := before.balance - after.balance
+	fmt.Fprintf(v.out, "\n   Balance %d → %d (%d used by %d reply(ies))\n\n", before.balance, after.balance, spent, total)
+
+	v.recordAndPrint(checkUsageTotal(before, after, total))
+	if before.date != after.date {
+		fmt.Fprintln(v.out, colors.Warning("   Daily comparisons skipped: the UTC day changed during the audit"))
+	} else {
+		v.recordAndPrint(checkDailyUsage(before, after, total))
+		v.recordAndPrint(checkModelUsage(before, after, replies))
+	}
+	v.recordAndPrint(checkUsageConsistency(after.usage))
+}
+
+// auditModels returns the models named in the config, or the first text
+// model of every provider
+func (v *Validator) auditModels() ([]types.AIModel, error) {
+	all, err := v.clerkClient.ListModels()
+	if err != nil {
+		return nil, err
+	}
+
+	var models []types.AIModel
+	if len(v.config.BatteryAuditModels) > 0 {
+		for _, id := range v.config.BatteryAuditModels {
+			i := slices.IndexFunc(all, func(m types.AIModel) bool { return m.ID == id })
+			if i < 0 {
+				return nil, fmt.Errorf("model %q is not listed by /api/models", id)
+			}
+			models = append(models, all[i])
+		}
+		return models, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, model := range all {
+		if model.SupportsImageGeneration || seen[model.Provider] {
+			continue
+		}
+		seen[model.Provider] = true
+		models = append(models, model)
+	}
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no text models listed by /api/models")
+	}
+	return models, nil
+}
+
+// readLedger reads /api/battery with the Clerk token and /api/v1/user/usage
+// with the JWT. The result only matters when it failed.
+func (v *Validator) readLedger(step string) (batteryLedger, types.TestResult) {
+	ledger := batteryLedger{date: time.Now().UTC().Format("2006-01-02")}
+
+	result := v.clerkClient.TestEndpoint("GET /api/battery ("+step+")", "GET", "/api/battery", nil)
+	result.Operation = "GET /api/battery"
+	result.Category = auditCategory
+	var battery types.BatteryResponse
+	if result.Success {
+		if err := decodeResponse(result.Response, &battery); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+	}
+	if !result.Success {
+		return ledger, v.addAuthHint(result, spec.AuthClerk)
+	}
+	ledger.balance = battery.Balance
+	for _, day := range battery.UsageHistory {
+		if day.Date == ledger.date {
+			ledger.history = day
+		}
+	}
+
+	result = v.jwtClient.TestEndpoint("GET /api/v1/user/usage ("+step+")", "GET", "/api/v1/user/usage", nil)
+	result.Operation = "GET /api/v1/user/usage"
+	result.Category = auditCategory
+	if result.Success {
+		if err := decodeResponse(result.Response, &ledger.usage); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+	}
+	if !result.Success {
+		return ledger, v.addAuthHint(result, spec.AuthJWT)
+	}
+	for _, day := range ledger.usage.DailyUsage {
+		if day.Date == ledger.date {
+			ledger.daily = dailyUsage{batteryUsed: day.BatteryUsed, messages: day.Messages, models: day.Models}
+		}
+	}
+	return ledger, result
+}
+
+// checkUsageTotal compares the balance decrease with the growth of the
+// usage summary
+func checkUsageTotal(before, after batteryLedger, replies int) types.TestResult {
+	result := types.TestResult{Name: "Balance decrease matches /api/v1/user/usage", Category: auditCategory, Success: true}
+
+	spent := before.balance - after.balance
+	used := after.usage.Summary.TotalBatteryUsed - before.usage.Summary.TotalBatteryUsed
+	if replies > 0 && spent <= 0 {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("balance: expected a decrease after %d reply(ies), went from %d to %d", replies, before.balance, after.balance))
+	}
+	if used != spent {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("summary.totalBatteryUsed: grew by %d, the balance went down by %d", used, spent))
+	}
+	if messages := after.usage.Summary.TotalMessages - before.usage.Summary.TotalMessages; messages != replies {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("summary.totalMessages: grew by %d, expected the %d reply(ies) sent", messages, replies))
+	}
+	if after.balance < 0 {
+		result.AssertionFailures = append(result.AssertionFailures, fmt.Sprintf("balance: went negative (%d)", after.balance))
+	}
+	failIfAsserted(&result)
+	return result
+}
+
+// checkDailyUsage compares the balance decrease with the growth of the
+// day's entries in /api/v1/user/usage and /api/battery
+func checkDailyUsage(before, after batteryLedger, replies int) types.TestResult {
+	result := types.TestResult{Name: "Balance decrease matches the daily usage of " + after.date, Category: auditCategory, Success: true}
+
+	spent := before.balance - after.balance
+	if used := after.daily.batteryUsed - before.daily.batteryUsed; used != spent {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("dailyUsage[%s].batteryUsed: grew by %d, the balance went down by %d", after.date, used, spent))
+	}
+	if messages := after.daily.messages - before.daily.messages; messages != replies {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("dailyUsage[%s].messages: grew by %d, expected the %d reply(ies) sent", after.date, messages, replies))
+	}
+	if used := after.history.Usage - before.history.Usage; used != spent {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("usageHistory[%s].usage: grew by %d, the balance went down by %d", after.date, used, spent))
+	}
+	if messages := after.history.Messages - before.history.Messages; messages != replies {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("usageHistory[%s].messages: grew by %d, expected the %d reply(ies) sent", after.date, messages, replies))
+	}
+	failIfAsserted(&result)
+	return result
+}
+
+// checkModelUsage compares the replies sent to each model with the growth
+// of the day's model counts and the model breakdown
+func checkModelUsage(before, after batteryLedger, replies map[string]int) types.TestResult {
+	result := types.TestResult{Name: "Per-model usage matches the replies sent", Category: auditCategory, Success: true}
+
+	models := make([]string, 0, len(replies))
+	for model := range replies {
+		models = append(models, model)
+	}
+	slices.Sort(models)
+
+	for _, model := range models {
+		if counted := after.daily.models[model] - before.daily.models[model]; counted != replies[model] {
+			result.AssertionFailures = append(result.AssertionFailures,
+				fmt.Sprintf("dailyUsage[%s].models[%s]: grew by %d, expected %d", after.date, model, counted, replies[model]))
+		}
+		if counted := breakdownCount(after.usage, model) - breakdownCount(before.usage, model); counted != replies[model] {
+			result.AssertionFailures = append(result.AssertionFailures,
+				fmt.Sprintf("modelBreakdown[%s].messageCount: grew by %d, expected %d", model, counted, replies[model]))
+		}
+	}
+	failIfAsserted(&result)
+	return result
+}
+
+// checkUsageConsistency checks the usage summary and model breakdown add
+// up to the daily entries they are derived from
+func checkUsageConsistency(usage types.UserUsageResponse) types.TestResult {
+	result := types.TestResult{Name: "Usage summary adds up to its daily entries", Category: auditCategory, Success: true}
+
+	batteryUsed, messages := 0, 0
+	for _, day := range usage.DailyUsage {
+		batteryUsed += day.BatteryUsed
+		messages += day.Messages
+
+		modelMessages := 0
+		for _, count := range day.Models {
+			modelMessages += count
+		}
+		if modelMessages != day.Messages {
+			result.AssertionFailures = append(result.AssertionFailures,
+				fmt.Sprintf("dailyUsage[%s]: models count %d message(s), messages is %d", day.Date, modelMessages, day.Messages))
+		}
+	}
+	if usage.Summary.TotalBatteryUsed != batteryUsed {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("summary.totalBatteryUsed: %d, the daily entries add up to %d", usage.Summary.TotalBatteryUsed, batteryUsed))
+	}
+	if usage.Summary.TotalMessages != messages {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("summary.totalMessages: %d, the daily entries add up to %d", usage.Summary.TotalMessages, messages))
+	}
+
+	breakdown, percentage := 0, 0.0
+	for _, model := range usage.ModelBreakdown {
+		breakdown += model.MessageCount
+		percentage += model.Percentage
+	}
+	if breakdown != usage.Summary.TotalMessages {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("modelBreakdown: counts %d message(s), summary.totalMessages is %d", breakdown, usage.Summary.TotalMessages))
+	}
+	if len(usage.ModelBreakdown) > 0 && math.Abs(percentage-100) > 0.5 {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("modelBreakdown: percentages add up to %.1f, expected 100", percentage))
+	}
+	failIfAsserted(&result)
+	return result
+}
+
+// breakdownCount returns a model's message count in the model breakdown
+func breakdownCount(usage types.UserUsageResponse, model string) int {
+	for _, entry := range usage.ModelBreakdown {
+		if entry.Model == model {
+			return entry.MessageCount
+		}
+	}
+	return 0
+}
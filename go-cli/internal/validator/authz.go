@@ -0,0 +1,362 @@
+package validator
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const authzCategory = "Authorization"
+
+// Actors in the authorization matrix
+const (
+	actorOwner = "user A"
+	actorOther = "user B"
+)
+
+// Content written by the checks, so the owner can spot changes made by
+// the other user
+const (
+	authzTitle        = "Validator Authorization Test"
+	authzOwnerMessage = "Authorization check message from user A"
+	authzOtherMessage = "Authorization check message from user B"
+	authzOtherTitle   = "Renamed by user B"
+)
+
+// authzCell is one resource × actor × verb outcome
+type authzCell struct {
+	resource string
+	verb     string
+	actor    string
+	result   types.TestResult
+}
+
+// authzProbe is a request user B sends against a resource of user A
+type authzProbe struct {
+	resource  string
+	verb      string
+	path      string
+	operation string
+	body      interface{}
+}
+
+// authzMatrix collects the cells of a run for the final report
+type authzMatrix struct {
+	cells []authzCell
+}
+
+// Create resources as user A, check user B can neither read nor change
+// them, then check user A still can and nothing was changed
+func (v *Validator) runAuthorizationMatrix() {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header("🛡️", "Testing Authorization (user A vs user B):"))
+	fmt.Fprintln(v.out)
+
+	if !v.hasJWTAuth || v.secondClient == nil {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --bearer and --bearer-b"))
+		return
+	}
+
+	if !v.checkDistinctUsers() {
+		return
+	}
+
+	var matrix authzMatrix
+	defer matrix.print(v.out)
+
+	// Resources owned by user A
+	conversation, result := v.createV1Conversation(v.jwtClient, authzTitle)
+	result.Name += " (" + actorOwner + ")"
+	result = authzResult(result)
+	matrix.add("conversation", "POST", actorOwner, result)
+	v.recordAndPrint(result)
+	if conversation == nil {
+		return
+	}
+	convDeleted := false
+	defer func() {
+		if !convDeleted {
+			v.deleteV1Conversation(v.jwtClient, conversation.ID)
+		}
+	}()
+	convPath := "/api/v1/conversations/" + conversation.ID
+
+	message := v.jwtClient.TestEndpoint("POST /api/v1/conversations/{id}/messages ("+actorOwner+")", "POST", convPath+"/messages",
+		types.V1MessageRequest{Content: authzOwnerMessage, Stream: false})
+	message.Operation = "POST /api/v1/conversations/{id}/messages"
+	v.recordOwner(&matrix, "messages", "POST", message)
+
+	fileKey, fileData := v.uploadAuthzFile(&matrix, conversation.ID)
+	fileDeleted := fileKey == ""
+	defer func() {
+		if !fileDeleted {
+			if resp, err := v.jwtClient.Request("DELETE", "/api/v1/files/"+escapeKey(fileKey), nil); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}()
+
+	// User B must get 403 or 404 on every verb
+	probes := []authzProbe{
+		{"conversation", "GET", convPath, "GET /api/v1/conversations/{id}", nil},
+		{"conversation", "PATCH", convPath, "PATCH /api/v1/conversations/{id}", types.ConversationUpdateRequest{Title: authzOtherTitle}},
+		{"conversation", "DELETE", convPath, "DELETE /api/v1/conversations/{id}", nil},
+		{"messages", "GET", convPath + "/messages", "GET /api/v1/conversations/{id}/messages", nil},
+		{"messages", "POST", convPath + "/messages", "POST /api/v1/conversations/{id}/messages", types.V1MessageRequest{Content: authzOtherMessage, Stream: false}},
+	}
+	if fileKey != "" {
+		filePath := "/api/v1/files/" + escapeKey(fileKey)
+		probes = append(probes,
+			authzProbe{"file", "GET", filePath, "GET /api/v1/files/{key}", nil},
+			authzProbe{"file", "DELETE", filePath, "DELETE /api/v1/files/{key}", nil},
+		)
+	}
+	for _, probe := range probes {
+		v.probeAsOther(&matrix, probe)
+	}
+
+	// User A still sees everything unchanged, then cleans up
+	v.recordOwner(&matrix, "conversation", "GET", v.ownerGetConversation(convPath))
+	v.recordOwner(&matrix, "messages", "GET", v.ownerGetMessages(convPath))
+	if fileKey != "" {
+		filePath := "/api/v1/files/" + escapeKey(fileKey)
+		v.recordOwner(&matrix, "file", "GET", v.downloadAndCompare(v.jwtClient,
+			"GET /api/v1/files/{key} ("+actorOwner+")", "GET /api/v1/files/{key}", filePath, fileData, ""))
+
+		result := v.jwtClient.TestEndpoint("DELETE /api/v1/files/{key} ("+actorOwner+")", "DELETE", filePath, nil)
+		result.Operation = "DELETE /api/v1/files/{key}"
+		fileDeleted = result.Success
+		v.recordOwner(&matrix, "file", "DELETE", result)
+	}
+
+	result = v.jwtClient.TestEndpoint("PATCH /api/v1/conversations/{id} ("+actorOwner+")", "PATCH", convPath,
+		types.ConversationUpdateRequest{Title: authzTitle + " (renamed)"})
+	result.Operation = "PATCH /api/v1/conversations/{id}"
+	v.recordOwner(&matrix, "conversation", "PATCH", result)
+
+	result = v.deleteV1Conversation(v.jwtClient, conversation.ID)
+	result.Name += " (" + actorOwner + ")"
+	convDeleted = result.Success
+	v.recordOwner(&matrix, "conversation", "DELETE", result)
+}
+
+// checkDistinctUsers confirms the two tokens belong to different users,
+// without which every denial check would be meaningless
+func (v *Validator) checkDistinctUsers() bool {
+	result := types.TestResult{
+		Name:      "GET /api/v1/user/profile (distinct users)",
+		Operation: "GET /api/v1/user/profile",
+		Category:  authzCategory,
+	}
+
+	actors := []struct {
+		name   string
+		client *client.APIClient
+	}{{actorOwner, v.jwtClient}, {actorOther, v.secondClient}}
+
+	var ids [2]string
+	for i, actor := range actors {
+		profile := actor.client.TestEndpoint(result.Name, "GET", "/api/v1/user/profile", nil)
+		result.Duration += profile.Duration
+		result.StatusCode = profile.StatusCode
+		if !profile.Success {
+			result.Error = fmt.Sprintf("%s: %s", actor.name, profile.Error)
+			v.recordAndPrint(result)
+			return false
+		}
+
+		var user types.UserProfile
+		if err := decodeResponse(profile.Response, &user); err != nil || user.ID == "" {
+			result.Error = fmt.Sprintf("%s: profile has no id", actor.name)
+			v.recordAndPrint(result)
+			return false
+		}
+		ids[i] = user.ID
+	}
+
+	result.Response = map[string]interface{}{"userA": ids[0], "userB": ids[1]}
+	result.Success = ids[0] != ids[1]
+	if !result.Success {
+		result.Error = fmt.Sprintf("--bearer and --bearer-b both belong to %s", ids[0])
+	}
+	v.recordAndPrint(result)
+	return result.Success
+}
+
+// uploadAuthzFile uploads a file as user A and returns its key, or an
+// empty key when the upload failed
+func (v *Validator) uploadAuthzFile(matrix *authzMatrix, conversationID string) (string, []byte) {
+	fixture := uploadFixtures[0]
+	data, err := fixtures.ReadFile("fixtures/" + fixture.fileName)
+	if err != nil {
+		v.recordAndPrint(authzResult(types.TestResult{Name: "POST /api/v1/upload (" + actorOwner + ")", Error: err.Error()}))
+		return "", nil
+	}
+
+	result := v.jwtClient.TestMultipart("POST /api/v1/upload ("+actorOwner+")", "POST", "/api/v1/upload",
+		map[string]string{"conversationId": conversationID}, []client.FilePart{fixture.part(data)})
+	result.Operation = "POST /api/v1/upload"
+
+	var attachment types.Attachment
+	if result.Success {
+		if err := decodeResponse(result.Response, &attachment); err != nil {
+			result.AssertionFailures = append(result.AssertionFailures, err.Error())
+		} else if attachment.Key == "" {
+			result.AssertionFailures = append(result.AssertionFailures, "key: expected non-empty value")
+		}
+	}
+	v.recordOwner(matrix, "file", "POST", result)
+
+	if attachment.Key == "" {
+		return "", nil
+	}
+	return attachment.Key, data
+}
+
+// probeAsOther sends a request as user B, which must be denied
+func (v *Validator) probeAsOther(matrix *authzMatrix, probe authzProbe) {
+	result := v.secondClient.TestEndpoint(probe.operation+" ("+actorOther+")", probe.verb, probe.path, probe.body)
+	result.Operation = probe.operation
+
+	switch {
+	case result.StatusCode == 403 || result.StatusCode == 404:
+		result.Success = true
+		result.Error = ""
+	case result.StatusCode >= 200 && result.StatusCode < 300:
+		result.Success = false
+		result.Error = fmt.Sprintf("HTTP %d: %s reached a %s owned by %s", result.StatusCode, actorOther, probe.resource, actorOwner)
+	case result.StatusCode == 401:
+		// Not an auth hint: the token worked for the profile check
+		result.Success = false
+		result.Error = fmt.Sprintf("HTTP 401: expected 403 or 404, %s's token was rejected", actorOther)
+	default:
+		result.Success = false
+		if result.StatusCode != 0 {
+			result.Error = fmt.Sprintf("HTTP %d: expected 403 or 404", result.StatusCode)
+		}
+	}
+
+	result = authzResult(result)
+	matrix.add(probe.resource, probe.verb, actorOther, result)
+	v.recordAndPrint(result)
+}
+
+// ownerGetConversation fetches the conversation as user A and checks
+// user B's rename did not stick
+func (v *Validator) ownerGetConversation(convPath string) types.TestResult {
+	result := v.jwtClient.TestEndpoint("GET /api/v1/conversations/{id} ("+actorOwner+")", "GET", convPath, nil)
+	result.Operation = "GET /api/v1/conversations/{id}"
+	if !result.Success {
+		return result
+	}
+
+	var conversation types.ConversationDetail
+	if err := decodeResponse(result.Response, &conversation); err != nil {
+		result.AssertionFailures = append(result.AssertionFailures, err.Error())
+	} else if conversation.Title != authzTitle {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("title: expected %q, got %q after %s's PATCH", authzTitle, conversation.Title, actorOther))
+	}
+	return result
+}
+
+// ownerGetMessages lists messages as user A and checks user B's message
+// was not added
+func (v *Validator) ownerGetMessages(convPath string) types.TestResult {
+	result := v.jwtClient.TestEndpoint("GET /api/v1/conversations/{id}/messages ("+actorOwner+")", "GET", convPath+"/messages", nil)
+	result.Operation = "GET /api/v1/conversations/{id}/messages"
+	if !result.Success {
+		return result
+	}
+
+	var messages types.MessagesResponse
+	if err := decodeResponse(result.Response, &messages); err != nil {
+		result.AssertionFailures = append(result.AssertionFailures, err.Error())
+		return result
+	}
+	for i, message := range messages.Messages {
+		if message.Content == authzOtherMessage {
+			result.AssertionFailures = append(result.AssertionFailures,
+				fmt.Sprintf("messages.%d: contains the message %s posted", i, actorOther))
+		}
+	}
+	return result
+}
+
+// recordOwner checks and records a request made by user A, which must
+// succeed
+func (v *Validator) recordOwner(matrix *authzMatrix, resource, verb string, result types.TestResult) {
+	if result.Success {
+		result.AssertionFailures = append(result.AssertionFailures, checkResponseType(result)...)
+		failIfAsserted(&result)
+	}
+	result = authzResult(result)
+	matrix.add(resource, verb, actorOwner, result)
+	v.recordAndPrint(result)
+}
+
+func authzResult(result types.TestResult) types.TestResult {
+	result.Category = authzCategory
+	return result
+}
+
+func (m *authzMatrix) add(resource, verb, actor string, result types.TestResult) {
+	m.cells = append(m.cells, authzCell{resource: resource, verb: verb, actor: actor, result: result})
+}
+
+// print writes the resource × verb table with a column per actor
+func (m *authzMatrix) print(w io.Writer) {
+	if len(m.cells) == 0 {
+		return
+	}
+
+	type row struct{ resource, verb string }
+	var rows []row
+	cells := make(map[row]map[string]types.TestResult)
+	for _, cell := range m.cells {
+		key := row{cell.resource, cell.verb}
+		if cells[key] == nil {
+			rows = append(rows, key)
+			cells[key] = make(map[string]types.TestResult)
+		}
+		cells[key][cell.actor] = cell.result
+	}
+	resourceOrder := []string{"conversation", "messages", "file"}
+	verbOrder := []string{"GET", "POST", "PATCH", "DELETE"}
+	slices.SortStableFunc(rows, func(a, b row) int {
+		if a.resource != b.resource {
+			return slices.Index(resourceOrder, a.resource) - slices.Index(resourceOrder, b.resource)
+		}
+		return slices.Index(verbOrder, a.verb) - slices.Index(verbOrder, b.verb)
+	})
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, colors.BoldText("Authorization matrix:"))
+	fmt.Fprintf(w, "   %-13s %-7s %-15s %s\n", "Resource", "Verb", actorOwner+" (owner)", actorOther)
+	for _, r := range rows {
+		fmt.Fprintf(w, "   %-13s %-7s %s %s\n", r.resource, r.verb,
+			formatAuthzCell(cells[r], actorOwner, 12), formatAuthzCell(cells[r], actorOther, 0))
+	}
+}
+
+// formatAuthzCell renders an actor's outcome as "✅ 404". The status is
+// padded to width, as the emoji and color codes would upset %-*s.
+func formatAuthzCell(cells map[string]types.TestResult, actor string, width int) string {
+	result, ok := cells[actor]
+	if !ok {
+		return fmt.Sprintf("%-*s", width+3, "-")
+	}
+
+	status := "error"
+	if result.StatusCode != 0 {
+		status = fmt.Sprint(result.StatusCode)
+	}
+	if result.Success {
+		return fmt.Sprintf("%s %-*s", colors.Success("✅"), width, status)
+	}
+	return fmt.Sprintf("%s %-*s", colors.Error("❌"), width, status)
+}
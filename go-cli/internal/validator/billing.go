@@ -0,0 +1,269 @@
+package validator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/omnichat/validator/internal/stripe"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const billingCategory = "Billing Flow"
+
+const checkoutOperation = "POST /api/stripe/checkout"
+
+// defaultBillingPlan is bought when the config names no plan
+const defaultBillingPlan = "starter"
+
+// billingExpectation is what the billing endpoints must report after a
+// step of the billing scenario
+type billingExpectation struct {
+	tier           string // empty if any tier is fine
+	subscriptionID string // empty if the user must not be subscribed
+	planName       string
+	balance        int
+}
+
+// Buy a subscription and then a battery pack through /api/stripe/checkout,
+// complete each checkout with the signed webhook Stripe would send, and
+// check the tier, subscription status and battery balance after each. The
+// subscription is cancelled with a webhook at the end; the battery units
+// bought stay on the account. If the subscription fails, as it does against
+// a deployment without a test-mode subscription, the battery pack is still
+// bought. Users on the paid tier are skipped: the checkout webhook would
+// overwrite their subscription and the cancellation would end it.
+func (v *Validator) runBillingScenario() {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header("🧾", "Testing Billing Flow (checkout → webhook → tier and battery):"))
+	fmt.Fprintln(v.out)
+
+	config := v.config.Webhook
+	if config.Secret == "" {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --stripe-webhook-secret"))
+		return
+	}
+	if !v.hasClerkAuth {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --clerk"))
+		return
+	}
+	userID := config.UserID
+	if userID == "" {
+		profile, ok := v.bearerProfile()
+		if !ok {
+			fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --stripe-user-id or --bearer to name the user"))
+			return
+		}
+		userID = profile.ID
+	}
+	planID := config.PlanID
+	if planID == "" {
+		planID = defaultBillingPlan
+	}
+	plan, ok := stripe.Plans[planID]
+	if !ok {
+		fmt.Fprintln(v.out, colors.Warning(fmt.Sprintf("   Skipped: unknown plan %q", planID)))
+		return
+	}
+
+	before, result := v.readBillingState("before checkout")
+	if !result.Success {
+		result.Category = billingCategory
+		v.recordAndPrint(result)
+		return
+	}
+	fmt.Fprintf(v.out, "   User %s, %s tier, battery %d\n", userID, before.tier, before.balance)
+	if before.tier == "paid" {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: the user is on the paid tier, use a free test account"))
+		return
+	}
+
+	// Subscription checkout
+	customer := stripe.NewCustomer(userID, planID)
+	if config.SubscriptionID != "" {
+		customer.SubscriptionID = config.SubscriptionID
+	}
+	expected := billingExpectation{tier: before.tier, balance: before.balance}
+	subscribed := v.subscribe(customer)
+	if subscribed {
+		expected = billingExpectation{
+			tier:           "paid",
+			subscriptionID: customer.SubscriptionID,
+			planName:       plan.Name,
+			balance:        before.balance + plan.BatteryUnits,
+		}
+		expected.balance = v.checkBilling("after subscription", expected)
+	}
+
+	// Battery top-up, while subscribed if the subscription went through
+	units := stripe.BatteryPacks[0]
+	sessionID, ok := v.createCheckout("battery", types.CheckoutRequest{
+		Type:         "battery",
+		BatteryUnits: units,
+		ReturnURL:    v.config.BaseURL + "/billing",
+	})
+	if ok {
+		purchase := customer
+		purchase.SessionID = sessionID
+		result = v.postWebhook(webhookOperation+" (battery checkout completed)", stripe.NewBatteryPurchaseEvent(purchase, units))
+		result.Category = billingCategory
+		v.recordAndPrint(result)
+		if result.Success {
+			expected.balance += units
+			expected.balance = v.checkBilling("after battery pack", expected)
+		}
+	}
+
+	if !subscribed {
+		return
+	}
+
+	// Cleanup: end the subscription the scenario started
+	event, err := stripe.NewEvent(stripe.SubscriptionDeleted, customer, "")
+	if err != nil {
+		v.recordAndPrint(types.TestResult{Name: webhookOperation, Operation: webhookOperation, Category: billingCategory, Error: err.Error()})
+		return
+	}
+	result = v.postWebhook(webhookOperation+" (subscription deleted)", event)
+	result.Category = billingCategory
+	v.recordAndPrint(result)
+	if result.Success {
+		// The API does not reset the tier when a subscription is deleted
+		v.checkBilling("after cancellation", billingExpectation{balance: expected.balance})
+	}
+}
+
+// subscribe creates a subscription checkout session and completes it with
+// a webhook. It reports whether the server accepted the webhook.
+func (v *Validator) subscribe(customer stripe.Customer) bool {
+	sessionID, ok := v.createCheckout("subscription", types.CheckoutRequest{
+		Type:      "subscription",
+		PlanID:    customer.PlanID,
+		ReturnURL: v.config.BaseURL + "/billing",
+	})
+	if !ok {
+		return false
+	}
+	customer.SessionID = sessionID
+
+	event, err := stripe.NewEvent(stripe.CheckoutSessionCompleted, customer, "active")
+	if err != nil {
+		v.recordAndPrint(types.TestResult{Name: webhookOperation, Operation: webhookOperation, Category: billingCategory, Error: err.Error()})
+		return false
+	}
+	result := v.postWebhook(webhookOperation+" (subscription checkout completed)", event)
+	result.Category = billingCategory
+	v.recordAndPrint(v.addSubscriptionHint(result, stripe.CheckoutSessionCompleted))
+	return result.Success
+}
+
+// createCheckout creates a checkout session and returns its id
+func (v *Validator) createCheckout(kind string, req types.CheckoutRequest) (string, bool) {
+	result := v.clerkClient.TestEndpoint(checkoutOperation+" ("+kind+")", "POST", "/api/stripe/checkout", req)
+	result.Operation = checkoutOperation
+	result.Category = billingCategory
+	if !result.Success {
+		v.recordAndPrint(v.addAuthHint(result, "clerk"))
+		return "", false
+	}
+
+	var checkout types.CheckoutResponse
+	if err := decodeResponse(result.Response, &checkout); err != nil {
+		result.AssertionFailures = append(result.AssertionFailures, err.Error())
+	}
+	result.AssertionFailures = append(result.AssertionFailures, checkResponseType(result)...)
+	if checkout.SessionID == "" {
+		result.AssertionFailures = append(result.AssertionFailures, "sessionId: expected non-empty value")
+	}
+	failIfAsserted(&result)
+	v.recordAndPrint(result)
+	return checkout.SessionID, checkout.SessionID != ""
+}
+
+// checkBilling reads the tier, subscription status and battery after a
+// step and compares them with want. /api/user/tier only reports the tier;
+// the subscription is read from /api/stripe/checkout. It returns the balance read, so the
+// next step builds on what the server reports.
+func (v *Validator) checkBilling(step string, want billingExpectation) int {
+	result := v.clerkClient.TestEndpoint("GET /api/user/tier ("+step+")", "GET", "/api/user/tier", nil)
+	result.Operation = "GET /api/user/tier"
+	result.Category = billingCategory
+	if result.Success {
+		var tier types.UserTierResponse
+		if err := decodeResponse(result.Response, &tier); err != nil {
+			result.AssertionFailures = append(result.AssertionFailures, err.Error())
+		}
+		result.AssertionFailures = append(result.AssertionFailures, checkResponseType(result)...)
+		if want.tier != "" && tier.Tier != want.tier {
+			result.AssertionFailures = append(result.AssertionFailures, fmt.Sprintf("tier: expected %q, got %q", want.tier, tier.Tier))
+		}
+		failIfAsserted(&result)
+	}
+	v.recordAndPrint(v.addAuthHint(result, "clerk"))
+
+	result = v.clerkClient.TestEndpoint("GET /api/stripe/checkout ("+step+")", "GET", "/api/stripe/checkout", nil)
+	result.Operation = "GET /api/stripe/checkout"
+	result.Category = billingCategory
+	if result.Success {
+		var status types.SubscriptionStatusResponse
+		if err := decodeResponse(result.Response, &status); err != nil {
+			result.AssertionFailures = append(result.AssertionFailures, err.Error())
+		}
+		result.AssertionFailures = append(result.AssertionFailures, checkResponseType(result)...)
+		result.AssertionFailures = append(result.AssertionFailures, checkSubscriptionStatus(status, want)...)
+		failIfAsserted(&result)
+	}
+	v.recordAndPrint(v.addAuthHint(result, "clerk"))
+
+	balance := want.balance
+	result = v.clerkClient.TestEndpoint("GET /api/battery ("+step+")", "GET", "/api/battery", nil)
+	result.Operation = "GET /api/battery"
+	result.Category = billingCategory
+	if result.Success {
+		var battery types.BatteryResponse
+		if err := decodeResponse(result.Response, &battery); err != nil {
+			result.AssertionFailures = append(result.AssertionFailures, err.Error())
+		} else {
+			balance = battery.Balance
+		}
+		result.AssertionFailures = append(result.AssertionFailures, checkResponseType(result)...)
+		if balance != want.balance {
+			result.AssertionFailures = append(result.AssertionFailures, fmt.Sprintf("balance: expected %d, got %d", want.balance, balance))
+		}
+		failIfAsserted(&result)
+	}
+	v.recordAndPrint(v.addAuthHint(result, "clerk"))
+
+	return balance
+}
+
+// checkSubscriptionStatus compares the subscription status with what a
+// step must leave behind
+func checkSubscriptionStatus(status types.SubscriptionStatusResponse, want billingExpectation) []string {
+	var failures []string
+	if want.subscriptionID == "" {
+		if status.IsSubscribed {
+			failures = append(failures, "isSubscribed: expected false after the subscription was deleted")
+		}
+		return failures
+	}
+
+	if !status.IsSubscribed {
+		failures = append(failures, "isSubscribed: expected true")
+	}
+	if status.SubscriptionID != want.subscriptionID {
+		failures = append(failures, fmt.Sprintf("subscriptionId: expected %q, got %q", want.subscriptionID, status.SubscriptionID))
+	}
+	if status.PlanName != want.planName {
+		failures = append(failures, fmt.Sprintf("planName: expected %q, got %q", want.planName, status.PlanName))
+	}
+	if status.Status != "active" {
+		failures = append(failures, fmt.Sprintf("status: expected %q, got %q", "active", status.Status))
+	}
+	if end, err := time.Parse(time.RFC3339, status.CurrentPeriodEnd); err != nil {
+		failures = append(failures, fmt.Sprintf("currentPeriodEnd: expected an RFC 3339 time, got %q", status.CurrentPeriodEnd))
+	} else if !end.After(time.Now()) {
+		failures = append(failures, fmt.Sprintf("currentPeriodEnd: expected a future time, got %s", status.CurrentPeriodEnd))
+	}
+	return failures
+}
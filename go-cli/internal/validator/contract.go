@@ -0,0 +1,220 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/openapi"
+	"github.com/omnichat/validator/internal/spec"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const openAPIPath = "/api/openapi.json"
+
+// Run every operation in the published OpenAPI document and validate the
+// live status code and body against the declared responses
+func (v *Validator) runContractSuite() error {
+	fmt.Println()
+	fmt.Println(colors.Header("📜", fmt.Sprintf("Testing OpenAPI Contract (%s):", openAPIPath)))
+	fmt.Println()
+
+	doc, err := v.openAPIDocument()
+	if err != nil {
+		return err
+	}
+
+	var tasks []task
+	for _, ref := range doc.Operations() {
+		tasks = append(tasks, func(v *Validator) {
+			v.recordAndPrint(v.checkOperation(doc, ref))
+		})
+	}
+	v.runTasks(tasks)
+
+	return nil
+}
+
+// openAPIDocument returns the published OpenAPI document, fetching it on
+// first use
+func (v *Validator) openAPIDocument() (*openapi.Document, error) {
+	if v.openAPI != nil {
+		return v.openAPI, nil
+	}
+
+	doc, err := v.fetchOpenAPI()
+	if err != nil {
+		return nil, err
+	}
+	v.openAPI = doc
+	return doc, nil
+}
+
+// fetchOpenAPI downloads and parses the published OpenAPI document
+func (v *Validator) fetchOpenAPI() (*openapi.Document, error) {
+	resp, err := v.client.Get(openAPIPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", openAPIPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to fetch %s: HTTP %d", openAPIPath, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", openAPIPath, err)
+	}
+
+	return openapi.Parse(data)
+}
+
+// Call a single operation with sample inputs and compare the response
+// against the operation's declared responses
+func (v *Validator) checkOperation(doc *openapi.Document, ref openapi.OperationRef) types.TestResult {
+	authKind := authKindForSchemes(doc.SecuritySchemes(ref.Operation))
+	client := v.clientFor(authKind)
+	name := ref.String()
+
+	path := samplePath(doc, ref)
+	body := sampleBody(doc, ref.Operation)
+
+	start := time.Now()
+	resp, err := client.Request(strings.ToUpper(ref.Method), path, body)
+	duration := time.Since(start)
+	if err != nil {
+		return types.TestResult{Name: name, Operation: name, Success: false, Error: err.Error(), Duration: duration}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return types.TestResult{
+			Name:       name,
+			Operation:  name,
+			Success:    false,
+			Error:      fmt.Sprintf("failed to read response: %v", err),
+			Duration:   duration,
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	result := types.TestResult{
+		Name:       name,
+		Operation:  name,
+		Duration:   duration,
+		StatusCode: resp.StatusCode,
+		Response:   string(respBody),
+	}
+
+	declared, code, ok := ref.Operation.MatchResponse(resp.StatusCode)
+	if !ok {
+		result.Error = fmt.Sprintf("HTTP %d: status not declared in spec", resp.StatusCode)
+		return v.addAuthHint(result, authKind)
+	}
+
+	declared, err = doc.ResolveResponse(declared)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	schema, ok := declared.SchemaFor(contentType)
+	if !ok {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("content type %q not declared for response %s", contentType, code))
+	}
+
+	if schema != nil && len(respBody) > 0 {
+		var decoded interface{}
+		if strings.Contains(contentType, "json") {
+			if err := json.Unmarshal(respBody, &decoded); err != nil {
+				result.AssertionFailures = append(result.AssertionFailures, fmt.Sprintf("$: invalid JSON: %v", err))
+			}
+			result.Response = decoded
+		} else {
+			decoded = string(respBody)
+		}
+
+		for _, mismatch := range doc.Validate(schema, decoded) {
+			result.AssertionFailures = append(result.AssertionFailures, mismatch.String())
+		}
+	}
+
+	result.Success = len(result.AssertionFailures) == 0
+	if !result.Success {
+		result.Error = fmt.Sprintf("HTTP %d: %d contract mismatch(es) against response %s", resp.StatusCode, len(result.AssertionFailures), code)
+	}
+
+	return result
+}
+
+// authKindForSchemes maps OpenAPI security scheme names to spec auth kinds
+func authKindForSchemes(schemes []string) string {
+	for _, scheme := range schemes {
+		if strings.Contains(strings.ToLower(scheme), "clerk") {
+			return spec.AuthClerk
+		}
+	}
+	if len(schemes) > 0 {
+		return spec.AuthJWT
+	}
+	return spec.AuthNone
+}
+
+// samplePath fills path parameters and required query parameters with
+// declared examples, falling back to placeholder values
+func samplePath(doc *openapi.Document, ref openapi.OperationRef) string {
+	path := ref.Path
+	query := url.Values{}
+
+	for _, param := range ref.Operation.Parameters {
+		value := param.Example
+		if value == nil {
+			value = doc.Example(param.Schema)
+		}
+		if value == nil || value == "test" {
+			value = "test-" + param.Name
+		}
+		str := fmt.Sprintf("%v", value)
+
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(str))
+		case "query":
+			if param.Required {
+				query.Set(param.Name, str)
+			}
+		}
+	}
+
+	if encoded := query.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	return path
+}
+
+// sampleBody builds a JSON request body from the declared example or
+// schema. Non-JSON bodies (e.g. multipart uploads) are not synthesized.
+func sampleBody(doc *openapi.Document, op *openapi.Operation) interface{} {
+	if op.RequestBody == nil {
+		return nil
+	}
+
+	for contentType, media := range op.RequestBody.Content {
+		if !strings.Contains(contentType, "json") {
+			continue
+		}
+		if media.Example != nil {
+			return media.Example
+		}
+		return doc.Example(media.Schema)
+	}
+	return nil
+}
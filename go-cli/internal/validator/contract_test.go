@@ -0,0 +1,88 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omnichat/validator/internal/mock"
+	"github.com/omnichat/validator/internal/types"
+)
+
+// contractDocument declares two mock routes correctly, gets the config
+// response wrong and leaves out that /api/models needs a session
+const contractDocument = `{
+	"openapi": "3.0.3",
+	"paths": {
+		"/api/v1/conversations": {
+			"get": {
+				"security": [{"bearerAuth": []}],
+				"responses": {"200": {"content": {"application/json": {"schema": {
+					"type": "object",
+					"required": ["conversations"],
+					"properties": {"conversations": {"type": "array"}}
+				}}}}}
+			}
+		},
+		"/api/v1/conversations/{id}": {
+			"get": {
+				"security": [{"bearerAuth": []}],
+				"parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}],
+				"responses": {"404": {"$ref": "#/components/responses/Error"}}
+			}
+		},
+		"/api/config": {
+			"get": {
+				"responses": {
+					"200": {"content": {"application/json": {"schema": {
+						"type": "object",
+						"required": ["stripePublishableKey", "version"],
+						"additionalProperties": false,
+						"properties": {"stripePublishableKey": {"type": "string"}, "version": {"type": "string"}}
+					}}}}
+				}
+			}
+		},
+		"/api/models": {
+			"get": {"responses": {"200": {}}}
+		}
+	},
+	"components": {
+		"responses": {
+			"Error": {"content": {"application/json": {"schema": {
+				"type": "object",
+				"required": ["error"],
+				"properties": {"error": {"type": "string"}}
+			}}}}
+		}
+	}
+}`
+
+func TestContractAgainstMock(t *testing.T) {
+	server := newMockServer(t, mock.Options{OpenAPI: []byte(contractDocument)})
+	results := run(t, &types.Config{BaseURL: server.URL, Timeout: 5 * time.Second, Parallel: 1, Contract: true})
+
+	byName := make(map[string]types.TestResult)
+	for _, result := range results {
+		byName[result.Name] = result
+	}
+	if len(byName) != 4 {
+		t.Fatalf("%d results, want one per operation: %v", len(results), results)
+	}
+	for _, name := range []string{"GET /api/v1/conversations", "GET /api/v1/conversations/{id}"} {
+		if result := byName[name]; !result.Success {
+			t.Errorf("%s failed: %s %q", name, result.Error, result.AssertionFailures)
+		}
+	}
+
+	config := byName["GET /api/config"]
+	failures := strings.Join(config.AssertionFailures, "\n")
+	if config.Success || !strings.Contains(failures, "$.version: missing required field") || !strings.Contains(failures, "$.appUrl: undeclared field") {
+		t.Errorf("GET /api/config: success %v, mismatches %q", config.Success, config.AssertionFailures)
+	}
+
+	// Without declared security the models are requested without a token
+	if models := byName["GET /api/models"]; models.StatusCode != 401 || !strings.Contains(models.Error, "status not declared") {
+		t.Errorf("GET /api/models: success %v, error %q", models.Success, models.Error)
+	}
+}
@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/omnichat/validator/internal/openapi"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+var pathParam = regexp.MustCompile(`\{[^}]*\}`)
+
+// coverageReport diffs the operations declared in the OpenAPI document
+// against the distinct operations the run exercised
+type coverageReport struct {
+	declared   int
+	covered    int
+	untested   []string
+	undeclared []string
+}
+
+// normalizeOperation strips the query string and parameter names so that
+// "GET /api/files/{key}" and "get /api/files/{id}?x=1" compare equal
+func normalizeOperation(operation string) string {
+	method, path, _ := strings.Cut(operation, " ")
+	path, _, _ = strings.Cut(path, "?")
+	path = strings.TrimSuffix(path, "/")
+	return strings.ToUpper(method) + " " + pathParam.ReplaceAllString(path, "{}")
+}
+
+// computeCoverage compares the tested operations against doc
+func (v *Validator) computeCoverage(doc *openapi.Document) coverageReport {
+	tested := make(map[string]string)
+	for _, result := range v.Results() {
+		if result.Operation != "" {
+			tested[normalizeOperation(result.Operation)] = result.Operation
+		}
+	}
+
+	declared := make(map[string]bool)
+	report := coverageReport{}
+	for _, ref := range doc.Operations() {
+		key := normalizeOperation(ref.String())
+		declared[key] = true
+		report.declared++
+
+		if _, ok := tested[key]; ok {
+			report.covered++
+		} else {
+			report.untested = append(report.untested, ref.String())
+		}
+	}
+
+	for key, operation := range tested {
+		if !declared[key] {
+			report.undeclared = append(report.undeclared, operation)
+		}
+	}
+	sort.Strings(report.undeclared)
+
+	return report
+}
+
+// Print the spec coverage section of the summary
+func (v *Validator) printCoverage() {
+	doc, err := v.openAPIDocument()
+	if err != nil {
+		fmt.Printf("Endpoint Coverage: %s\n", colors.Warning(fmt.Sprintf("unavailable (%v)", err)))
+		return
+	}
+
+	report := v.computeCoverage(doc)
+
+	percent := 0.0
+	if report.declared > 0 {
+		percent = float64(report.covered) / float64(report.declared) * 100
+	}
+	fmt.Printf("Endpoint Coverage: %d/%d spec operations (%.1f%%)\n", report.covered, report.declared, percent)
+
+	if len(report.untested) > 0 {
+		fmt.Println()
+		fmt.Println(colors.Warning(fmt.Sprintf("Untested operations (%d):", len(report.untested))))
+		for _, operation := range report.untested {
+			fmt.Printf("   • %s\n", operation)
+		}
+	}
+
+	if len(report.undeclared) > 0 {
+		fmt.Println()
+		fmt.Println(colors.Warning(fmt.Sprintf("Tested operations missing from spec (%d):", len(report.undeclared))))
+		for _, operation := range report.undeclared {
+			fmt.Printf("   • %s\n", operation)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package validator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/omnichat/validator/internal/openapi"
+	"github.com/omnichat/validator/internal/types"
+)
+
+func TestNormalizeOperation(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"GET /api/files/{key}", "get /api/files/{id}?x=1"},
+		{"POST /api/upload/", "POST /api/upload"},
+		{"GET /api/search?q=a", "GET /api/search?q=b"},
+	}
+	for _, tt := range tests {
+		if a, b := normalizeOperation(tt.a), normalizeOperation(tt.b); a != b {
+			t.Errorf("%q normalizes to %q, %q to %q", tt.a, a, tt.b, b)
+		}
+	}
+	if normalizeOperation("GET /api/files/{key}") == normalizeOperation("DELETE /api/files/{key}") {
+		t.Error("methods compare equal")
+	}
+}
+
+func TestComputeCoverage(t *testing.T) {
+	doc, err := openapi.Parse([]byte(`{
+		"openapi": "3.0.3",
+		"paths": {
+			"/api/config": {"get": {"responses": {}}},
+			"/api/files/{key}": {"get": {"responses": {}}, "delete": {"responses": {}}},
+			"/api/upload": {"post": {"responses": {}}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &Validator{results: []types.TestResult{
+		{Operation: "GET /api/config"},
+		{Operation: "GET /api/files/{id}?download=1"},
+		{Operation: "GET /api/files/{id}"},
+		{Operation: "GET /api/search?q=test"},
+		{Name: "a check without an operation"},
+	}}
+
+	report := v.computeCoverage(doc)
+	if report.declared != 4 || report.covered != 2 {
+		t.Errorf("covered %d of %d, want 2 of 4", report.covered, report.declared)
+	}
+	if want := []string{"DELETE /api/files/{key}", "POST /api/upload"}; !reflect.DeepEqual(report.untested, want) {
+		t.Errorf("untested = %q, want %q", report.untested, want)
+	}
+	if want := []string{"GET /api/search?q=test"}; !reflect.DeepEqual(report.undeclared, want) {
+		t.Errorf("undeclared = %q, want %q", report.undeclared, want)
+	}
+}
@@ -0,0 +1,176 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/jwt"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const lifecycleCategory = "Token Lifecycle"
+
+const refreshOperation = "POST /api/v1/auth/refresh"
+
+// lifetimeSkew is how far an access token's exp may be from now plus
+// ExpiresIn, allowing for clock skew and the request round trip
+const lifetimeSkew = 30 * time.Second
+
+// Exchange --refresh-token for a RefreshResponse, refresh with the refresh
+// token it returned or, if it returned none, the same token again, check
+// the new access token works and expires when ExpiresIn says, then reuse
+// the exchanged refresh token, which must be rejected if the server rotates
+// refresh tokens
+func (v *Validator) runTokenLifecycle() {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header("♻️", "Testing Token Lifecycle ("+refreshOperation+"):"))
+	fmt.Fprintln(v.out)
+
+	if v.config.RefreshToken == "" {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --refresh-token"))
+		return
+	}
+
+	initial, result := v.refreshTokens("sign in", v.config.RefreshToken)
+	v.recordAndPrint(result)
+	if initial == nil {
+		return
+	}
+
+	// A server that keeps the refresh token leaves it out of the response
+	exchanged := initial.RefreshToken
+	if exchanged == "" {
+		exchanged = v.config.RefreshToken
+	}
+	refreshed, result := v.refreshTokens("refresh", exchanged)
+	v.recordAndPrint(result)
+	if refreshed == nil {
+		return
+	}
+
+	v.recordAndPrint(v.checkAccessToken(refreshed))
+
+	if refreshed.RefreshToken == "" || refreshed.RefreshToken == exchanged {
+		fmt.Fprintln(v.out, colors.Warning("   Reuse check skipped: the server does not rotate refresh tokens"))
+		return
+	}
+
+	result = v.client.TestEndpoint(refreshOperation+" (reused token)", "POST", "/api/v1/auth/refresh",
+		types.RefreshTokenRequest{RefreshToken: exchanged})
+	result.Operation = refreshOperation
+	result.Category = lifecycleCategory
+	switch {
+	case result.StatusCode == 401:
+		result.Success = true
+		result.Error = ""
+		checkErrorBody(&result)
+		failIfAsserted(&result)
+	case result.Success:
+		result.Success = false
+		result.Error = fmt.Sprintf("HTTP %d: a rotated refresh token was accepted again", result.StatusCode)
+	default:
+		if result.StatusCode != 0 {
+			result.Error = fmt.Sprintf("HTTP %d: expected 401", result.StatusCode)
+		}
+	}
+	v.recordAndPrint(result)
+
+	// Reuse detection often revokes the whole token family; report which
+	// way the server went without failing either
+	after := v.client.TestEndpoint(refreshOperation, "POST", "/api/v1/auth/refresh",
+		types.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken})
+	if after.StatusCode == 401 {
+		fmt.Fprintln(v.out, colors.Info("   The reuse also revoked the newest refresh token"))
+	} else if after.Success {
+		fmt.Fprintln(v.out, colors.Info("   The newest refresh token still works after the reuse"))
+	}
+}
+
+// refreshTokens exchanges a refresh token and checks the RefreshResponse,
+// decoded into an AuthResponse whose RefreshToken is empty unless the
+// server rotated it. It returns nil if the exchange failed.
+func (v *Validator) refreshTokens(step, refreshToken string) (*types.AuthResponse, types.TestResult) {
+	result := v.client.TestEndpoint(refreshOperation+" ("+step+")", "POST", "/api/v1/auth/refresh",
+		types.RefreshTokenRequest{RefreshToken: refreshToken})
+	result.Operation = refreshOperation
+	result.Category = lifecycleCategory
+	if !result.Success {
+		return nil, result
+	}
+
+	var auth types.AuthResponse
+	if err := decodeResponse(result.Response, &auth); err != nil {
+		result.AssertionFailures = append(result.AssertionFailures, err.Error())
+		failIfAsserted(&result)
+		return nil, result
+	}
+	result.AssertionFailures = append(result.AssertionFailures, checkResponseType(result)...)
+	if auth.AccessToken == "" {
+		result.AssertionFailures = append(result.AssertionFailures, "accessToken: expected non-empty value")
+	}
+	if auth.ExpiresIn <= 0 {
+		result.AssertionFailures = append(result.AssertionFailures, fmt.Sprintf("expiresIn: expected a positive lifetime, got %d", auth.ExpiresIn))
+	}
+	if auth.TokenType != "Bearer" {
+		result.AssertionFailures = append(result.AssertionFailures, fmt.Sprintf("tokenType: expected %q, got %q", "Bearer", auth.TokenType))
+	}
+	result.AssertionFailures = append(result.AssertionFailures, checkTokenLifetime(auth)...)
+	failIfAsserted(&result)
+
+	if auth.AccessToken == "" {
+		return nil, result
+	}
+	return &auth, result
+}
+
+// checkTokenLifetime compares the exp claim of a JWT access token with
+// ExpiresIn. Opaque access tokens are not checked.
+func checkTokenLifetime(auth types.AuthResponse) []string {
+	claims, err := jwt.Decode(auth.AccessToken)
+	if err != nil || auth.ExpiresIn <= 0 {
+		return nil
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return []string{"accessToken: expected an exp claim"}
+	}
+
+	var failures []string
+	if iat, ok := claims["iat"].(float64); ok && exp-iat != float64(auth.ExpiresIn) {
+		failures = append(failures, fmt.Sprintf("accessToken: exp - iat is %vs, expiresIn is %ds", exp-iat, auth.ExpiresIn))
+	}
+	expected := time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+	if drift := time.Duration(math.Abs(exp-float64(expected.Unix()))) * time.Second; drift > lifetimeSkew {
+		failures = append(failures, fmt.Sprintf("accessToken: expires at %s, %s from now plus expiresIn (%ds)",
+			time.Unix(int64(exp), 0).UTC().Format(time.RFC3339), drift, auth.ExpiresIn))
+	}
+	return failures
+}
+
+// checkAccessToken reads the profile with a refreshed access token, which
+// must belong to the user the AuthResponse names
+func (v *Validator) checkAccessToken(auth *types.AuthResponse) types.TestResult {
+	config := *v.config
+	config.AuthToken = auth.AccessToken
+	apiClient := client.NewAPIClient(&config)
+
+	result := apiClient.TestEndpoint("GET /api/v1/user/profile (refreshed access token)", "GET", "/api/v1/user/profile", nil)
+	result.Operation = "GET /api/v1/user/profile"
+	result.Category = lifecycleCategory
+	if !result.Success {
+		return result
+	}
+
+	var profile types.UserProfile
+	if err := decodeResponse(result.Response, &profile); err != nil {
+		result.AssertionFailures = append(result.AssertionFailures, err.Error())
+	} else if auth.User != nil && profile.ID != auth.User.ID {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("id: expected the refreshed user %q, got %q", auth.User.ID, profile.ID))
+	}
+	failIfAsserted(&result)
+	return result
+}
@@ -0,0 +1,228 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/spec"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const (
+	modelCategory = "Models"
+
+	// modelConversationID tags /api/chat usage, which requires a conversation
+	modelConversationID = "validator-model-smoke"
+
+	// visionColor is the color of the image sent to vision models
+	visionColor = "red"
+)
+
+// Prompts sent to every model, kept short to limit battery usage
+const (
+	textPrompt   = "Reply with the single word: ready"
+	visionPrompt = "What color is this image? Answer with one word."
+	imagePrompt  = "Generate an image of a red circle on a white background."
+)
+
+// markdownImage matches an inline markdown image with a URL
+var markdownImage = regexp.MustCompile(`!\[[^\]]*\]\([^)\s]+\)`)
+
+// modelOutcome collects which capability checks a model passed
+type modelOutcome struct {
+	model  types.AIModel
+	passed []string
+	failed []string
+}
+
+// Send a minimal prompt to every model from /api/models, plus an image
+// prompt for vision models and an image generation prompt for image models
+func (v *Validator) runModelSuite() {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header("🤖", "Testing Models (/api/models):"))
+	fmt.Fprintln(v.out)
+
+	if !v.hasClerkAuth {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --clerk"))
+		return
+	}
+
+	models, err := v.clerkClient.ListModels()
+	if err != nil {
+		v.recordAndPrint(types.TestResult{Name: "GET /api/models", Operation: "GET /api/models", Category: modelCategory, Error: err.Error()})
+		return
+	}
+	fmt.Fprintf(v.out, "   %d model(s) advertised; every prompt is charged to the battery\n", len(models))
+
+	outcomes := make([]modelOutcome, len(models))
+	var tasks []task
+	for i, model := range models {
+		tasks = append(tasks, func(v *Validator) { outcomes[i] = v.checkModel(model) })
+	}
+	v.runTasks(tasks)
+
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.BoldText("Model summary:"))
+	for _, outcome := range outcomes {
+		name := fmt.Sprintf("%s/%s", outcome.model.Provider, outcome.model.ID)
+		if len(outcome.failed) == 0 {
+			fmt.Fprintf(v.out, "   %s %-40s %s\n", colors.Success("✅"), name, strings.Join(outcome.passed, ", "))
+			continue
+		}
+		summary := "failed: " + strings.Join(outcome.failed, ", ")
+		if len(outcome.passed) > 0 {
+			summary += " | passed: " + strings.Join(outcome.passed, ", ")
+		}
+		fmt.Fprintf(v.out, "   %s %-40s %s\n", colors.Error("❌"), name, summary)
+	}
+}
+
+// checkModel runs the capability checks one model advertises. Image
+// generation models get the image prompt instead of the text prompt, as
+// any prompt to them generates an image.
+func (v *Validator) checkModel(model types.AIModel) modelOutcome {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Subheader("🧠", model.Provider+" / "+model.ID+":"))
+
+	outcome := modelOutcome{model: model}
+	check := func(capability string, message types.ChatMessage, verify func(reply string) string) {
+		result := v.checkModelReply(model.ID, capability, message, verify)
+		v.recordAndPrint(result)
+		if result.Success {
+			outcome.passed = append(outcome.passed, capability)
+		} else {
+			outcome.failed = append(outcome.failed, capability)
+		}
+	}
+
+	if !model.SupportsImageGeneration {
+		check("text", types.ChatMessage{Role: "user", Content: textPrompt}, func(string) string { return "" })
+	}
+
+	if model.SupportsVision {
+		message := types.ChatMessage{Role: "user", Content: visionPrompt, Images: []string{visionImage}}
+		check("vision", message, func(reply string) string {
+			if !strings.Contains(strings.ToLower(reply), visionColor) {
+				return fmt.Sprintf("reply: expected the image color %q, got %q", visionColor, truncate(reply, 80))
+			}
+			return ""
+		})
+	}
+
+	if model.SupportsImageGeneration {
+		check("image generation", types.ChatMessage{Role: "user", Content: imagePrompt}, func(reply string) string {
+			if !hasGeneratedImage(reply) {
+				return fmt.Sprintf("reply: expected a generated image, got %q", truncate(reply, 80))
+			}
+			return ""
+		})
+	}
+
+	return outcome
+}
+
+// checkModelReply streams one message through /api/chat and verifies the
+// reply. verify returns an assertion failure or an empty string.
+func (v *Validator) checkModelReply(model, capability string, message types.ChatMessage, verify func(reply string) string) types.TestResult {
+	result := types.TestResult{
+		Name:      fmt.Sprintf("%s (%s)", model, capability),
+		Operation: "POST /api/chat",
+		Category:  modelCategory,
+	}
+
+	start := time.Now()
+	reply, err := streamChatReply(v.clerkClient, types.ChatRequest{
+		Model:          model,
+		Messages:       []types.ChatMessage{message},
+		ConversationID: modelConversationID,
+	})
+	result.Duration = time.Since(start)
+
+	var statusErr *client.StatusError
+	switch {
+	case errors.As(err, &statusErr):
+		result.StatusCode = statusErr.StatusCode
+		result.Error = err.Error()
+		return v.addAuthHint(result, spec.AuthClerk)
+	case err != nil:
+		result.Error = err.Error()
+		return result
+	}
+
+	result.StatusCode = 200
+	result.Response = map[string]interface{}{"reply": truncate(reply, 200)}
+	result.Success = true
+	if reply == "" {
+		result.AssertionFailures = append(result.AssertionFailures, "reply: stream ended without content")
+	} else if failure := verify(reply); failure != "" {
+		result.AssertionFailures = append(result.AssertionFailures, failure)
+	}
+	failIfAsserted(&result)
+
+	return result
+}
+
+// streamChatReply streams a chat request and returns the full reply
+func streamChatReply(apiClient *client.APIClient, req types.ChatRequest) (string, error) {
+	stream, err := apiClient.StreamChat(req)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
+
+	var reply strings.Builder
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			return reply.String(), nil
+		}
+		if err != nil {
+			return reply.String(), err
+		}
+		reply.WriteString(chunk.Text)
+	}
+}
+
+// hasGeneratedImage reports whether a reply carries an image: the
+// image_generation payload sent by image models, a data URL or a markdown
+// image
+func hasGeneratedImage(reply string) bool {
+	var payload struct {
+		Type   string `json:"type"`
+		URL    string `json:"url"`
+		Base64 string `json:"base64"`
+	}
+	if json.Unmarshal([]byte(strings.TrimSpace(reply)), &payload) == nil && payload.Type == "image_generation" {
+		return payload.URL != "" || payload.Base64 != ""
+	}
+	return strings.Contains(reply, "data:image/") || markdownImage.MatchString(reply)
+}
+
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n]) + "…"
+	}
+	return s
+}
+
+// visionImage is a solid visionColor PNG as a data URL
+var visionImage = func() string {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:], []byte{255, 0, 0, 255})
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}()
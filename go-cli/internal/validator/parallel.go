@@ -0,0 +1,79 @@
+package validator
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// task is an independent unit of checks. It prints and records through the
+// validator it is given, which is a private fork when run in parallel.
+type task func(v *Validator)
+
+// runTasks runs tasks on a pool of config.Parallel workers. Each task's
+// output and results are buffered and flushed in task order, so the run
+// reads the same as a serial one. A pool of one runs tasks inline.
+func (v *Validator) runTasks(tasks []task) {
+	workers := min(v.config.Parallel, len(tasks))
+	if workers <= 1 {
+		for _, t := range tasks {
+			t(v)
+		}
+		return
+	}
+
+	forks := make([]*Validator, len(tasks))
+	outputs := make([]*bytes.Buffer, len(tasks))
+	done := make([]chan struct{}, len(tasks))
+	for i := range tasks {
+		outputs[i] = &bytes.Buffer{}
+		forks[i] = v.fork(outputs[i])
+		done[i] = make(chan struct{})
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				tasks[i](forks[i])
+				close(done[i])
+			}
+		}()
+	}
+
+	go func() {
+		for i := range tasks {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	for i := range tasks {
+		<-done[i]
+		v.out.Write(outputs[i].Bytes())
+		for _, result := range forks[i].Results() {
+			v.record(result)
+		}
+	}
+	wg.Wait()
+}
+
+// fork returns a validator sharing v's clients and configuration that
+// prints to out and records its own results
+func (v *Validator) fork(out io.Writer) *Validator {
+	return &Validator{
+		client:       v.client,
+		clerkClient:  v.clerkClient,
+		jwtClient:    v.jwtClient,
+		secondClient: v.secondClient,
+		config:       v.config,
+		out:          out,
+		authMode:     v.authMode,
+		hasClerkAuth: v.hasClerkAuth,
+		hasJWTAuth:   v.hasJWTAuth,
+		openAPI:      v.openAPI,
+	}
+}
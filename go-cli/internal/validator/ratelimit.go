@@ -0,0 +1,331 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const (
+	rateLimitCategory = "Rate Limits"
+
+	// rateLimitBurstSlack is how many requests past the advertised limit
+	// are sent before giving up on seeing a 429
+	rateLimitBurstSlack = 5
+
+	// maxRateLimitWait bounds how long the suite waits for a window reset
+	maxRateLimitWait = 5 * time.Minute
+
+	// rateLimitResetMargin absorbs clock skew when waiting for a reset
+	rateLimitResetMargin = time.Second
+)
+
+// DefaultRateLimitEndpoints are probed when no endpoints are configured.
+// Uploads have a stricter limit than the other v1 routes.
+var DefaultRateLimitEndpoints = []string{
+	"GET /api/v1/conversations",
+	"POST /api/v1/upload",
+}
+
+// rateLimitProbe is one response's rate limit headers
+type rateLimitProbe struct {
+	status    int
+	limit     int
+	remaining int
+	reset     time.Time
+	sentAt    time.Time
+	errorBody string
+	missing   []string // headers that were absent or unparseable
+}
+
+// rateLimitObservation summarizes what was learned about one endpoint
+type rateLimitObservation struct {
+	endpoint string
+	limit    int
+	allowed  int // requests accepted in the window before the 429
+	window   time.Duration
+}
+
+// Burst requests at each endpoint and check the advertised rate limit
+// headers, the 429 at the limit and recovery after the window resets
+func (v *Validator) runRateLimitSuite() {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header("🚦", "Testing Rate Limits (V1 API):"))
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, "   Probes are sent without credentials: rate limiting runs before")
+	fmt.Fprintln(v.out, "   authentication, so no data is created. Each endpoint waits for")
+	fmt.Fprintln(v.out, "   its window to reset, which can take a minute or more.")
+
+	endpoints := v.config.RateLimitEndpoints
+	if len(endpoints) == 0 {
+		endpoints = DefaultRateLimitEndpoints
+	}
+
+	// Retrying would hide the very 429s under test
+	apiClient := v.client.WithoutRetries()
+
+	var observations []rateLimitObservation
+	for _, endpoint := range endpoints {
+		method, path, ok := strings.Cut(endpoint, " ")
+		if !ok {
+			method, path = "GET", endpoint
+		}
+		method = strings.ToUpper(method)
+
+		fmt.Fprintln(v.out)
+		fmt.Fprintln(v.out, colors.Subheader("⏱️", method+" "+path+":"))
+		if observation, ok := v.checkRateLimit(apiClient, method, path); ok {
+			observations = append(observations, observation)
+		}
+	}
+
+	if len(observations) > 0 {
+		fmt.Fprintln(v.out)
+		fmt.Fprintln(v.out, colors.BoldText("Observed limits:"))
+		for _, o := range observations {
+			fmt.Fprintf(v.out, "   %-32s limit %4d | accepted %4d | window %v\n", o.endpoint, o.limit, o.allowed, o.window.Round(time.Second))
+		}
+	}
+}
+
+// checkRateLimit runs the burst, limit and recovery checks for one
+// endpoint. The observation is only valid when ok is true.
+func (v *Validator) checkRateLimit(apiClient *client.APIClient, method, path string) (rateLimitObservation, bool) {
+	endpoint := method + " " + path
+	observation := rateLimitObservation{endpoint: endpoint}
+	newResult := func(check string) types.TestResult {
+		return types.TestResult{
+			Name:      fmt.Sprintf("%s (%s)", endpoint, check),
+			Operation: endpoint,
+			Category:  rateLimitCategory,
+			Success:   true,
+		}
+	}
+
+	// Start from a fresh window so the whole limit is observable
+	first := probeRateLimit(apiClient, method, path)
+	if first.status == http.StatusTooManyRequests {
+		if !v.waitForReset(first.reset) {
+			result := newResult("headers")
+			result.Success = false
+			result.StatusCode = first.status
+			result.Error = "already rate limited and the reset is too far away or unknown"
+			v.recordAndPrint(result)
+			return observation, false
+		}
+		first = probeRateLimit(apiClient, method, path)
+	}
+
+	// Burst until the 429, checking headers on every response
+	burst := newResult("remaining decreases")
+	burst.StatusCode = first.status
+	if first.status == 0 {
+		burst.Success = false
+		burst.Error = first.errorBody
+		v.recordAndPrint(burst)
+		return observation, false
+	}
+	if len(first.missing) > 0 {
+		burst.Success = false
+		burst.Error = "missing or invalid headers: " + strings.Join(first.missing, ", ")
+		v.recordAndPrint(burst)
+		return observation, false
+	}
+
+	start := time.Now()
+	observation.limit = first.limit
+	// Other requests may already have counted against this window
+	observation.allowed = first.limit - first.remaining
+	prev := first
+	var limited *rateLimitProbe
+	for i := 0; i < first.limit+rateLimitBurstSlack; i++ {
+		p := probeRateLimit(apiClient, method, path)
+		if p.status == http.StatusTooManyRequests {
+			limited = &p
+			break
+		}
+		if p.status == 0 {
+			burst.AssertionFailures = append(burst.AssertionFailures, fmt.Sprintf("request %d: %s", i+2, p.errorBody))
+			break
+		}
+		burst.AssertionFailures = append(burst.AssertionFailures, compareProbes(i+2, prev, p)...)
+		observation.allowed++
+		prev = p
+	}
+	burst.Duration = time.Since(start)
+	burst.Response = map[string]interface{}{"limit": first.limit, "accepted": observation.allowed}
+	failIfAsserted(&burst)
+	v.recordAndPrint(burst)
+
+	// The 429 must arrive right after Remaining hit zero
+	atLimit := newResult("429 at limit")
+	switch {
+	case limited == nil:
+		atLimit.AssertionFailures = append(atLimit.AssertionFailures,
+			fmt.Sprintf("no 429 after %d requests with a limit of %d", observation.allowed, first.limit))
+	default:
+		atLimit.StatusCode = limited.status
+		atLimit.AssertionFailures = append(atLimit.AssertionFailures, checkLimitedProbe(prev, *limited)...)
+		if observation.allowed != first.limit {
+			atLimit.AssertionFailures = append(atLimit.AssertionFailures,
+				fmt.Sprintf("429 after %d accepted requests in the window, limit is %d", observation.allowed, first.limit))
+		}
+	}
+	failIfAsserted(&atLimit)
+	v.recordAndPrint(atLimit)
+	if limited == nil {
+		return observation, false
+	}
+
+	// Access must resume once the window resets
+	resumed := newResult("resumes after reset")
+	if !v.waitForReset(limited.reset) {
+		resumed.Success = false
+		resumed.Error = fmt.Sprintf("reset %s is too far away to wait for", limited.reset.Format(time.RFC3339))
+		v.recordAndPrint(resumed)
+		return observation, false
+	}
+	after := probeRateLimit(apiClient, method, path)
+	resumed.StatusCode = after.status
+	switch {
+	case after.status == 0:
+		resumed.Success = false
+		resumed.Error = after.errorBody
+	case after.status == http.StatusTooManyRequests:
+		resumed.AssertionFailures = append(resumed.AssertionFailures, "still 429 after the advertised reset")
+	case len(after.missing) > 0:
+		resumed.AssertionFailures = append(resumed.AssertionFailures, "missing or invalid headers: "+strings.Join(after.missing, ", "))
+	default:
+		if after.remaining != after.limit-1 {
+			resumed.AssertionFailures = append(resumed.AssertionFailures,
+				fmt.Sprintf("X-RateLimit-Remaining: expected %d in a fresh window, got %d", after.limit-1, after.remaining))
+		}
+		if !after.reset.After(limited.reset) {
+			resumed.AssertionFailures = append(resumed.AssertionFailures,
+				fmt.Sprintf("X-RateLimit-Reset: expected a new window after %s, got %s",
+					limited.reset.Format(time.RFC3339), after.reset.Format(time.RFC3339)))
+		}
+		observation.window = after.reset.Sub(after.sentAt)
+	}
+	failIfAsserted(&resumed)
+	v.recordAndPrint(resumed)
+
+	return observation, resumed.Success
+}
+
+// waitForReset sleeps until reset has passed. It returns false when the
+// reset is unknown or further away than maxRateLimitWait.
+func (v *Validator) waitForReset(reset time.Time) bool {
+	if reset.IsZero() {
+		return false
+	}
+	wait := time.Until(reset) + rateLimitResetMargin
+	if wait > maxRateLimitWait {
+		return false
+	}
+	if wait > 0 {
+		fmt.Fprintf(v.out, "   ⏳ Waiting %v for the rate limit window to reset...\n", wait.Round(time.Second))
+		time.Sleep(wait)
+	}
+	return true
+}
+
+// compareProbes checks that consecutive responses in one window count
+// down by one and keep the same limit and reset
+func compareProbes(n int, prev, p rateLimitProbe) []string {
+	if len(p.missing) > 0 {
+		return []string{fmt.Sprintf("request %d: missing or invalid headers: %s", n, strings.Join(p.missing, ", "))}
+	}
+
+	var failures []string
+	if p.limit != prev.limit {
+		failures = append(failures, fmt.Sprintf("request %d: X-RateLimit-Limit changed from %d to %d", n, prev.limit, p.limit))
+	}
+	if p.remaining != prev.remaining-1 {
+		failures = append(failures, fmt.Sprintf("request %d: X-RateLimit-Remaining went from %d to %d", n, prev.remaining, p.remaining))
+	}
+	if !p.reset.Equal(prev.reset) {
+		failures = append(failures, fmt.Sprintf("request %d: X-RateLimit-Reset moved from %s to %s",
+			n, prev.reset.Format(time.RFC3339), p.reset.Format(time.RFC3339)))
+	}
+	return failures
+}
+
+// checkLimitedProbe validates a 429 against the last accepted response
+func checkLimitedProbe(prev, limited rateLimitProbe) []string {
+	var failures []string
+	if prev.remaining != 0 {
+		failures = append(failures, fmt.Sprintf("429 arrived while X-RateLimit-Remaining was %d", prev.remaining))
+	}
+	if len(limited.missing) > 0 {
+		return append(failures, "missing or invalid headers on the 429: "+strings.Join(limited.missing, ", "))
+	}
+	if limited.limit != prev.limit {
+		failures = append(failures, fmt.Sprintf("X-RateLimit-Limit: expected %d, got %d", prev.limit, limited.limit))
+	}
+	if limited.remaining != 0 {
+		failures = append(failures, fmt.Sprintf("X-RateLimit-Remaining: expected 0, got %d", limited.remaining))
+	}
+	if !limited.reset.After(limited.sentAt) {
+		failures = append(failures, fmt.Sprintf("X-RateLimit-Reset %s is not in the future", limited.reset.Format(time.RFC3339)))
+	} else if limited.reset.Sub(limited.sentAt) > maxRateLimitWait {
+		failures = append(failures, fmt.Sprintf("X-RateLimit-Reset %s is unreasonably far away", limited.reset.Format(time.RFC3339)))
+	}
+	if !limited.reset.Equal(prev.reset) {
+		failures = append(failures, fmt.Sprintf("X-RateLimit-Reset: expected the current window %s, got %s",
+			prev.reset.Format(time.RFC3339), limited.reset.Format(time.RFC3339)))
+	}
+	if limited.errorBody == "" {
+		failures = append(failures, "error: expected an ErrorResponse body")
+	}
+	return failures
+}
+
+// probeRateLimit sends one request and parses its rate limit headers
+func probeRateLimit(apiClient *client.APIClient, method, path string) rateLimitProbe {
+	p := rateLimitProbe{sentAt: time.Now()}
+
+	resp, err := apiClient.Request(method, path, nil)
+	if err != nil {
+		p.errorBody = err.Error()
+		return p
+	}
+	defer resp.Body.Close()
+	p.status = resp.StatusCode
+
+	if p.status == http.StatusTooManyRequests {
+		var body types.ErrorResponse
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &body) == nil {
+			p.errorBody = body.Error
+		}
+	} else {
+		io.Copy(io.Discard, resp.Body)
+	}
+
+	if limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit")); err == nil {
+		p.limit = limit
+	} else {
+		p.missing = append(p.missing, "X-RateLimit-Limit")
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		p.remaining = remaining
+	} else {
+		p.missing = append(p.missing, "X-RateLimit-Remaining")
+	}
+	if reset, err := time.Parse(time.RFC3339, resp.Header.Get("X-RateLimit-Reset")); err == nil {
+		p.reset = reset
+	} else {
+		p.missing = append(p.missing, "X-RateLimit-Reset")
+	}
+
+	return p
+}
@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/mock"
+)
+
+// replaySubscription fixes the subscription id of the billing flow, which is
+// otherwise new on every run and would not match a recorded run
+const replaySubscription = "sub_replay"
+
+func TestCassetteReplay(t *testing.T) {
+	server := newMockServer(t, mock.Options{})
+	recorder := client.NewRecorder(nil)
+	config := fullConfig(server.URL)
+	config.Webhook.SubscriptionID = replaySubscription
+	config.Transport = recorder
+	recorded := run(t, config)
+
+	path := filepath.Join(t.TempDir(), "run.cassette.json")
+	if err := recorder.Save(path, server.URL); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	cassette, err := client.LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cassette.Interactions) != recorder.Len() {
+		t.Errorf("cassette has %d interactions, recorded %d", len(cassette.Interactions), recorder.Len())
+	}
+	replayer := client.NewReplayer(cassette)
+	config = fullConfig(server.URL)
+	config.Webhook.SubscriptionID = replaySubscription
+	config.Transport = replayer
+	replayed := run(t, config)
+
+	if len(replayed) != len(recorded) {
+		t.Fatalf("replay has %d results, the recorded run %d", len(replayed), len(recorded))
+	}
+	for i := range recorded {
+		want, got := recorded[i], replayed[i]
+		if got.Name != want.Name || got.Success != want.Success || got.StatusCode != want.StatusCode {
+			t.Errorf("result %d: replayed %s (HTTP %d, success %t), recorded %s (HTTP %d, success %t): %s",
+				i, got.Name, got.StatusCode, got.Success, want.Name, want.StatusCode, want.Success, got.Error)
+		}
+	}
+	if n := replayer.Remaining(); n != 0 {
+		t.Errorf("%d recorded interactions were not replayed", n)
+	}
+}
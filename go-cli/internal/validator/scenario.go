@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omnichat/validator/internal/spec"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+// scenarioCategory groups scenario steps in the summary and reports
+const scenarioCategory = "Scenarios"
+
+// Run a scenario's steps in order, threading captured variables between
+// them, then run its cleanup steps regardless of the outcome
+func (v *Validator) runScenario(scenario spec.Scenario, fileVars map[string]string) {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header(scenario.Emoji, fmt.Sprintf("Scenario: %s", scenario.Name)))
+	fmt.Fprintln(v.out)
+
+	if reason := v.missingAuth(scenario.Auth); reason != "" {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: "+reason))
+		return
+	}
+
+	vars := make(map[string]string, len(fileVars))
+	for k, val := range fileVars {
+		vars[k] = val
+	}
+
+	defer v.runScenarioCleanup(scenario, vars)
+
+	for i, step := range scenario.Steps {
+		result := v.runScenarioStep(step, scenario.Auth, vars)
+		v.recordAndPrint(result)
+
+		if !result.Success {
+			if remaining := len(scenario.Steps) - i - 1; remaining > 0 {
+				fmt.Fprintln(v.out, colors.Warning(fmt.Sprintf("   Skipped %d remaining step(s)", remaining)))
+			}
+			return
+		}
+	}
+}
+
+// Run a scenario step and capture variables from its response
+func (v *Validator) runScenarioStep(step spec.TestCase, inheritedAuth string, vars map[string]string) types.TestResult {
+	// Never send a request to a path the scenario failed to fill in
+	if unresolved := spec.Unresolved(spec.Expand(step.Path, step.Params, vars)); len(unresolved) > 0 {
+		return types.TestResult{
+			Name:      step.DisplayName(),
+			Operation: strings.ToUpper(step.Method) + " " + step.Path,
+			Category:  scenarioCategory,
+			Error:     fmt.Sprintf("unresolved variables in path: %s", strings.Join(unresolved, ", ")),
+		}
+	}
+
+	result := v.runTestCase(step, inheritedAuth, vars)
+	result.Category = scenarioCategory
+
+	if result.Success && len(step.Capture) > 0 {
+		result.AssertionFailures = append(result.AssertionFailures, step.Captured(result.Response, vars)...)
+		failIfAsserted(&result)
+	}
+
+	return result
+}
+
+// Run cleanup steps whose variables were all captured
+func (v *Validator) runScenarioCleanup(scenario spec.Scenario, vars map[string]string) {
+	for _, step := range scenario.Cleanup {
+		if len(spec.Unresolved(spec.Expand(step.Path, step.Params, vars))) > 0 {
+			continue
+		}
+		result := v.runTestCase(step, scenario.Auth, vars)
+		result.Category = scenarioCategory
+		result.Name = "cleanup: " + result.Name
+		v.recordAndPrint(result)
+	}
+}
+
+// missingAuth describes why a scenario cannot run with the provided
+// tokens, or returns an empty string
+func (v *Validator) missingAuth(authKind string) string {
+	switch authKind {
+	case spec.AuthClerk:
+		if !v.hasClerkAuth {
+			return "requires --clerk"
+		}
+	case spec.AuthJWT:
+		if !v.hasJWTAuth {
+			return "requires --bearer"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,226 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/spec"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const (
+	// defaultTestModel is the model used for conversations the validator creates
+	defaultTestModel = "gpt-4o-mini"
+
+	// maxStreamEvents guards against streams that never terminate
+	maxStreamEvents = 10000
+)
+
+// Stream a reply into a throwaway v1 conversation and validate the events
+func (v *Validator) runStreamingTests() {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header("📡", "Testing Streaming (V1 Messages):"))
+	fmt.Fprintln(v.out)
+
+	if !v.hasJWTAuth {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --bearer"))
+		return
+	}
+
+	conversation, result := v.createV1Conversation(v.jwtClient, "Validator Streaming Test")
+	if conversation == nil {
+		v.recordAndPrint(result)
+		return
+	}
+	defer v.deleteV1Conversation(v.jwtClient, conversation.ID)
+
+	v.recordAndPrint(v.checkMessageStream(v.jwtClient, conversation.ID))
+}
+
+// checkMessageStream sends a streaming message and validates event order,
+// content consistency and error events
+func (v *Validator) checkMessageStream(apiClient *client.APIClient, conversationID string) types.TestResult {
+	result := types.TestResult{
+		Name:      "POST /api/v1/conversations/{id}/messages (stream)",
+		Operation: "POST /api/v1/conversations/{id}/messages",
+	}
+
+	start := time.Now()
+	stream, err := apiClient.StreamMessage(conversationID, types.V1MessageRequest{
+		Content: "Reply with a short greeting.",
+	})
+	if err != nil {
+		result.Duration = time.Since(start)
+		result.Error = err.Error()
+		var statusErr *client.StatusError
+		if errors.As(err, &statusErr) {
+			result.StatusCode = statusErr.StatusCode
+		}
+		return v.addAuthHint(result, spec.AuthJWT)
+	}
+	defer stream.Close()
+	result.StatusCode = 200
+
+	events, err := collectStreamEvents(stream)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.AssertionFailures = append(result.AssertionFailures, fmt.Sprintf("stream read failed: %v", err))
+	}
+
+	result.AssertionFailures = append(result.AssertionFailures, validateStreamEvents(events)...)
+	result.Response = summarizeStream(events)
+	result.Success = true
+	failIfAsserted(&result)
+
+	return result
+}
+
+// collectStreamEvents reads a message stream to completion
+func collectStreamEvents(stream *client.MessageStream) ([]types.StreamEvent, error) {
+	var events []types.StreamEvent
+	for len(events) < maxStreamEvents {
+		event, err := stream.Next()
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, err
+		}
+		events = append(events, event)
+	}
+	return events, fmt.Errorf("stream exceeded %d events", maxStreamEvents)
+}
+
+// validateStreamEvents checks that events follow message_start,
+// content_chunk*, message_complete, [DONE], that all events belong to the
+// same message, and that the chunks add up to the completed content.
+// Error events are always reported.
+func validateStreamEvents(events []types.StreamEvent) []string {
+	if len(events) == 0 {
+		return []string{"stream: no events received"}
+	}
+
+	var failures []string
+	var chunks strings.Builder
+	var messageID, completed string
+	started, complete, done := false, false, false
+
+	for i, event := range events {
+		if done {
+			failures = append(failures, fmt.Sprintf("event %d: %q received after [DONE]", i, event.Type))
+			continue
+		}
+
+		switch event.Type {
+		case types.StreamEventMessageStart:
+			if started {
+				failures = append(failures, fmt.Sprintf("event %d: duplicate message_start", i))
+			} else if i != 0 {
+				failures = append(failures, fmt.Sprintf("event %d: message_start is not the first event", i))
+			}
+			started = true
+			messageID = event.ID
+		case types.StreamEventContentChunk:
+			if !started || complete {
+				failures = append(failures, fmt.Sprintf("event %d: content_chunk outside message_start..message_complete", i))
+			}
+			chunks.WriteString(event.Content)
+		case types.StreamEventMessageComplete:
+			if !started {
+				failures = append(failures, fmt.Sprintf("event %d: message_complete before message_start", i))
+			}
+			if complete {
+				failures = append(failures, fmt.Sprintf("event %d: duplicate message_complete", i))
+			}
+			complete = true
+			completed = event.Content
+		case types.StreamEventError:
+			failures = append(failures, fmt.Sprintf("event %d: error event: %s", i, event.Error))
+			continue
+		case types.StreamEventDone:
+			if !complete {
+				failures = append(failures, fmt.Sprintf("event %d: [DONE] before message_complete", i))
+			}
+			done = true
+			continue
+		default:
+			failures = append(failures, fmt.Sprintf("event %d: unknown event type %q", i, event.Type))
+			continue
+		}
+
+		if messageID != "" && event.ID != messageID {
+			failures = append(failures, fmt.Sprintf("event %d: id %q does not match message id %q", i, event.ID, messageID))
+		}
+	}
+
+	if !started {
+		failures = append(failures, "stream: missing message_start")
+	}
+	if !complete {
+		failures = append(failures, "stream: missing message_complete")
+	} else if chunks.String() != completed {
+		failures = append(failures, fmt.Sprintf("stream: concatenated chunks (%d chars) differ from message_complete content (%d chars)",
+			len(chunks.String()), len(completed)))
+	}
+	if !done {
+		failures = append(failures, "stream: missing [DONE]")
+	}
+
+	return failures
+}
+
+// summarizeStream condenses events into a response summary for reports
+func summarizeStream(events []types.StreamEvent) map[string]interface{} {
+	counts := make(map[string]int)
+	content := ""
+	for _, event := range events {
+		counts[event.Type]++
+		if event.Type == types.StreamEventMessageComplete {
+			content = event.Content
+		}
+	}
+	return map[string]interface{}{
+		"events":  len(events),
+		"byType":  counts,
+		"content": content,
+	}
+}
+
+// createV1Conversation creates a conversation for stateful checks. On
+// failure the conversation is nil and the result describes the error.
+func (v *Validator) createV1Conversation(apiClient *client.APIClient, title string) (*types.Conversation, types.TestResult) {
+	result := apiClient.TestEndpoint("POST /api/v1/conversations", "POST", "/api/v1/conversations",
+		types.ConversationRequest{Title: title, Model: defaultTestModel})
+	result.Operation = "POST /api/v1/conversations"
+	if !result.Success {
+		return nil, result
+	}
+
+	var conversation types.Conversation
+	if err := decodeResponse(result.Response, &conversation); err != nil {
+		result.AssertionFailures = append(result.AssertionFailures, err.Error())
+	} else {
+		result.AssertionFailures = append(result.AssertionFailures, checkResponseType(result)...)
+		if conversation.ID == "" {
+			result.AssertionFailures = append(result.AssertionFailures, "id: expected non-empty value")
+		}
+	}
+	failIfAsserted(&result)
+	if !result.Success {
+		return nil, result
+	}
+
+	return &conversation, result
+}
+
+// deleteV1Conversation removes a conversation created by the validator
+func (v *Validator) deleteV1Conversation(apiClient *client.APIClient, id string) types.TestResult {
+	result := apiClient.TestEndpoint("DELETE /api/v1/conversations/{id}", "DELETE", "/api/v1/conversations/"+id, nil)
+	result.Operation = "DELETE /api/v1/conversations/{id}"
+	return result
+}
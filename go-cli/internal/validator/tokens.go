@@ -0,0 +1,203 @@
+package validator
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/omnichat/validator/internal/jwt"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const tokenCategory = "Token Validation"
+
+// The v1 endpoint every token case is sent to
+const (
+	tokenProbePath      = "/api/v1/conversations"
+	tokenProbeOperation = "GET /api/v1/conversations"
+)
+
+// Values that make an otherwise valid token invalid
+const (
+	tokenWrongSecret   = "validator-wrong-secret"
+	tokenWrongAudience = "validator-wrong-audience"
+)
+
+// tokenCase is an Authorization header the API must reject
+type tokenCase struct {
+	name string
+	// header is the Authorization value, empty to send none
+	header string
+	// needsSecret marks tokens signed with --jwt-secret, which only prove
+	// anything once a valid token signed with it is accepted
+	needsSecret bool
+	// knownGap, if set, is why the API accepts the token today. Such a case
+	// is recorded as a failure marked with the gap, which does not fail the
+	// run.
+	knownGap string
+}
+
+// Mint missing, malformed, expired, forged and unsigned tokens and check
+// every v1 request carrying one gets a 401 with an error body. A
+// wrong-audience token is sent too and reported as a known gap.
+func (v *Validator) runTokenRejectionSuite() {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header("🔑", "Testing Token Rejection ("+tokenProbePath+"):"))
+	fmt.Fprintln(v.out)
+
+	userID, email := v.tokenSubject()
+	secret := v.config.JWTSecret
+	if secret == "" {
+		secret = jwt.DevSecret
+	}
+
+	cases, err := tokenCases(userID, email, secret)
+	if err != nil {
+		v.recordAndPrint(types.TestResult{Name: "mint tokens", Category: tokenCategory, Error: err.Error()})
+		return
+	}
+
+	// A valid token signed with the secret tells whether the expired and
+	// wrong-audience tokens are rejected for their claims or their signature
+	valid, err := jwt.SignHS256(jwt.AccessClaims(userID, email, 15*time.Minute), secret)
+	if err != nil {
+		v.recordAndPrint(types.TestResult{Name: "mint tokens", Category: tokenCategory, Error: err.Error()})
+		return
+	}
+	control := v.client.TestEndpointWithHeader("control", "GET", tokenProbePath, nil, authorization("Bearer "+valid))
+	secretAccepted := control.Success
+	if !secretAccepted {
+		fmt.Fprintln(v.out, colors.Warning(fmt.Sprintf(
+			"   Skipping expired and wrong audience tokens: a valid token signed with --jwt-secret got HTTP %d", control.StatusCode)))
+	}
+
+	for _, c := range cases {
+		if c.needsSecret && !secretAccepted {
+			continue
+		}
+		if c.knownGap != "" {
+			v.recordAndPrint(v.checkKnownGap(c))
+			continue
+		}
+		v.recordAndPrint(v.checkTokenRejected(c))
+	}
+}
+
+// tokenSubject returns the user the minted tokens are issued to: the
+// --bearer user if the profile can be read, so only the broken part of
+// each token is wrong
+func (v *Validator) tokenSubject() (string, string) {
+	if profile, ok := v.bearerProfile(); ok {
+		return profile.ID, profile.Email
+	}
+	return "validator-user", "validator@example.com"
+}
+
+// bearerProfile reads the profile of the --bearer user
+func (v *Validator) bearerProfile() (types.UserProfile, bool) {
+	var profile types.UserProfile
+	if !v.hasJWTAuth {
+		return profile, false
+	}
+	result := v.jwtClient.TestEndpoint("GET /api/v1/user/profile", "GET", "/api/v1/user/profile", nil)
+	ok := result.Success && decodeResponse(result.Response, &profile) == nil && profile.ID != ""
+	return profile, ok
+}
+
+// tokenCases mints a token for every way a token can be wrong
+func tokenCases(userID, email, secret string) ([]tokenCase, error) {
+	expired := jwt.AccessClaims(userID, email, -time.Hour)
+	expired["iat"] = time.Now().Add(-2 * time.Hour).Unix()
+	expiredToken, err := jwt.SignHS256(expired, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	forgedToken, err := jwt.SignHS256(jwt.AccessClaims(userID, email, 15*time.Minute), tokenWrongSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	unsignedToken, err := jwt.Unsigned(jwt.AccessClaims(userID, email, 15*time.Minute))
+	if err != nil {
+		return nil, err
+	}
+
+	audience := jwt.AccessClaims(userID, email, 15*time.Minute)
+	audience["aud"] = tokenWrongAudience
+	audienceToken, err := jwt.SignHS256(audience, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return []tokenCase{
+		{name: "missing token"},
+		{name: "wrong scheme", header: "Basic dmFsaWRhdG9yOnZhbGlkYXRvcg=="},
+		{name: "malformed token", header: "Bearer not-a-jwt"},
+		{name: "expired token", header: "Bearer " + expiredToken, needsSecret: true},
+		{name: "wrong signature", header: "Bearer " + forgedToken},
+		{name: "alg none", header: "Bearer " + unsignedToken},
+		{name: "wrong audience", header: "Bearer " + audienceToken, needsSecret: true,
+			knownGap: "verifyToken does not check the aud claim"},
+	}, nil
+}
+
+// checkTokenRejected sends one token case and expects a 401 whose body is
+// an ErrorResponse with a message
+func (v *Validator) checkTokenRejected(c tokenCase) types.TestResult {
+	var header http.Header
+	if c.header != "" {
+		header = authorization(c.header)
+	}
+	result := v.client.TestEndpointWithHeader(tokenProbeOperation+" ("+c.name+")", "GET", tokenProbePath, nil, header)
+	result.Operation = tokenProbeOperation
+	result.Category = tokenCategory
+
+	switch {
+	case result.StatusCode == 401:
+		result.Success = true
+		result.Error = ""
+	case result.StatusCode >= 200 && result.StatusCode < 300:
+		result.Success = false
+		result.Error = fmt.Sprintf("HTTP %d: the %s was accepted", result.StatusCode, c.name)
+		return result
+	default:
+		result.Success = false
+		if result.StatusCode != 0 {
+			result.Error = fmt.Sprintf("HTTP %d: expected 401", result.StatusCode)
+		}
+		return result
+	}
+
+	checkErrorBody(&result)
+	failIfAsserted(&result)
+	return result
+}
+
+// checkKnownGap sends a token the API is known to accept. Accepting it
+// is a failure marked with the known gap; a 401 passes, as the gap is closed.
+func (v *Validator) checkKnownGap(c tokenCase) types.TestResult {
+	result := v.checkTokenRejected(c)
+	if result.StatusCode >= 200 && result.StatusCode < 300 {
+		result.KnownGap = c.knownGap
+	}
+	return result
+}
+
+// checkErrorBody asserts a response is an ErrorResponse with a message
+func checkErrorBody(result *types.TestResult) {
+	result.AssertionFailures = append(result.AssertionFailures,
+		checkType(result.Response, reflect.TypeFor[types.ErrorResponse]())...)
+	var body types.ErrorResponse
+	if len(result.AssertionFailures) == 0 && decodeResponse(result.Response, &body) == nil && body.Error == "" {
+		result.AssertionFailures = append(result.AssertionFailures, "error: expected a message, got an empty string")
+	}
+}
+
+func authorization(value string) http.Header {
+	header := http.Header{}
+	header.Set("Authorization", value)
+	return header
+}
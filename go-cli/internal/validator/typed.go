@@ -0,0 +1,269 @@
+package validator
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/omnichat/validator/internal/spec"
+	"github.com/omnichat/validator/internal/types"
+)
+
+// responseTypes maps an operation to the type its successful response
+// decodes into. Streams, downloads and documents are not listed.
+var responseTypes = map[string]reflect.Type{
+	"GET /api/config":    reflect.TypeFor[types.ConfigResponse](),
+	"GET /api/models":    reflect.TypeFor[types.ModelsResponse](),
+	"GET /api/search":    reflect.TypeFor[types.SearchResponse](),
+	"GET /api/battery":   reflect.TypeFor[types.BatteryResponse](),
+	"GET /api/user/tier": reflect.TypeFor[types.UserTierResponse](),
+
+	"POST /api/v1/auth/apple":   reflect.TypeFor[types.AuthResponse](),
+	"POST /api/v1/auth/refresh": reflect.TypeFor[types.RefreshResponse](),
+
+	"GET /api/conversations":                  reflect.TypeFor[types.ConversationsResponse](),
+	"POST /api/conversations":                 reflect.TypeFor[types.Conversation](),
+	"DELETE /api/conversations/{id}":          reflect.TypeFor[types.SuccessResponse](),
+	"GET /api/conversations/{id}/messages":    reflect.TypeFor[types.StoredMessagesResponse](),
+	"POST /api/conversations/{id}/messages":   reflect.TypeFor[types.StoredMessageResponse](),
+	"GET /api/v1/conversations":               reflect.TypeFor[types.ConversationsResponse](),
+	"POST /api/v1/conversations":              reflect.TypeFor[types.Conversation](),
+	"GET /api/v1/conversations/{id}":          reflect.TypeFor[types.ConversationDetail](),
+	"PATCH /api/v1/conversations/{id}":        reflect.TypeFor[types.ConversationUpdateResponse](),
+	"DELETE /api/v1/conversations/{id}":       reflect.TypeFor[types.SuccessResponse](),
+	"GET /api/v1/conversations/{id}/messages": reflect.TypeFor[types.MessagesResponse](),
+
+	// Only sent as JSON with "stream": false
+	"POST /api/v1/conversations/{id}/messages": reflect.TypeFor[types.SentMessage](),
+
+	"POST /api/upload":    reflect.TypeFor[types.UploadResponse](),
+	"POST /api/v1/upload": reflect.TypeFor[types.Attachment](),
+
+	"GET /api/v1/user/profile":   reflect.TypeFor[types.UserProfile](),
+	"PATCH /api/v1/user/profile": reflect.TypeFor[types.UserProfileUpdateResponse](),
+	"GET /api/v1/user/usage":     reflect.TypeFor[types.UserUsageResponse](),
+
+	"GET /api/stripe/checkout":  reflect.TypeFor[types.SubscriptionStatusResponse](),
+	"POST /api/stripe/checkout": reflect.TypeFor[types.CheckoutResponse](),
+	"POST /api/stripe/portal":   reflect.TypeFor[types.BillingPortalResponse](),
+}
+
+// responseType looks up the response type of an operation, ignoring the
+// query string and the names of path parameters
+func responseType(operation string) (reflect.Type, bool) {
+	operation = normalizeOperation(operation)
+	for declared, t := range responseTypes {
+		if normalizeOperation(declared) == operation {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// checkResponseType decodes a 2xx response into the type declared for its
+// operation and reports every unknown field, missing required field and
+// type mismatch. A field is required unless it is tagged omitempty; null
+// is only accepted for pointers, slices and maps.
+func checkResponseType(result types.TestResult) []string {
+	t, ok := responseType(result.Operation)
+	if !ok || result.StatusCode < 200 || result.StatusCode >= 300 {
+		return nil
+	}
+	return checkType(result.Response, t)
+}
+
+// checkType reports how a decoded response differs from type t
+func checkType(response interface{}, t reflect.Type) []string {
+	if _, isString := response.(string); isString {
+		return []string{fmt.Sprintf("$: expected a JSON %s response, got a non-JSON body", t.Name())}
+	}
+
+	c := typeChecker{seen: make(map[string]bool)}
+	c.walk("", "", response, t)
+
+	// The walk accepts everything the decoder does, so this only fails on
+	// values it cannot represent, such as integers that overflow
+	if len(c.failures) == 0 {
+		if err := decodeResponse(response, reflect.New(t).Interface()); err != nil {
+			c.failures = append(c.failures, err.Error())
+		}
+	}
+	return c.failures
+}
+
+// typeChecker walks a decoded JSON value alongside a Go type. Array
+// elements are reported once per field, at the first index that fails,
+// so a list of conversations with the same problem yields one failure.
+type typeChecker struct {
+	failures []string
+	seen     map[string]bool // failures keyed by path without array indexes
+}
+
+// jsonField is a struct field as encoding/json sees it
+type jsonField struct {
+	name      string
+	t         reflect.Type
+	omitEmpty bool
+}
+
+func (c *typeChecker) fail(path, generic, format string, args ...interface{}) {
+	if path == "" {
+		path = "$"
+	}
+	message := fmt.Sprintf(format, args...)
+	if key := generic + ": " + message; !c.seen[key] {
+		c.seen[key] = true
+		c.failures = append(c.failures, path+": "+message)
+	}
+}
+
+// walk checks value against t. generic is path with array indexes
+// replaced by "*", used to report each field once.
+func (c *typeChecker) walk(path, generic string, value interface{}, t reflect.Type) {
+	if t.Kind() == reflect.Pointer {
+		if value == nil {
+			return
+		}
+		t = t.Elem()
+	}
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Interface:
+		default:
+			c.fail(path, generic, "expected type %s, got null", jsonType(t))
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			c.fail(path, generic, "expected type object, got %s", spec.TypeOf(value))
+			return
+		}
+		fields := jsonFields(t)
+		known := make(map[string]bool, len(fields))
+		for _, field := range fields {
+			known[field.name] = true
+			fieldValue, present := object[field.name]
+			if !present {
+				if !field.omitEmpty {
+					c.fail(join(path, field.name), join(generic, field.name), "missing field")
+				}
+				continue
+			}
+			c.walk(join(path, field.name), join(generic, field.name), fieldValue, field.t)
+		}
+
+		var unknown []string
+		for name := range object {
+			if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+		slices.Sort(unknown)
+		for _, name := range unknown {
+			c.fail(join(path, name), join(generic, name), "unknown field")
+		}
+
+	case reflect.Slice:
+		array, ok := value.([]interface{})
+		if !ok {
+			c.fail(path, generic, "expected type array, got %s", spec.TypeOf(value))
+			return
+		}
+		for i, element := range array {
+			c.walk(join(path, strconv.Itoa(i)), join(generic, "*"), element, t.Elem())
+		}
+
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			c.fail(path, generic, "expected type object, got %s", spec.TypeOf(value))
+			return
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			c.walk(join(path, key), join(generic, "*"), object[key], t.Elem())
+		}
+
+	case reflect.Interface:
+		// Any JSON value
+
+	default:
+		if actual := spec.TypeOf(value); actual != jsonType(t) {
+			c.fail(path, generic, "expected type %s, got %s", jsonType(t), actual)
+		} else if n, ok := value.(float64); ok && isInteger(t) && n != math.Trunc(n) {
+			c.fail(path, generic, "expected an integer, got %v", n)
+		}
+	}
+}
+
+// jsonFields lists the fields encoding/json maps for a struct type,
+// flattening embedded structs
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(f.Type)...)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{
+			name:      name,
+			t:         f.Type,
+			omitEmpty: slices.Contains(strings.Split(options, ","), "omitempty"),
+		})
+	}
+	return fields
+}
+
+// jsonType names the JSON type a Go kind decodes from, in spec.TypeOf terms
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	if isInteger(t) || t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64 {
+		return "number"
+	}
+	return t.Kind().String()
+}
+
+func isInteger(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func join(path, segment string) string {
+	if path == "" {
+		return segment
+	}
+	return path + "." + segment
+}
@@ -0,0 +1,123 @@
+package validator
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/omnichat/validator/internal/types"
+)
+
+type typedItem struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+type typedBase struct {
+	CreatedAt string `json:"createdAt"`
+}
+
+type typedResponse struct {
+	typedBase
+	Name     string            `json:"name"`
+	Nickname *string           `json:"nickname"`
+	Note     string            `json:"note,omitempty"`
+	Items    []typedItem       `json:"items"`
+	Labels   map[string]string `json:"labels"`
+	Extra    interface{}       `json:"extra,omitempty"`
+}
+
+func TestCheckType(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "matching",
+			body: `{"createdAt":"2024-01-01","name":"a","nickname":"b","note":"c","items":[{"id":"1","count":2}],"labels":{"k":"v"},"extra":[1]}`,
+		},
+		{
+			name: "nulls for pointers, slices and maps, optional fields left out",
+			body: `{"createdAt":"2024-01-01","name":"a","nickname":null,"items":null,"labels":null}`,
+		},
+		{
+			name: "missing required fields",
+			body: `{"name":"a","items":[]}`,
+			want: []string{"createdAt: missing field", "nickname: missing field", "labels: missing field"},
+		},
+		{
+			name: "null for a string",
+			body: `{"createdAt":null,"name":"a","nickname":null,"items":[],"labels":{}}`,
+			want: []string{"createdAt: expected type string, got null"},
+		},
+		{
+			name: "unknown fields in order",
+			body: `{"createdAt":"","name":"a","nickname":null,"items":[],"labels":{},"zeta":1,"alpha":2}`,
+			want: []string{"alpha: unknown field", "zeta: unknown field"},
+		},
+		{
+			name: "array elements reported once per field",
+			body: `{"createdAt":"","name":"a","nickname":null,"items":[{"id":"1","count":1.5},{"id":2,"count":"x"},{"id":3,"count":"y"}],"labels":{}}`,
+			want: []string{"items.0.count: expected an integer, got 1.5", "items.1.id: expected type string, got number", "items.1.count: expected type number, got string"},
+		},
+		{
+			name: "map values",
+			body: `{"createdAt":"","name":"a","nickname":null,"items":[],"labels":{"a":"x","b":2}}`,
+			want: []string{"labels.b: expected type string, got number"},
+		},
+		{
+			name: "array instead of object",
+			body: `[]`,
+			want: []string{"$: expected type object, got array"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var response interface{}
+			if err := json.Unmarshal([]byte(tt.body), &response); err != nil {
+				t.Fatal(err)
+			}
+			got := checkType(response, reflect.TypeFor[typedResponse]())
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("checkType =\n  %q\nwant\n  %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTypeNonJSON(t *testing.T) {
+	got := checkType("<html>", reflect.TypeFor[typedResponse]())
+	want := []string{"$: expected a JSON typedResponse response, got a non-JSON body"}
+	if !slices.Equal(got, want) {
+		t.Errorf("checkType = %q, want %q", got, want)
+	}
+}
+
+func TestCheckResponseType(t *testing.T) {
+	var refresh interface{}
+	if err := json.Unmarshal([]byte(`{"accessToken":"a","expiresIn":3600,"tokenType":"Bearer","user":{"id":"u","email":"e"}}`), &refresh); err != nil {
+		t.Fatal(err)
+	}
+
+	result := types.TestResult{Operation: "POST /api/v1/auth/refresh", StatusCode: 200, Response: refresh}
+	if got := checkResponseType(result); len(got) != 0 {
+		t.Errorf("refresh without a refresh token: %q", got)
+	}
+
+	result.Operation = "POST /api/v1/auth/apple"
+	if got := checkResponseType(result); !slices.Equal(got, []string{"refreshToken: missing field"}) {
+		t.Errorf("sign in without a refresh token: %q", got)
+	}
+
+	result.StatusCode = 401
+	if got := checkResponseType(result); len(got) != 0 {
+		t.Errorf("error responses are not typed, got %q", got)
+	}
+	// /api/user/tier only sends the tier
+	result = types.TestResult{Operation: "GET /api/user/tier", StatusCode: 200, Response: map[string]interface{}{"tier": "paid"}}
+	if got := checkResponseType(result); len(got) != 0 {
+		t.Errorf("tier: %q", got)
+	}
+}
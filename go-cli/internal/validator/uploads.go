@@ -0,0 +1,272 @@
+package validator
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// uploadConversationID scopes validator uploads in the storage key
+const uploadConversationID = "validator-uploads"
+
+// uploadFixture is an embedded file uploaded during round-trip checks
+type uploadFixture struct {
+	fileName    string
+	contentType string
+}
+
+var uploadFixtures = []uploadFixture{
+	{fileName: "sample.txt", contentType: "text/plain"},
+	{fileName: "sample.png", contentType: "image/png"},
+	{fileName: "sample.pdf", contentType: "application/pdf"},
+}
+
+// Upload each fixture and download it back, comparing the bytes
+func (v *Validator) runUploadRoundTrips() {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header("📤", "Testing File Upload Round-Trips:"))
+	fmt.Fprintln(v.out)
+
+	if !v.hasJWTAuth && !v.hasClerkAuth {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --bearer and/or --clerk"))
+		return
+	}
+
+	if v.hasJWTAuth {
+		fmt.Fprintln(v.out, colors.Subheader("📁", "Files V1:"))
+		for _, fixture := range uploadFixtures {
+			v.roundTripV1(fixture)
+		}
+	}
+
+	if v.hasClerkAuth {
+		if v.hasJWTAuth {
+			fmt.Fprintln(v.out)
+		}
+		fmt.Fprintln(v.out, colors.Subheader("📁", "Files:"))
+		for _, fixture := range uploadFixtures {
+			v.roundTripClerk(fixture)
+		}
+	}
+}
+
+// Upload via /api/v1/upload, assert the Attachment, download it via
+// /api/v1/files/{key} and delete it again
+func (v *Validator) roundTripV1(fixture uploadFixture) {
+	data, err := fixtures.ReadFile("fixtures/" + fixture.fileName)
+	if err != nil {
+		v.recordAndPrint(types.TestResult{Name: "POST /api/v1/upload [" + fixture.fileName + "]", Error: err.Error()})
+		return
+	}
+
+	fields := map[string]string{"conversationId": uploadConversationID}
+	result := v.jwtClient.TestMultipart("POST /api/v1/upload ["+fixture.fileName+"]", "POST", "/api/v1/upload",
+		fields, []client.FilePart{fixture.part(data)})
+	result.Operation = "POST /api/v1/upload"
+
+	var attachment types.Attachment
+	if result.Success {
+		if err := decodeResponse(result.Response, &attachment); err != nil {
+			result.AssertionFailures = append(result.AssertionFailures, err.Error())
+		} else {
+			result.AssertionFailures = append(result.AssertionFailures, checkResponseType(result)...)
+			result.AssertionFailures = append(result.AssertionFailures, checkAttachment(attachment, fixture, len(data))...)
+		}
+		failIfAsserted(&result)
+	}
+	v.recordAndPrint(result)
+
+	if !result.Success || attachment.Key == "" {
+		return
+	}
+
+	filePath := "/api/v1/files/" + escapeKey(attachment.Key)
+	v.recordAndPrint(v.downloadAndCompare(v.jwtClient,
+		"GET /api/v1/files/{key} ["+fixture.fileName+"]", "GET /api/v1/files/{key}", filePath, data, ""))
+
+	result = v.jwtClient.TestEndpoint("DELETE /api/v1/files/{key} ["+fixture.fileName+"]", "DELETE", filePath, nil)
+	result.Operation = "DELETE /api/v1/files/{key}"
+	v.recordAndPrint(result)
+}
+
+// Upload via /api/upload, assert the FileAttachment and download it via
+// /api/upload?key=. The file stays: the web app has no route to delete it.
+func (v *Validator) roundTripClerk(fixture uploadFixture) {
+	data, err := fixtures.ReadFile("fixtures/" + fixture.fileName)
+	if err != nil {
+		v.recordAndPrint(types.TestResult{Name: "POST /api/upload [" + fixture.fileName + "]", Error: err.Error()})
+		return
+	}
+
+	fields := map[string]string{
+		"conversationId": uploadConversationID,
+		"messageId":      fmt.Sprintf("validator-%d", time.Now().UnixNano()),
+	}
+	result := v.clerkClient.TestMultipart("POST /api/upload ["+fixture.fileName+"]", "POST", "/api/upload",
+		fields, []client.FilePart{fixture.part(data)})
+	result.Operation = "POST /api/upload"
+
+	var upload types.UploadResponse
+	if result.Success {
+		if err := decodeResponse(result.Response, &upload); err != nil {
+			result.AssertionFailures = append(result.AssertionFailures, err.Error())
+		} else {
+			result.AssertionFailures = append(result.AssertionFailures, checkResponseType(result)...)
+			result.AssertionFailures = append(result.AssertionFailures, checkFileAttachment(upload, fixture, len(data))...)
+		}
+		failIfAsserted(&result)
+	}
+	v.recordAndPrint(result)
+
+	if !result.Success || upload.Attachment == nil || upload.Attachment.R2Key == "" {
+		return
+	}
+
+	// Images may be re-encoded server-side, which changes the stored extension
+	skipReason := ""
+	if path.Ext(upload.Attachment.R2Key) != path.Ext(fixture.fileName) {
+		skipReason = fmt.Sprintf("server re-encoded file as %s", path.Ext(upload.Attachment.R2Key))
+	}
+
+	v.recordAndPrint(v.downloadAndCompare(v.clerkClient,
+		"GET /api/upload?key= ["+fixture.fileName+"]", "GET /api/upload",
+		"/api/upload?key="+url.QueryEscape(upload.Attachment.R2Key), data, skipReason))
+}
+
+// downloadAndCompare fetches path and compares the body with expected.
+// A non-empty skipReason downgrades the comparison to a status check.
+func (v *Validator) downloadAndCompare(apiClient *client.APIClient, name, operation, path string, expected []byte, skipReason string) types.TestResult {
+	start := time.Now()
+	resp, err := apiClient.Get(path)
+	duration := time.Since(start)
+
+	result := types.TestResult{Name: name, Operation: operation, Duration: duration}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read response: %v", err)
+		return result
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Error = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return result
+	}
+
+	result.Success = true
+	if skipReason != "" {
+		result.Response = fmt.Sprintf("byte comparison skipped: %s", skipReason)
+		return result
+	}
+
+	if !bytes.Equal(body, expected) {
+		result.AssertionFailures = append(result.AssertionFailures, describeByteMismatch(expected, body))
+	}
+	failIfAsserted(&result)
+	return result
+}
+
+// part wraps fixture data as a multipart file part
+func (f uploadFixture) part(data []byte) client.FilePart {
+	return client.FilePart{
+		FieldName:   "file",
+		FileName:    f.fileName,
+		ContentType: f.contentType,
+		Reader:      bytes.NewReader(data),
+	}
+}
+
+func checkAttachment(a types.Attachment, fixture uploadFixture, size int) []string {
+	var failures []string
+	if a.ID == "" {
+		failures = append(failures, "id: expected non-empty value")
+	}
+	if a.Key == "" {
+		failures = append(failures, "key: expected non-empty value")
+	}
+	if a.URL == "" {
+		failures = append(failures, "url: expected non-empty value")
+	}
+	if a.FileName != fixture.fileName {
+		failures = append(failures, fmt.Sprintf("fileName: expected %q, got %q", fixture.fileName, a.FileName))
+	}
+	if a.FileType != fixture.contentType {
+		failures = append(failures, fmt.Sprintf("fileType: expected %q, got %q", fixture.contentType, a.FileType))
+	}
+	if a.FileSize != size {
+		failures = append(failures, fmt.Sprintf("fileSize: expected %d, got %d", size, a.FileSize))
+	}
+	return failures
+}
+
+func checkFileAttachment(upload types.UploadResponse, fixture uploadFixture, size int) []string {
+	if !upload.Success {
+		return []string{"success: expected true"}
+	}
+	a := upload.Attachment
+	if a == nil {
+		return []string{"attachment: missing field"}
+	}
+
+	var failures []string
+	if a.ID == "" {
+		failures = append(failures, "attachment.id: expected non-empty value")
+	}
+	if a.R2Key == "" {
+		failures = append(failures, "attachment.r2Key: expected non-empty value")
+	}
+	if a.ConversationID != uploadConversationID {
+		failures = append(failures, fmt.Sprintf("attachment.conversationId: expected %q, got %q", uploadConversationID, a.ConversationID))
+	}
+	if a.FileName != fixture.fileName {
+		failures = append(failures, fmt.Sprintf("attachment.fileName: expected %q, got %q", fixture.fileName, a.FileName))
+	}
+	if a.MimeType != fixture.contentType {
+		failures = append(failures, fmt.Sprintf("attachment.mimeType: expected %q, got %q", fixture.contentType, a.MimeType))
+	}
+	if a.FileSize != size {
+		failures = append(failures, fmt.Sprintf("attachment.fileSize: expected %d, got %d", size, a.FileSize))
+	}
+	return failures
+}
+
+// describeByteMismatch reports where two byte slices first differ
+func describeByteMismatch(expected, actual []byte) string {
+	n := len(expected)
+	if len(actual) < n {
+		n = len(actual)
+	}
+	for i := 0; i < n; i++ {
+		if expected[i] != actual[i] {
+			return fmt.Sprintf("body differs at byte %d (expected %d bytes, got %d)", i, len(expected), len(actual))
+		}
+	}
+	return fmt.Sprintf("body length differs (expected %d bytes, got %d)", len(expected), len(actual))
+}
+
+// escapeKey escapes each segment of a storage key for a catch-all route
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -1,12 +1,17 @@
 package validator
 
 import (
-	"bytes"
+	"encoding/json"
 	"fmt"
-	"mime/multipart"
+	"io"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/omnichat/validator/internal/client"
+	"github.com/omnichat/validator/internal/openapi"
+	"github.com/omnichat/validator/internal/spec"
 	"github.com/omnichat/validator/internal/types"
 	"github.com/omnichat/validator/pkg/colors"
 )
@@ -16,11 +21,16 @@ type Validator struct {
 	client       *client.APIClient
 	clerkClient  *client.APIClient // For Clerk auth endpoints
 	jwtClient    *client.APIClient // For JWT auth endpoints
+	secondClient *client.APIClient // JWT client for a second user, may be nil
 	config       *types.Config
-	results      []types.TestResult
+	out          io.Writer // Where check output is printed
 	authMode     string // "none", "clerk", "jwt", "both"
 	hasClerkAuth bool
 	hasJWTAuth   bool
+	openAPI      *openapi.Document // Fetched lazily for contract and coverage
+
+	mu      sync.Mutex // Guards results
+	results []types.TestResult
 }
 
 // NewValidator creates a new validator with expanded functionality
@@ -28,6 +38,7 @@ func NewValidator(config *types.Config, clerkToken, jwtToken string) *Validator
 	v := &Validator{
 		client:  client.NewAPIClient(config),
 		config:  config,
+		out:     os.Stdout,
 		results: []types.TestResult{},
 	}
 
@@ -46,6 +57,12 @@ func NewValidator(config *types.Config, clerkToken, jwtToken string) *Validator
 		v.hasJWTAuth = true
 	}
 
+	if config.SecondBearer != "" {
+		secondConfig := *config
+		secondConfig.AuthToken = config.SecondBearer
+		v.secondClient = client.NewAPIClient(&secondConfig)
+	}
+
 	// Determine auth mode
 	if v.hasClerkAuth && v.hasJWTAuth {
 		v.authMode = "both"
@@ -63,19 +80,45 @@ func NewValidator(config *types.Config, clerkToken, jwtToken string) *Validator
 // RunAllTests runs all API tests
 func (v *Validator) RunAllTests() error {
 	fmt.Printf("%s\n", colors.Header("🔍", fmt.Sprintf("Validating OmniChat API at %s", v.config.BaseURL)))
-	fmt.Printf("🔐 Authentication: %s\n\n", v.getAuthStatus())
-
-	// Always test public endpoints
-	v.testPublicEndpoints()
+	fmt.Printf("🔐 Authentication: %s\n", v.getAuthStatus())
+
+	if v.config.RateLimit {
+		v.runRateLimitSuite()
+	} else if v.config.Models {
+		v.runModelSuite()
+	} else if v.config.Webhooks {
+		v.runWebhookSuite()
+	} else if v.config.BatteryAudit {
+		v.runBatteryAudit()
+	} else if v.config.Contract {
+		if err := v.runContractSuite(); err != nil {
+			return err
+		}
+	} else {
+		specFile, err := v.loadSpec()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("📍 Testing %d endpoints across %d suites\n", specFile.Endpoints(), len(specFile.Suites))
 
-	// Test auth endpoints
-	v.testAuthEndpoints()
+		var tasks []task
+		for _, suite := range specFile.Suites {
+			tasks = append(tasks, v.suiteTasks(suite, specFile.Vars)...)
+		}
+		for _, scenario := range specFile.Scenarios {
+			tasks = append(tasks, func(v *Validator) { v.runScenario(scenario, specFile.Vars) })
+		}
+		tasks = append(tasks, (*Validator).runUploadRoundTrips, (*Validator).runStreamingTests, (*Validator).runAuthorizationMatrix,
+			(*Validator).runTokenRejectionSuite, (*Validator).runTokenLifecycle)
 
-	// Test Clerk auth endpoints
-	v.testClerkAuthEndpoints()
+		v.runTasks(tasks)
 
-	// Test JWT auth endpoints (V1 API)
-	v.testJWTAuthEndpoints()
+		// The billing scenario compares exact battery balances, so it runs
+		// once nothing else is spending battery
+		if v.config.Billing {
+			v.runBillingScenario()
+		}
+	}
 
 	// Print comprehensive results
 	v.printResults()
@@ -96,317 +139,184 @@ func (v *Validator) getAuthStatus() string {
 	}
 }
 
-// Test Public Endpoints (no auth required)
-func (v *Validator) testPublicEndpoints() {
-	fmt.Println(colors.Header("📂", "Testing Public Endpoints:"))
-	fmt.Println()
+// loadSpec assembles the built-in spec and any user supplied spec files
+func (v *Validator) loadSpec() (*spec.File, error) {
+	specFile := &spec.File{}
 
-	// Config endpoint
-	result := v.client.TestEndpoint("GET /api/config", "GET", "/api/config", nil)
-	if result.Success && v.config.Verbose {
-		fmt.Printf("   Config: Stripe=%v, Clerk=%v\n",
-			result.Response != nil, result.Response != nil)
+	if !v.config.SkipBuiltinSpec {
+		builtin, err := spec.Default()
+		if err != nil {
+			return nil, err
+		}
+		specFile.Merge(builtin)
 	}
-	v.results = append(v.results, result)
 
-	// OpenAPI spec
-	result = v.client.TestEndpoint("GET /api/openapi.json", "GET", "/api/openapi.json", nil)
-	if result.Success && v.config.Verbose {
-		fmt.Println("   OpenAPI spec available")
+	for _, path := range v.config.SpecFiles {
+		custom, err := spec.Load(path)
+		if err != nil {
+			return nil, err
+		}
+		specFile.Merge(custom)
 	}
-	v.results = append(v.results, result)
 
-	// API docs
-	result = v.client.TestEndpoint("GET /api/v1/docs", "GET", "/api/v1/docs", nil)
-	if result.Success && v.config.Verbose {
-		fmt.Println("   API documentation available")
+	if len(specFile.Suites) == 0 && len(specFile.Scenarios) == 0 {
+		return nil, fmt.Errorf("no test suites or scenarios to run")
 	}
-	v.results = append(v.results, result)
+
+	return specFile, nil
 }
 
-// Test Authentication Endpoints
-func (v *Validator) testAuthEndpoints() {
-	fmt.Println()
-	fmt.Println(colors.Header("🔐", "Testing Authentication Endpoints:"))
-	fmt.Println()
+// suiteTasks splits a spec suite into one task per test, with its header
+// and group subheaders as tasks of their own so they print in place
+func (v *Validator) suiteTasks(suite spec.Suite, vars map[string]string) []task {
+	tasks := []task{func(v *Validator) {
+		fmt.Fprintln(v.out)
+		fmt.Fprintln(v.out, colors.Header(suite.Emoji, fmt.Sprintf("Testing %s:", suite.Name)))
+		fmt.Fprintln(v.out)
+	}}
+
+	for i, group := range suite.Groups {
+		if group.Name != "" {
+			tasks = append(tasks, func(v *Validator) {
+				if i > 0 {
+					fmt.Fprintln(v.out)
+				}
+				fmt.Fprintln(v.out, colors.Subheader(group.Emoji, group.Name+":"))
+			})
+		}
 
-	// Apple Sign In
-	appleAuth := types.AppleAuthRequest{
-		IDToken: "mock-apple-jwt-token",
-		User: &types.AppleUserData{
-			Email: "test@example.com",
-			Name: &types.AppleUserName{
-				FirstName: "Test",
-				LastName:  "User",
-			},
-		},
-	}
-	result := v.client.TestEndpoint("POST /api/v1/auth/apple", "POST", "/api/v1/auth/apple", appleAuth)
-	if !result.Success && result.StatusCode == 400 {
-		fmt.Println("   💡 Expected: Requires valid Apple ID token")
+		for _, tc := range group.Tests {
+			tasks = append(tasks, func(v *Validator) {
+				v.recordAndPrint(v.runTestCase(tc, suite.Auth, vars))
+			})
+		}
 	}
-	v.results = append(v.results, result)
 
-	// Token Refresh
-	refreshReq := types.RefreshTokenRequest{
-		RefreshToken: "mock-refresh-token",
-	}
-	result = v.client.TestEndpoint("POST /api/v1/auth/refresh", "POST", "/api/v1/auth/refresh", refreshReq)
-	if !result.Success && result.StatusCode == 401 {
-		fmt.Println("   💡 Expected: Requires valid refresh token")
-	}
-	v.results = append(v.results, result)
+	return tasks
 }
 
-// Test Clerk Auth Endpoints
-func (v *Validator) testClerkAuthEndpoints() {
-	fmt.Println()
-	fmt.Println(colors.Header("🔒", "Testing Clerk Auth Endpoints:"))
-	fmt.Println()
+// Run a single spec test case and evaluate its expectations
+func (v *Validator) runTestCase(tc spec.TestCase, inheritedAuth string, vars map[string]string) types.TestResult {
+	authKind := tc.AuthKind(inheritedAuth)
+	client := v.clientFor(authKind)
+	name := tc.DisplayName()
+	path := spec.Expand(tc.Path, tc.Params, vars)
 
-	client := v.client
-	if v.hasClerkAuth {
-		client = v.clerkClient
+	var result types.TestResult
+	if tc.Multipart != nil {
+		result = v.testMultipartEndpoint(client, name, tc.Method, path, tc.Multipart, tc.Params, vars)
+	} else {
+		result = client.TestEndpoint(name, tc.Method, path, spec.ExpandValue(tc.Body, tc.Params, vars))
 	}
+	result.Operation = strings.ToUpper(tc.Method) + " " + tc.Path
 
-	// 1. Chat endpoint
-	fmt.Println(colors.Subheader("💬", "Chat & AI:"))
-	chatReq := types.ChatRequest{
-		Messages: []types.ChatMessage{
-			{Role: "user", Content: "Hello, this is a test message"},
-		},
-		Model:          "gpt-4o-mini",
-		ConversationID: "test-conversation",
-		Stream:         false,
+	// A transport error leaves no status code to judge
+	if result.StatusCode != 0 {
+		result.Success = tc.Expect.Accepts(result.StatusCode)
+		if result.Success {
+			result.Error = ""
+		} else if result.Error == "" {
+			result.Error = fmt.Sprintf("HTTP %d: unexpected status (want %v)", result.StatusCode, tc.Expect.Status)
+		}
 	}
-	result := client.TestEndpoint("POST /api/chat", "POST", "/api/chat", chatReq)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	// 2. Models endpoint
-	result = client.TestEndpoint("GET /api/models", "GET", "/api/models", nil)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	// 3. Conversations
-	fmt.Println()
-	fmt.Println(colors.Subheader("📚", "Conversations:"))
-
-	result = client.TestEndpoint("GET /api/conversations", "GET", "/api/conversations", nil)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
 
-	convReq := types.ConversationRequest{
-		Title: "Test Conversation",
-		Model: "gpt-4o-mini",
-	}
-	result = client.TestEndpoint("POST /api/conversations", "POST", "/api/conversations", convReq)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	result = client.TestEndpoint("DELETE /api/conversations/{id}", "DELETE", "/api/conversations/test-id", nil)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	// 4. Messages
-	fmt.Println()
-	fmt.Println(colors.Subheader("✉️", "Messages:"))
-
-	result = client.TestEndpoint("GET /api/conversations/{id}/messages", "GET", "/api/conversations/test-id/messages", nil)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	msgReq := types.MessageRequest{
-		Role:    "user",
-		Content: "Test message",
-		Model:   "gpt-4o-mini",
+	if result.Success {
+		result.AssertionFailures = append(result.AssertionFailures, checkResponseType(result)...)
+		for _, assertion := range tc.Expect.Assertions {
+			assertion.Equals = spec.ExpandValue(assertion.Equals, tc.Params, vars)
+			if err := assertion.Check(result.Response); err != nil {
+				result.AssertionFailures = append(result.AssertionFailures, err.Error())
+			}
+		}
+		failIfAsserted(&result)
 	}
-	result = client.TestEndpoint("POST /api/conversations/{id}/messages", "POST", "/api/conversations/test-id/messages", msgReq)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	// 5. Files
-	fmt.Println()
-	fmt.Println(colors.Subheader("📁", "Files:"))
-
-	// File upload (multipart)
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-	writer.WriteField("conversationId", "test-id")
-	fileWriter, _ := writer.CreateFormFile("file", "test.txt")
-	fileWriter.Write([]byte("test file content"))
-	writer.Close()
-
-	result = v.testMultipartEndpoint(client, "POST /api/upload", "/api/upload", &buf, writer.FormDataContentType())
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	result = client.TestEndpoint("GET /api/upload?key=test", "GET", "/api/upload?key=test", nil)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	// 6. Search
-	fmt.Println()
-	fmt.Println(colors.Subheader("🔍", "Search:"))
-
-	result = client.TestEndpoint("GET /api/search?q=test", "GET", "/api/search?q=test", nil)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	// 7. Battery
-	fmt.Println()
-	fmt.Println(colors.Subheader("🔋", "Battery & Usage:"))
-
-	result = client.TestEndpoint("GET /api/battery", "GET", "/api/battery", nil)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	// 8. User
-	fmt.Println()
-	fmt.Println(colors.Subheader("👤", "User:"))
 
-	result = client.TestEndpoint("GET /api/user/tier", "GET", "/api/user/tier", nil)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
-
-	// 9. Billing
-	fmt.Println()
-	fmt.Println(colors.Subheader("💳", "Billing:"))
-
-	checkoutReq := types.CheckoutRequest{
-		Type:      "subscription",
-		PlanID:    "monthly",
-		ReturnURL: "http://localhost:3000/billing",
+	if hint, ok := tc.Hints[result.StatusCode]; ok && !result.Success {
+		result.Error += "\n   💡 " + hint
 	}
-	result = client.TestEndpoint("POST /api/stripe/checkout", "POST", "/api/stripe/checkout", checkoutReq)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
 
-	result = client.TestEndpoint("GET /api/stripe/checkout", "GET", "/api/stripe/checkout", nil)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
+	return v.addAuthHint(result, authKind)
+}
 
-	portalReq := map[string]string{
-		"returnUrl": "http://localhost:3000/billing",
+// clientFor picks the API client matching a spec auth kind
+func (v *Validator) clientFor(authKind string) *client.APIClient {
+	switch authKind {
+	case spec.AuthClerk:
+		if v.hasClerkAuth {
+			return v.clerkClient
+		}
+	case spec.AuthJWT:
+		if v.hasJWTAuth {
+			return v.jwtClient
+		}
 	}
-	result = client.TestEndpoint("POST /api/stripe/portal", "POST", "/api/stripe/portal", portalReq)
-	v.addAuthHint(result, "clerk")
-	v.results = append(v.results, result)
+	return v.client
 }
 
-// Test JWT Auth Endpoints (V1 API)
-func (v *Validator) testJWTAuthEndpoints() {
-	fmt.Println()
-	fmt.Println(colors.Header("🔑", "Testing JWT Auth Endpoints (V1 API):"))
-	fmt.Println()
-
-	client := v.client
-	if v.hasJWTAuth {
-		client = v.jwtClient
+// Helper to test multipart endpoints
+func (v *Validator) testMultipartEndpoint(apiClient *client.APIClient, name, method, path string, mp *spec.Multipart, params, vars map[string]string) types.TestResult {
+	fields := make(map[string]string, len(mp.Fields))
+	for field, value := range mp.Fields {
+		fields[field] = spec.Expand(value, params, vars)
+	}
+
+	files := make([]client.FilePart, 0, len(mp.Files))
+	for _, file := range mp.Files {
+		part := client.FilePart{
+			FieldName:   file.Field,
+			FileName:    file.FileName,
+			ContentType: file.ContentType,
+			Path:        file.Source,
+		}
+		if file.Source == "" {
+			part.Reader = strings.NewReader(file.Content)
+		}
+		files = append(files, part)
 	}
 
-	// 1. Conversations V1
-	fmt.Println(colors.Subheader("📚", "Conversations V1:"))
-
-	result := client.TestEndpoint("GET /api/v1/conversations", "GET", "/api/v1/conversations", nil)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
+	return apiClient.TestMultipart(name, method, path, fields, files)
+}
 
-	convReq := types.ConversationRequest{
-		Title: "Test V1 Conversation",
-		Model: "gpt-4o-mini",
+// Add auth hint to failed requests
+func (v *Validator) addAuthHint(result types.TestResult, authType string) types.TestResult {
+	if !result.Success && (result.StatusCode == 401 || result.StatusCode == 403) {
+		if authType == spec.AuthClerk && !v.hasClerkAuth {
+			result.Error += "\n   🔑 Requires Clerk authentication. Use --clerk flag"
+		} else if authType == spec.AuthJWT && !v.hasJWTAuth {
+			result.Error += "\n   🔑 Requires JWT authentication. Use --bearer flag"
+		}
 	}
-	result = client.TestEndpoint("POST /api/v1/conversations", "POST", "/api/v1/conversations", convReq)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
+	return result
+}
 
-	result = client.TestEndpoint("GET /api/v1/conversations/{id}", "GET", "/api/v1/conversations/test-id", nil)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
+// Print a single test result line with its error and assertion details
+func (v *Validator) printResult(result types.TestResult) {
+	duration := result.Duration.Round(time.Millisecond)
 
-	updateReq := types.ConversationUpdateRequest{
-		Title:      "Updated Title",
-		IsArchived: true,
+	switch {
+	case result.Success:
+		fmt.Fprintf(v.out, "%s %s (%v)\n", colors.Success("✅"), result.Name, duration)
+	case result.KnownGap != "" || result.StatusCode == 401 || result.StatusCode == 403:
+		fmt.Fprintf(v.out, "%s  %s (%v)\n", colors.Warning("⚠️"), result.Name, duration)
+	default:
+		fmt.Fprintf(v.out, "%s %s (%v)\n", colors.Error("❌"), result.Name, duration)
 	}
-	result = client.TestEndpoint("PATCH /api/v1/conversations/{id}", "PATCH", "/api/v1/conversations/test-id", updateReq)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
-
-	result = client.TestEndpoint("DELETE /api/v1/conversations/{id}", "DELETE", "/api/v1/conversations/test-id", nil)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
 
-	// 2. Messages V1
-	fmt.Println()
-	fmt.Println(colors.Subheader("✉️", "Messages V1:"))
-
-	result = client.TestEndpoint("GET /api/v1/conversations/{id}/messages", "GET", "/api/v1/conversations/test-id/messages", nil)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
-
-	v1MsgReq := types.V1MessageRequest{
-		Content: "Test V1 message",
-		Stream:  false,
+	for _, retry := range result.Retries {
+		reason := retry.Error
+		if retry.StatusCode != 0 {
+			reason = fmt.Sprintf("HTTP %d", retry.StatusCode)
+		}
+		fmt.Fprintf(v.out, "   %s attempt %d: %s, retried after %v\n", colors.Warning("↻"), retry.Attempt, reason, retry.Delay.Round(time.Millisecond))
 	}
-	result = client.TestEndpoint("POST /api/v1/conversations/{id}/messages", "POST", "/api/v1/conversations/test-id/messages", v1MsgReq)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
-
-	// 3. User Profile V1
-	fmt.Println()
-	fmt.Println(colors.Subheader("👤", "User Profile V1:"))
-
-	result = client.TestEndpoint("GET /api/v1/user/profile", "GET", "/api/v1/user/profile", nil)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
-
-	profileUpdate := types.UserProfileUpdate{
-		Name: "Updated Test User",
+	if result.Error != "" {
+		fmt.Fprintf(v.out, "   Error: %s\n", result.Error)
 	}
-	result = client.TestEndpoint("PATCH /api/v1/user/profile", "PATCH", "/api/v1/user/profile", profileUpdate)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
-
-	result = client.TestEndpoint("GET /api/v1/user/usage", "GET", "/api/v1/user/usage", nil)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
-
-	// 4. Files V1
-	fmt.Println()
-	fmt.Println(colors.Subheader("📁", "Files V1:"))
-
-	// File upload V1 (multipart)
-	var buf bytes.Buffer
-	writer := multipart.NewWriter(&buf)
-	writer.WriteField("conversationId", "test-id")
-	fileWriter, _ := writer.CreateFormFile("file", "test-v1.txt")
-	fileWriter.Write([]byte("test v1 file content"))
-	writer.Close()
-
-	result = v.testMultipartEndpoint(client, "POST /api/v1/upload", "/api/v1/upload", &buf, writer.FormDataContentType())
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
-
-	result = client.TestEndpoint("GET /api/v1/files/{key}", "GET", "/api/v1/files/test-key", nil)
-	v.addAuthHint(result, "jwt")
-	v.results = append(v.results, result)
-}
-
-// Helper to test multipart endpoints
-func (v *Validator) testMultipartEndpoint(client *client.APIClient, name, path string, body *bytes.Buffer, contentType string) types.TestResult {
-	// For now, just test that the endpoint exists
-	// Real implementation would send the multipart data
-	return client.TestEndpoint(name+" (multipart)", "POST", path, nil)
-}
-
-// Add auth hint to failed requests
-func (v *Validator) addAuthHint(result types.TestResult, authType string) {
-	if !result.Success && (result.StatusCode == 401 || result.StatusCode == 403) {
-		if authType == "clerk" && !v.hasClerkAuth {
-			result.Error += "\n   🔑 Requires Clerk authentication. Use --clerk flag"
-		} else if authType == "jwt" && !v.hasJWTAuth {
-			result.Error += "\n   🔑 Requires JWT authentication. Use --bearer flag"
-		}
+	if result.KnownGap != "" {
+		fmt.Fprintf(v.out, "   Known gap: %s\n", result.KnownGap)
+	}
+	for _, failure := range result.AssertionFailures {
+		fmt.Fprintf(v.out, "   %s %s\n", colors.Error("✗"), failure)
 	}
 }
 
@@ -417,21 +327,28 @@ func (v *Validator) printResults() {
 	fmt.Println()
 
 	// Calculate statistics
-	total := len(v.results)
+	results := v.Results()
+	total := len(results)
 	passed := 0
 	failed := 0
 	authRequired := 0
+	knownGaps := 0
 
 	categoryStats := make(map[string]struct {
 		total  int
 		passed int
 		failed int
 		auth   int
+		known  int
 	})
+	var categories []string // In first-seen order
 
-	for _, result := range v.results {
-		category := v.getEndpointCategory(result.Name)
-		stats := categoryStats[category]
+	for _, result := range results {
+		category := result.Category
+		stats, seen := categoryStats[category]
+		if !seen {
+			categories = append(categories, category)
+		}
 		stats.total++
 
 		if result.Success {
@@ -440,7 +357,10 @@ func (v *Validator) printResults() {
 		} else {
 			failed++
 			stats.failed++
-			if result.StatusCode == 401 || result.StatusCode == 403 {
+			if result.KnownGap != "" {
+				knownGaps++
+				stats.known++
+			} else if result.StatusCode == 401 || result.StatusCode == 403 {
 				authRequired++
 				stats.auth++
 			}
@@ -451,7 +371,8 @@ func (v *Validator) printResults() {
 
 	// Print category breakdown
 	fmt.Println("By Category:")
-	for category, stats := range categoryStats {
+	for _, category := range categories {
+		stats := categoryStats[category]
 		fmt.Printf("  %-20s Total: %2d | Passed: %s | Failed: %s",
 			category,
 			stats.total,
@@ -461,6 +382,9 @@ func (v *Validator) printResults() {
 		if stats.auth > 0 {
 			fmt.Printf(" | Auth Required: %s", colors.Warning(fmt.Sprintf("%d", stats.auth)))
 		}
+		if stats.known > 0 {
+			fmt.Printf(" | Known Gaps: %s", colors.Warning(fmt.Sprintf("%d", stats.known)))
+		}
 		fmt.Println()
 	}
 
@@ -471,10 +395,14 @@ func (v *Validator) printResults() {
 		v.colorNumber(passed, passed == total),
 		v.colorNumber(failed, failed == 0),
 		colors.Warning(fmt.Sprintf("%d", authRequired)))
+	if knownGaps > 0 {
+		fmt.Printf("Known Gaps: %s (failures the API is known to have, see above)\n", colors.Warning(fmt.Sprintf("%d", knownGaps)))
+	}
 
-	// Coverage
-	coverage := float64(total) / 43.0 * 100
-	fmt.Printf("Endpoint Coverage: %d/43 (%.1f%%)\n", total, coverage)
+	// Coverage; the rate limit suite only targets a few endpoints on purpose
+	if !v.config.RateLimit && !v.config.Models && !v.config.Webhooks && !v.config.BatteryAudit {
+		v.printCoverage()
+	}
 
 	// Next steps
 	fmt.Println()
@@ -493,7 +421,7 @@ func (v *Validator) printResults() {
 		fmt.Println("   2. Run: omnichat-validator --clerk CLERK_TOKEN")
 	}
 
-	if failed > authRequired {
+	if failed > authRequired+knownGaps {
 		fmt.Println()
 		fmt.Println(colors.Error("❌ Some tests failed beyond auth issues. Review errors above."))
 	} else if passed == total {
@@ -502,10 +430,62 @@ func (v *Validator) printResults() {
 	}
 }
 
+// record categorizes a result and adds it to the run. It is safe to
+// call from multiple goroutines.
+func (v *Validator) record(result types.TestResult) {
+	if result.Category == "" {
+		result.Category = v.getEndpointCategory(result.Name)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.results = append(v.results, result)
+}
+
+// recordAndPrint prints a result line and records it
+func (v *Validator) recordAndPrint(result types.TestResult) {
+	v.printResult(result)
+	v.record(result)
+}
+
+// decodeResponse converts a decoded JSON response into a typed struct
+func decodeResponse(response interface{}, target interface{}) error {
+	if s, ok := response.(string); ok {
+		if err := json.Unmarshal([]byte(s), target); err != nil {
+			return fmt.Errorf("$: response is not valid JSON: %v", err)
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return fmt.Errorf("$: failed to re-encode response: %v", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("$: %v", err)
+	}
+	return nil
+}
+
+// failIfAsserted marks a result failed when it has assertion failures
+func failIfAsserted(result *types.TestResult) {
+	if len(result.AssertionFailures) > 0 {
+		result.Success = false
+		result.Error = fmt.Sprintf("%d assertion(s) failed", len(result.AssertionFailures))
+	}
+}
+
+// Results returns a copy of every recorded test result in run order
+func (v *Validator) Results() []types.TestResult {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return append([]types.TestResult(nil), v.results...)
+}
+
 // HasFailures returns true if there are non-auth failures
 func (v *Validator) HasFailures() bool {
-	for _, result := range v.results {
-		if !result.Success && result.StatusCode != 401 && result.StatusCode != 403 {
+	for _, result := range v.Results() {
+		if !result.Success && result.KnownGap == "" && result.StatusCode != 401 && result.StatusCode != 403 {
 			return true
 		}
 	}
@@ -544,66 +524,6 @@ func (v *Validator) colorNumber(n int, good bool) string {
 	return colors.Error(fmt.Sprintf("%d", n))
 }
 
-// Helper functions for response validation
-func (v *Validator) validateModelsResponse(response interface{}) []string {
-	errors := []string{}
-	
-	respMap, ok := response.(map[string]interface{})
-	if !ok {
-		errors = append(errors, "Response is not a JSON object")
-		return errors
-	}
-	
-	providers, ok := respMap["providers"]
-	if !ok {
-		errors = append(errors, "Missing 'providers' field")
-		return errors
-	}
-	
-	providersMap, ok := providers.(map[string]interface{})
-	if !ok {
-		errors = append(errors, "'providers' field is not an object")
-		return errors
-	}
-	
-	// Validate that at least one provider exists
-	if len(providersMap) == 0 {
-		errors = append(errors, "No providers found in response")
-	}
-	
-	// Validate each provider has models
-	for providerName, models := range providersMap {
-		modelsArray, ok := models.([]interface{})
-		if !ok {
-			errors = append(errors, fmt.Sprintf("Provider '%s' models is not an array", providerName))
-			continue
-		}
-		
-		if len(modelsArray) == 0 {
-			errors = append(errors, fmt.Sprintf("Provider '%s' has no models", providerName))
-		}
-		
-		// Basic validation of model structure
-		for i, model := range modelsArray {
-			modelMap, ok := model.(map[string]interface{})
-			if !ok {
-				errors = append(errors, fmt.Sprintf("Provider '%s' model[%d] is not an object", providerName, i))
-				continue
-			}
-			
-			// Check required fields
-			requiredFields := []string{"id", "name", "provider", "contextWindow", "maxOutput"}
-			for _, field := range requiredFields {
-				if _, ok := modelMap[field]; !ok {
-					errors = append(errors, fmt.Sprintf("Provider '%s' model[%d] missing field '%s'", providerName, i, field))
-				}
-			}
-		}
-	}
-	
-	return errors
-}
-
 // Backward compatibility - keep the old NewValidator function signature
 func NewValidatorBasic(config *types.Config) *Validator {
 	return NewValidator(config, "", "")
@@ -0,0 +1,215 @@
+package validator
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omnichat/validator/internal/mock"
+	"github.com/omnichat/validator/internal/stripe"
+	"github.com/omnichat/validator/internal/types"
+)
+
+// newMockServer serves a mock API for the length of the test
+func newMockServer(t *testing.T, opts mock.Options) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(mock.New(opts))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// fullConfig enables every suite of a normal run that the mock supports
+func fullConfig(baseURL string) *types.Config {
+	return &types.Config{
+		BaseURL:      baseURL,
+		Timeout:      5 * time.Second,
+		Parallel:     1,
+		SecondBearer: mock.DefaultSecondToken,
+		RefreshToken: mock.DefaultRefreshToken,
+		Billing:      true,
+		Webhook:      types.WebhookConfig{Secret: mock.DefaultStripeWebhookSecret},
+		Retry:        types.RetryPolicy{MaxAttempts: 1},
+	}
+}
+
+// run runs a validator quietly and returns its results
+func run(t *testing.T, config *types.Config) []types.TestResult {
+	t.Helper()
+	v := NewValidator(config, mock.DefaultClerkToken, mock.DefaultJWTToken)
+	v.out = io.Discard
+	if err := v.RunAllTests(); err != nil {
+		t.Fatalf("RunAllTests: %v", err)
+	}
+	return v.Results()
+}
+
+// placeholderCheck reports whether a result is a built-in check of a
+// resource the spec names by a placeholder, which the mock does not have
+// or does not let the user read
+func placeholderCheck(result types.TestResult) bool {
+	return (result.StatusCode == 403 || result.StatusCode == 404) &&
+		(strings.Contains(result.Name, "{") || strings.Contains(result.Name, "key=test"))
+}
+
+func TestRunAllTestsAgainstMock(t *testing.T) {
+	server := newMockServer(t, mock.Options{})
+	results := run(t, fullConfig(server.URL))
+
+	categories := make(map[string]int)
+	deletedUploads := 0
+	knownGaps := 0
+	for _, result := range results {
+		categories[result.Category]++
+		if strings.HasPrefix(result.Name, "DELETE /api/v1/files/{key} [") {
+			deletedUploads++
+		}
+		if result.Success || placeholderCheck(result) {
+			continue
+		}
+		// Like the API, the mock does not check the token audience
+		if result.KnownGap != "" {
+			knownGaps++
+			continue
+		}
+		// Sign in with Apple needs a JWKS the mock is not given here
+		if result.Name == "POST /api/v1/auth/apple" && result.StatusCode == 401 {
+			continue
+		}
+		t.Errorf("%s failed: %s %q", result.Name, result.Error, result.AssertionFailures)
+	}
+
+	if knownGaps != 1 {
+		t.Errorf("%d known gaps recorded, want the wrong audience only", knownGaps)
+	}
+	if deletedUploads != len(uploadFixtures) {
+		t.Errorf("%d v1 uploads deleted, want %d", deletedUploads, len(uploadFixtures))
+	}
+	for _, category := range []string{"Scenarios", "Authorization", "Token Validation", lifecycleCategory, billingCategory} {
+		if categories[category] == 0 {
+			t.Errorf("no %s results", category)
+		}
+	}
+}
+
+func TestBillingScenarioSkipsPaidUser(t *testing.T) {
+	server := newMockServer(t, mock.Options{})
+	event, err := stripe.NewEvent(stripe.CheckoutSessionCompleted, stripe.NewCustomer("user_mock", defaultBillingPlan), "active")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("POST", server.URL+webhookPath, bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Stripe-Signature", stripe.Sign(payload, mock.DefaultStripeWebhookSecret, time.Now()))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("webhook: HTTP %d", resp.StatusCode)
+	}
+
+	for _, result := range run(t, fullConfig(server.URL)) {
+		if result.Category == billingCategory {
+			t.Errorf("the billing scenario ran for a paid user: %s", result.Name)
+		}
+	}
+}
+
+func TestWebhookSuite(t *testing.T) {
+	server := newMockServer(t, mock.Options{})
+	config := &types.Config{
+		BaseURL:  server.URL,
+		Timeout:  5 * time.Second,
+		Webhooks: true,
+		Webhook: types.WebhookConfig{
+			Secret: mock.DefaultStripeWebhookSecret,
+			UserID: "user_mock",
+			PlanID: defaultBillingPlan,
+			Events: stripe.EventTypes,
+		},
+	}
+
+	events := 0
+	for _, result := range run(t, config) {
+		if !result.Success {
+			t.Errorf("%s failed: %s %q", result.Name, result.Error, result.AssertionFailures)
+		}
+		if strings.Contains(result.Name, "(customer.subscription.deleted)") {
+			events++
+		}
+	}
+	if events != 1 {
+		t.Errorf("%d results for the deleted subscription, want 1", events)
+	}
+
+	// The deletion leaves the tier the checkout set
+	v := NewValidator(config, mock.DefaultClerkToken, "")
+	if state, result := v.readBillingState("after the suite"); !result.Success || state.tier != "paid" {
+		t.Errorf("tier after the suite = %q (%s), want paid", state.tier, result.Error)
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		fault   mock.Fault
+		status  int
+		retries int
+		error   string
+	}{
+		{"status", mock.Fault{Pattern: "/api/config", Action: mock.FaultStatus, Status: 503}, 503, 2, "HTTP 503"},
+		{"reset", mock.Fault{Pattern: "/api/config", Action: mock.FaultReset}, 0, 2, "EOF"},
+		{"delay", mock.Fault{Method: "GET", Pattern: "/api/config", Action: mock.FaultDelay, Delay: time.Second}, 0, 2, "Timeout"},
+		{"other method", mock.Fault{Method: "POST", Pattern: "/api/config", Action: mock.FaultStatus, Status: 500}, 200, 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newMockServer(t, mock.Options{Faults: []mock.Fault{tt.fault}})
+			config := &types.Config{
+				BaseURL:         server.URL,
+				Timeout:         200 * time.Millisecond,
+				SpecFiles:       []string{filepath.Join("testdata", "public.yaml")},
+				SkipBuiltinSpec: true,
+				Retry:           types.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond},
+			}
+
+			var configResult, docs *types.TestResult
+			results := run(t, config)
+			for i := range results {
+				switch results[i].Name {
+				case "GET /api/config":
+					configResult = &results[i]
+				case "GET /api/v1/docs":
+					docs = &results[i]
+				}
+			}
+			if configResult == nil || docs == nil {
+				t.Fatalf("missing results: %+v", results)
+			}
+
+			if configResult.StatusCode != tt.status || len(configResult.Retries) != tt.retries || configResult.Success != (tt.error == "") {
+				t.Errorf("GET /api/config: status %d, %d retries, success %t; want %d, %d, %t",
+					configResult.StatusCode, len(configResult.Retries), configResult.Success, tt.status, tt.retries, tt.error == "")
+			}
+			if !strings.Contains(configResult.Error, tt.error) {
+				t.Errorf("GET /api/config error %q, want %q", configResult.Error, tt.error)
+			}
+			if !docs.Success || len(docs.Retries) != 0 {
+				t.Errorf("GET /api/v1/docs is not targeted but got %+v", *docs)
+			}
+		})
+	}
+}
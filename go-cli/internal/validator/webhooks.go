@@ -0,0 +1,242 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/omnichat/validator/internal/stripe"
+	"github.com/omnichat/validator/internal/types"
+	"github.com/omnichat/validator/pkg/colors"
+)
+
+const webhookCategory = "Webhooks"
+
+const (
+	webhookPath      = "/api/stripe/webhook"
+	webhookOperation = "POST /api/stripe/webhook"
+)
+
+// billingState is what the webhooks change, as the Clerk user sees it
+type billingState struct {
+	tier    string
+	balance int
+}
+
+// webhookEffect is the billing state an event must leave behind
+type webhookEffect struct {
+	tier         string
+	batteryDelta int
+}
+
+// expectedEffect returns the effect of an event on a subscription to plan
+// for a user on tier. Only a new subscription credits the battery. Only a
+// checkout and an update to an active subscription set the tier; the API
+// leaves it as it was after a failed payment and a deleted subscription.
+func expectedEffect(eventType string, plan stripe.Plan, tier string) webhookEffect {
+	switch eventType {
+	case stripe.CheckoutSessionCompleted:
+		return webhookEffect{tier: "paid", batteryDelta: plan.BatteryUnits}
+	case stripe.SubscriptionUpdated:
+		return webhookEffect{tier: "paid"}
+	}
+	return webhookEffect{tier: tier}
+}
+
+// Check forged and stale webhook signatures are rejected, then send a
+// signed event for each configured type and read its effect back from
+// /api/user/tier and /api/battery
+func (v *Validator) runWebhookSuite() {
+	fmt.Fprintln(v.out)
+	fmt.Fprintln(v.out, colors.Header("💳", "Testing Stripe Webhooks ("+webhookPath+"):"))
+	fmt.Fprintln(v.out)
+
+	if !v.hasClerkAuth {
+		fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --clerk"))
+		return
+	}
+
+	config := v.config.Webhook
+	userID := config.UserID
+	if userID == "" {
+		profile, ok := v.bearerProfile()
+		if !ok {
+			fmt.Fprintln(v.out, colors.Warning("   Skipped: requires --user-id or --bearer to name the user"))
+			return
+		}
+		userID = profile.ID
+	}
+
+	customer := stripe.NewCustomer(userID, config.PlanID)
+	if config.SubscriptionID != "" {
+		customer.SubscriptionID = config.SubscriptionID
+	}
+	fmt.Fprintf(v.out, "   User %s, %s plan, customer %s, subscription %s\n\n",
+		userID, config.PlanID, customer.CustomerID, customer.SubscriptionID)
+
+	// The probe describes a subscription nobody has, so it is harmless if
+	// a broken server accepts it
+	event, err := stripe.NewEvent(stripe.InvoicePaymentFailed, stripe.NewCustomer(userID, config.PlanID), "")
+	if err != nil {
+		v.recordAndPrint(types.TestResult{Name: webhookOperation, Category: webhookCategory, Error: err.Error()})
+		return
+	}
+	probe, err := json.Marshal(event)
+	if err != nil {
+		v.recordAndPrint(types.TestResult{Name: webhookOperation, Category: webhookCategory, Error: err.Error()})
+		return
+	}
+	now := time.Now()
+	v.recordAndPrint(v.checkWebhookRejected("missing signature", probe, ""))
+	v.recordAndPrint(v.checkWebhookRejected("wrong secret", probe, stripe.Sign(probe, config.Secret+"-wrong", now)))
+	v.recordAndPrint(v.checkWebhookRejected("stale timestamp", probe, stripe.Sign(probe, config.Secret, now.Add(-2*stripe.DefaultTolerance))))
+
+	before, result := v.readBillingState("before")
+	if !result.Success {
+		v.recordAndPrint(result)
+		return
+	}
+	fmt.Fprintf(v.out, "   Before: %s tier, battery %d\n", before.tier, before.balance)
+
+	for _, eventType := range config.Events {
+		result, after := v.sendWebhook(eventType, customer, before)
+		v.recordAndPrint(result)
+		if after == nil {
+			return
+		}
+		before = *after
+	}
+	fmt.Fprintf(v.out, "   After: %s tier, battery %d\n", before.tier, before.balance)
+}
+
+// sendWebhook posts a signed event and checks its effect on the billing
+// state. The state is nil if it could not be read.
+func (v *Validator) sendWebhook(eventType string, customer stripe.Customer, before billingState) (types.TestResult, *billingState) {
+	result := types.TestResult{Name: webhookOperation + " (" + eventType + ")", Operation: webhookOperation, Category: webhookCategory}
+
+	event, err := stripe.NewEvent(eventType, customer, "active")
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil
+	}
+	result = v.postWebhook(result.Name, event)
+	result.Category = webhookCategory
+	if !result.Success {
+		return v.addSubscriptionHint(result, eventType), &before
+	}
+
+	after, state := v.readBillingState("after " + eventType)
+	if !state.Success {
+		result.Success = false
+		result.Error = state.Error
+		return result, nil
+	}
+
+	effect := expectedEffect(eventType, stripe.Plans[customer.PlanID], before.tier)
+	if after.tier != effect.tier {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("tier: expected %q after %s, got %q", effect.tier, eventType, after.tier))
+	}
+	if delta := after.balance - before.balance; delta != effect.batteryDelta {
+		result.AssertionFailures = append(result.AssertionFailures,
+			fmt.Sprintf("battery: expected balance %+d after %s, got %+d (%d → %d)",
+				effect.batteryDelta, eventType, delta, before.balance, after.balance))
+	}
+	failIfAsserted(&result)
+	return result, &after
+}
+
+// postWebhook signs an event with the configured secret and posts it
+func (v *Validator) postWebhook(name string, event stripe.Event) types.TestResult {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return types.TestResult{Name: name, Operation: webhookOperation, Error: err.Error()}
+	}
+
+	// The client marshals the body again; a RawMessage comes out as the
+	// same compact bytes, so the signature still matches
+	header := http.Header{}
+	header.Set("Stripe-Signature", stripe.Sign(payload, v.config.Webhook.Secret, time.Now()))
+	result := v.client.TestEndpointWithHeader(name, "POST", webhookPath, json.RawMessage(payload), header)
+	result.Operation = webhookOperation
+	return result
+}
+
+// addSubscriptionHint explains a server error on an event whose made-up
+// subscription the API could not look up in Stripe
+func (v *Validator) addSubscriptionHint(result types.TestResult, eventType string) types.TestResult {
+	if result.StatusCode >= 500 && v.config.Webhook.SubscriptionID == "" && stripe.RetrievesSubscription(eventType) {
+		flag := "--stripe-subscription"
+		if v.config.Webhooks {
+			flag = "--subscription"
+		}
+		result.Error += "\n   💳 The API looks this subscription up in Stripe. Use " + flag + " with a test-mode subscription"
+	}
+	return result
+}
+
+// checkWebhookRejected posts an event with a bad or missing signature,
+// which must get a 400
+func (v *Validator) checkWebhookRejected(name string, payload []byte, signature string) types.TestResult {
+	header := http.Header{}
+	if signature != "" {
+		header.Set("Stripe-Signature", signature)
+	}
+	result := v.client.TestEndpointWithHeader(webhookOperation+" ("+name+")", "POST", webhookPath, json.RawMessage(payload), header)
+	result.Operation = webhookOperation
+	result.Category = webhookCategory
+
+	switch {
+	case result.StatusCode == 400:
+		result.Success = true
+		result.Error = ""
+		checkErrorBody(&result)
+		failIfAsserted(&result)
+	case result.Success:
+		result.Success = false
+		result.Error = fmt.Sprintf("HTTP %d: an event with a %s was accepted", result.StatusCode, name)
+	default:
+		if result.StatusCode != 0 {
+			result.Error = fmt.Sprintf("HTTP %d: expected 400", result.StatusCode)
+		}
+	}
+	return result
+}
+
+// readBillingState reads the tier and battery balance of the Clerk user.
+// The result only matters when it failed.
+func (v *Validator) readBillingState(step string) (billingState, types.TestResult) {
+	var state billingState
+
+	result := v.clerkClient.TestEndpoint("GET /api/user/tier ("+step+")", "GET", "/api/user/tier", nil)
+	result.Operation = "GET /api/user/tier"
+	result.Category = webhookCategory
+	var tier types.UserTierResponse
+	if result.Success {
+		if err := decodeResponse(result.Response, &tier); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+	}
+	if !result.Success {
+		return state, v.addAuthHint(result, "clerk")
+	}
+	state.tier = tier.Tier
+
+	result = v.clerkClient.TestEndpoint("GET /api/battery ("+step+")", "GET", "/api/battery", nil)
+	result.Operation = "GET /api/battery"
+	result.Category = webhookCategory
+	var battery types.BatteryResponse
+	if result.Success {
+		if err := decodeResponse(result.Response, &battery); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+	}
+	if !result.Success {
+		return state, v.addAuthHint(result, "clerk")
+	}
+	state.balance = battery.Balance
+	return state, result
+}
@@ -0,0 +1,194 @@
+package omnichat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/omnichat/validator/internal/jwt"
+	"github.com/omnichat/validator/internal/types"
+)
+
+// refreshSkew is how long before it expires an access token is refreshed
+const refreshSkew = 30 * time.Second
+
+// RefreshToken exchanges the refresh token for a new access token, which
+// the client uses from then on
+func (c *Client) RefreshToken(ctx context.Context) (*AuthResponse, error) {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	return c.exchangeRefreshToken(ctx)
+}
+
+// Tokens returns the access and refresh tokens in use
+func (c *Client) Tokens() (accessToken, refreshToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.accessToken, c.refreshToken
+}
+
+// exchangeRefreshToken calls the refresh endpoint. Callers hold
+// c.refreshMu.
+func (c *Client) exchangeRefreshToken(ctx context.Context) (*AuthResponse, error) {
+	_, refreshToken := c.Tokens()
+	if refreshToken == "" {
+		return nil, errors.New("omnichat: no refresh token")
+	}
+	body, err := marshal(types.RefreshTokenRequest{RefreshToken: refreshToken})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.roundTrip(ctx, "POST", "/api/v1/auth/refresh", body, http.Header{"Content-Type": {"application/json"}}, "")
+	if err != nil {
+		return nil, err
+	}
+	var auth AuthResponse
+	if err := decode(resp, &auth); err != nil {
+		return nil, err
+	}
+	if auth.AccessToken == "" {
+		return nil, errors.New("omnichat: refresh response has no access token")
+	}
+
+	// The API keeps the refresh token and may leave it out of the response
+	if auth.RefreshToken == "" {
+		auth.RefreshToken = refreshToken
+	}
+	c.setTokens(auth.AccessToken, auth.RefreshToken, auth.ExpiresIn)
+	if c.onRefresh != nil {
+		c.onRefresh(auth)
+	}
+	return &auth, nil
+}
+
+// refreshStale refreshes the access token unless another request already
+// replaced the stale one
+func (c *Client) refreshStale(ctx context.Context, stale string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	if accessToken, _ := c.Tokens(); accessToken != stale {
+		return nil
+	}
+	_, err := c.exchangeRefreshToken(ctx)
+	return err
+}
+
+// setTokens replaces the tokens. The expiry comes from expiresIn or, if it
+// is not known, the access token's exp claim.
+func (c *Client) setTokens(accessToken, refreshToken string, expiresIn int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accessToken = accessToken
+	c.refreshToken = refreshToken
+	c.expiresAt = time.Time{}
+	if expiresIn > 0 {
+		c.expiresAt = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	} else if claims, err := jwt.Decode(accessToken); err == nil {
+		if exp, ok := claims["exp"].(float64); ok {
+			c.expiresAt = time.Unix(int64(exp), 0)
+		}
+	}
+}
+
+// send performs a request with the access token. With a refresh token, the
+// access token is refreshed first if it is about to expire, and once more
+// and the request repeated if the API rejects it with a 401.
+func (c *Client) send(ctx context.Context, method, path string, body []byte, header http.Header) (*http.Response, error) {
+	c.mu.Lock()
+	accessToken, canRefresh := c.accessToken, c.refreshToken != ""
+	expiring := !c.expiresAt.IsZero() && time.Until(c.expiresAt) < refreshSkew
+	c.mu.Unlock()
+
+	if canRefresh && expiring {
+		if err := c.refreshStale(ctx, accessToken); err != nil {
+			return nil, err
+		}
+		accessToken, _ = c.Tokens()
+	}
+
+	resp, err := c.roundTrip(ctx, method, path, body, header, accessToken)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !canRefresh {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	if err := c.refreshStale(ctx, accessToken); err != nil {
+		return nil, err
+	}
+	accessToken, _ = c.Tokens()
+	return c.roundTrip(ctx, method, path, body, header, accessToken)
+}
+
+// roundTrip performs a single request
+func (c *Client) roundTrip(ctx context.Context, method, path string, body []byte, header http.Header, accessToken string) (*http.Response, error) {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("omnichat: %s %s: %w", method, path, err)
+	}
+	return resp, nil
+}
+
+// doJSON sends a JSON body, if any, and decodes the JSON response into out
+func (c *Client) doJSON(ctx context.Context, method, path string, in, out interface{}) error {
+	var body []byte
+	header := http.Header{}
+	if in != nil {
+		var err error
+		if body, err = marshal(in); err != nil {
+			return err
+		}
+		header.Set("Content-Type", "application/json")
+	}
+	return c.do(ctx, method, path, body, header, out)
+}
+
+// do sends a request and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body []byte, header http.Header, out interface{}) error {
+	resp, err := c.send(ctx, method, path, body, header)
+	if err != nil {
+		return err
+	}
+	return decode(resp, out)
+}
+
+// decode decodes a 2xx JSON response into out and closes it. Other
+// statuses are returned as an *APIError.
+func decode(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(resp)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("omnichat: failed to parse %s response: %w", resp.Request.URL.Path, err)
+	}
+	return nil
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	return body, nil
+}
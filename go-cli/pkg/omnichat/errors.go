@@ -0,0 +1,73 @@
+package omnichat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody caps how much of an error response is read
+const maxErrorBody = 64 << 10
+
+// Errors an *APIError matches with errors.Is, by status code
+var (
+	ErrUnauthorized = errors.New("omnichat: unauthorized")
+	ErrForbidden    = errors.New("omnichat: forbidden")
+	ErrNotFound     = errors.New("omnichat: not found")
+	ErrRateLimited  = errors.New("omnichat: rate limited")
+)
+
+// APIError is a non-2xx response. Message is the error of the
+// ErrorResponse body, or the status text if the body is not one.
+type APIError struct {
+	StatusCode int
+	Message    string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("omnichat: HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Is reports whether the status code matches one of the sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// StreamError is an error event in a message stream
+type StreamError struct {
+	Message string
+}
+
+func (e *StreamError) Error() string {
+	return "omnichat: stream error: " + e.Message
+}
+
+// newAPIError reads a non-2xx response into an *APIError
+func newAPIError(resp *http.Response) *APIError {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Message:    http.StatusText(resp.StatusCode),
+		Body:       strings.TrimSpace(string(body)),
+	}
+
+	var errorResponse ErrorResponse
+	if json.Unmarshal(body, &errorResponse) == nil && errorResponse.Error != "" {
+		apiErr.Message = errorResponse.Error
+	}
+	return apiErr
+}
@@ -0,0 +1,249 @@
+// Package omnichat is a client for the OmniChat v1 API.
+//
+//	client := omnichat.New(omnichat.Options{
+//		AccessToken:  accessToken,
+//		RefreshToken: refreshToken,
+//	})
+//	conversation, err := client.CreateConversation(ctx, omnichat.ConversationRequest{Title: "Notes"})
+//	stream, err := client.SendMessage(ctx, conversation.ID, omnichat.MessageRequest{Content: "Hello"})
+//	defer stream.Close()
+//	for {
+//		event, err := stream.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+//
+// Every method takes a context, which cancels the request and, for
+// SendMessage, the stream. Non-2xx responses are returned as an *APIError,
+// which matches ErrUnauthorized, ErrNotFound and the other sentinel errors
+// with errors.Is. With a refresh token, the access token is refreshed
+// before it expires and when the API rejects it.
+package omnichat
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBaseURL is the production API
+const DefaultBaseURL = "https://omnichat-7pu.pages.dev"
+
+// messagesPageSize is how many messages ListMessages reads per request,
+// the API's default page
+const messagesPageSize = 50
+
+// Options configures a Client
+type Options struct {
+	// BaseURL of the API, DefaultBaseURL if empty
+	BaseURL string
+	// AccessToken is the JWT sent as a Bearer token
+	AccessToken string
+	// RefreshToken, if set, renews the access token automatically
+	RefreshToken string
+	// ClerkToken is a Clerk session token for ListModels: /api/models is a
+	// web app route and does not accept the access token
+	ClerkToken string
+	// HTTPClient sends the requests, a client without a timeout if nil;
+	// streams can run for minutes, so bound requests with their context
+	HTTPClient *http.Client
+	// OnRefresh is called with the new tokens after every refresh, e.g. to
+	// persist them. The refresh token is the one in use when the response
+	// leaves it out.
+	OnRefresh func(AuthResponse)
+}
+
+// Client calls the v1 API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	onRefresh  func(AuthResponse)
+	clerkToken string
+
+	mu           sync.Mutex
+	accessToken  string
+	refreshToken string
+	expiresAt    time.Time // zero if unknown
+
+	// refreshMu serializes refreshes, so concurrent requests that find the
+	// token expired refresh it once
+	refreshMu sync.Mutex
+}
+
+// New returns a client for the v1 API
+func New(opts Options) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(opts.BaseURL, "/"),
+		httpClient: opts.HTTPClient,
+		onRefresh:  opts.OnRefresh,
+		clerkToken: opts.ClerkToken,
+	}
+	if c.baseURL == "" {
+		c.baseURL = DefaultBaseURL
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{}
+	}
+	c.setTokens(opts.AccessToken, opts.RefreshToken, 0)
+	return c
+}
+
+// ListModels returns the models of /api/models, ordered by provider and
+// then as listed. It signs in with Options.ClerkToken and fails with
+// ErrUnauthorized without one.
+func (c *Client) ListModels(ctx context.Context) ([]Model, error) {
+	resp, err := c.roundTrip(ctx, "GET", "/api/models", nil, nil, c.clerkToken)
+	if err != nil {
+		return nil, err
+	}
+	var listed ModelsResponse
+	if err := decode(resp, &listed); err != nil {
+		return nil, err
+	}
+
+	providers := make([]string, 0, len(listed.Providers))
+	for provider := range listed.Providers {
+		providers = append(providers, provider)
+	}
+	slices.Sort(providers)
+
+	var models []Model
+	for _, provider := range providers {
+		for _, model := range listed.Providers[provider] {
+			if model.Provider == "" {
+				model.Provider = provider
+			}
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
+
+// ListConversations returns the user's conversations
+func (c *Client) ListConversations(ctx context.Context) ([]Conversation, error) {
+	var resp ConversationsResponse
+	if err := c.doJSON(ctx, "GET", "/api/v1/conversations", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Conversations, nil
+}
+
+// CreateConversation creates a conversation
+func (c *Client) CreateConversation(ctx context.Context, req ConversationRequest) (*Conversation, error) {
+	var conversation Conversation
+	if err := c.doJSON(ctx, "POST", "/api/v1/conversations", req, &conversation); err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// ListMessages returns all messages of a conversation, oldest first,
+// reading them a page at a time
+func (c *Client) ListMessages(ctx context.Context, conversationID string) ([]Message, error) {
+	var messages []Message
+	for {
+		path := fmt.Sprintf("/api/v1/conversations/%s/messages?limit=%d&offset=%d", conversationID, messagesPageSize, len(messages))
+		var page MessagesResponse
+		if err := c.doJSON(ctx, "GET", path, nil, &page); err != nil {
+			return nil, err
+		}
+		messages = append(messages, page.Messages...)
+		if !page.HasMore || len(page.Messages) == 0 {
+			return messages, nil
+		}
+	}
+}
+
+// SendMessage sends a user message to a conversation and streams the
+// assistant's reply. The caller must close the stream.
+func (c *Client) SendMessage(ctx context.Context, conversationID string, req MessageRequest) (*MessageStream, error) {
+	req.Stream = true
+	body, err := marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{"Accept": {"text/event-stream"}, "Content-Type": {"application/json"}}
+	resp, err := c.send(ctx, "POST", "/api/v1/conversations/"+conversationID+"/messages", body, header)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		resp.Body.Close()
+		return nil, fmt.Errorf("omnichat: expected a text/event-stream response, got %q", ct)
+	}
+	return newMessageStream(resp.Body), nil
+}
+
+// Upload uploads a file for a conversation and returns the attachment,
+// whose ID can be sent in MessageRequest.AttachmentIDs. The content type
+// comes from the file name's extension or, failing that, the data. The
+// file is read into memory so the upload can be retried after a refresh.
+func (c *Client) Upload(ctx context.Context, conversationID, fileName string, data io.Reader) (*Attachment, error) {
+	content, err := io.ReadAll(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fileName, err)
+	}
+	contentType := mime.TypeByExtension(filepath.Ext(fileName))
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if conversationID != "" {
+		writer.WriteField("conversationId", conversationID)
+	}
+	part := textproto.MIMEHeader{}
+	part.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{"name": "file", "filename": fileName}))
+	part.Set("Content-Type", contentType)
+	w, err := writer.CreatePart(part)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+	w.Write(content)
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	var attachment Attachment
+	header := http.Header{"Content-Type": {writer.FormDataContentType()}}
+	if err := c.do(ctx, "POST", "/api/v1/upload", body.Bytes(), header, &attachment); err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// GetProfile returns the user's profile, subscription and battery
+func (c *Client) GetProfile(ctx context.Context) (*UserProfile, error) {
+	var profile UserProfile
+	if err := c.doJSON(ctx, "GET", "/api/v1/user/profile", nil, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// GetUsage returns the user's battery usage over the last 30 days
+func (c *Client) GetUsage(ctx context.Context) (*UserUsage, error) {
+	var usage UserUsage
+	if err := c.doJSON(ctx, "GET", "/api/v1/user/usage", nil, &usage); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
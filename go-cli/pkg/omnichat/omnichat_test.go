@@ -0,0 +1,311 @@
+package omnichat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/omnichat/validator/internal/jwt"
+	"github.com/omnichat/validator/internal/mock"
+)
+
+// countingServer serves the mock API and counts refreshes and 401s
+type countingServer struct {
+	*httptest.Server
+	refreshes    atomic.Int32
+	unauthorized atomic.Int32
+}
+
+func newCountingServer(t *testing.T) *countingServer {
+	t.Helper()
+	s := &countingServer{}
+	api := mock.New(mock.Options{})
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/auth/refresh" {
+			s.refreshes.Add(1)
+		}
+		recorder := &statusRecorder{ResponseWriter: w}
+		api.ServeHTTP(recorder, r)
+		if recorder.status == http.StatusUnauthorized {
+			s.unauthorized.Add(1)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func TestRefreshOnUnauthorized(t *testing.T) {
+	server := newCountingServer(t)
+	var refreshed []AuthResponse
+	client := New(Options{
+		BaseURL:      server.URL,
+		AccessToken:  "revoked-token",
+		RefreshToken: mock.DefaultRefreshToken,
+		OnRefresh:    func(auth AuthResponse) { refreshed = append(refreshed, auth) },
+	})
+
+	if _, err := client.GetProfile(context.Background()); err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if n := server.refreshes.Load(); n != 1 {
+		t.Errorf("%d refreshes, want 1", n)
+	}
+	if len(refreshed) != 1 || refreshed[0].AccessToken == "" {
+		t.Fatalf("OnRefresh got %+v", refreshed)
+	}
+	// The mock leaves the refresh token out, so the one in use is kept
+	accessToken, refreshToken := client.Tokens()
+	if accessToken != refreshed[0].AccessToken || refreshToken != mock.DefaultRefreshToken || refreshed[0].RefreshToken != mock.DefaultRefreshToken {
+		t.Errorf("tokens after refresh: %q, %q; OnRefresh got refresh token %q", accessToken, refreshToken, refreshed[0].RefreshToken)
+	}
+
+	// The new access token is used without another refresh
+	if _, err := client.GetProfile(context.Background()); err != nil {
+		t.Fatalf("GetProfile after refresh: %v", err)
+	}
+	if n := server.refreshes.Load(); n != 1 {
+		t.Errorf("%d refreshes after a second request, want 1", n)
+	}
+}
+
+func TestUnauthorizedWithoutRefreshToken(t *testing.T) {
+	server := newCountingServer(t)
+	client := New(Options{BaseURL: server.URL, AccessToken: "revoked-token"})
+
+	_, err := client.GetProfile(context.Background())
+	var apiErr *APIError
+	if !errors.Is(err, ErrUnauthorized) || !errors.As(err, &apiErr) || apiErr.Message == "" {
+		t.Errorf("GetProfile error = %v, want an *APIError matching ErrUnauthorized", err)
+	}
+	if n := server.refreshes.Load(); n != 0 {
+		t.Errorf("%d refreshes without a refresh token", n)
+	}
+}
+
+func TestRefreshBeforeExpiry(t *testing.T) {
+	server := newCountingServer(t)
+	expiring, err := jwt.SignHS256(jwt.AccessClaims("user_mock", "", 10*time.Second), jwt.DevSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := New(Options{BaseURL: server.URL, AccessToken: expiring, RefreshToken: mock.DefaultRefreshToken})
+
+	if _, err := client.GetProfile(context.Background()); err != nil {
+		t.Fatalf("GetProfile: %v", err)
+	}
+	if n := server.refreshes.Load(); n != 1 {
+		t.Errorf("%d refreshes, want 1", n)
+	}
+	if n := server.unauthorized.Load(); n != 0 {
+		t.Errorf("the expiring token was sent and rejected %d times", n)
+	}
+	if accessToken, _ := client.Tokens(); accessToken == expiring {
+		t.Error("the expiring access token was not replaced")
+	}
+}
+
+func TestConcurrentRequestsRefreshOnce(t *testing.T) {
+	server := newCountingServer(t)
+	client := New(Options{BaseURL: server.URL, AccessToken: "revoked-token", RefreshToken: mock.DefaultRefreshToken})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetProfile(context.Background())
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("GetProfile: %v", err)
+		}
+	}
+	if n := server.refreshes.Load(); n != 1 {
+		t.Errorf("%d refreshes, want 1", n)
+	}
+}
+
+// sseServer streams body as the reply to every message
+func sseServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func chunk(content string) string {
+	return fmt.Sprintf("data: {\"type\":%q,\"content\":%q}\n\n", StreamEventContentChunk, content)
+}
+
+func TestMessageStream(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		reply string
+		check func(error) bool
+	}{
+		{
+			name:  "complete",
+			body:  "data: {\"type\":\"message_start\",\"id\":\"msg_1\"}\n\n" + chunk("Hello") + ": keep-alive\n\n" + chunk(", world") + "data: {\"type\":\"message_complete\"}\n\ndata: [DONE]\n\n",
+			reply: "Hello, world",
+			check: func(err error) bool { return err == io.EOF },
+		},
+		{
+			name:  "error event",
+			body:  chunk("Hel") + "data: {\"type\":\"error\",\"error\":\"provider unavailable\"}\n\n",
+			reply: "Hel",
+			check: func(err error) bool {
+				var streamErr *StreamError
+				return errors.As(err, &streamErr) && streamErr.Message == "provider unavailable"
+			},
+		},
+		{
+			name:  "cut off before [DONE]",
+			body:  chunk("Hel"),
+			reply: "Hel",
+			check: func(err error) bool { return err == io.ErrUnexpectedEOF },
+		},
+		{
+			name:  "invalid event",
+			body:  "data: {not json}\n\n",
+			check: func(err error) bool { return err != nil && strings.Contains(err.Error(), "invalid stream event") },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := New(Options{BaseURL: sseServer(t, tt.body).URL, AccessToken: "token"})
+			stream, err := client.SendMessage(context.Background(), "conv_1", MessageRequest{Content: "Hi"})
+			if err != nil {
+				t.Fatalf("SendMessage: %v", err)
+			}
+			defer stream.Close()
+
+			var last error
+			for range 10 {
+				if _, last = stream.Next(); last != nil {
+					break
+				}
+			}
+			if !tt.check(last) {
+				t.Errorf("stream ended with %v", last)
+			}
+			if got := stream.Reply(); got != tt.reply {
+				t.Errorf("Reply() = %q, want %q", got, tt.reply)
+			}
+		})
+	}
+}
+
+func TestStreamEndsAfterDone(t *testing.T) {
+	client := New(Options{BaseURL: sseServer(t, "data: [DONE]\n\n"+chunk("late")).URL, AccessToken: "token"})
+	stream, err := client.SendMessage(context.Background(), "conv_1", MessageRequest{Content: "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Close()
+	for range 2 {
+		if _, err := stream.Next(); err != io.EOF {
+			t.Errorf("Next after [DONE] = %v, want io.EOF", err)
+		}
+	}
+}
+
+func TestSendMessageNotEventStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := New(Options{BaseURL: server.URL, AccessToken: "token"})
+	if _, err := client.SendMessage(context.Background(), "conv_1", MessageRequest{Content: "Hi"}); err == nil || !strings.Contains(err.Error(), "text/event-stream") {
+		t.Errorf("SendMessage error = %v", err)
+	}
+}
+
+func TestConversationAgainstMock(t *testing.T) {
+	server := newCountingServer(t)
+	client := New(Options{BaseURL: server.URL, AccessToken: mock.DefaultJWTToken, ClerkToken: mock.DefaultClerkToken})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	models, err := client.ListModels(ctx)
+	if err != nil || len(models) == 0 {
+		t.Fatalf("ListModels = %d models, %v", len(models), err)
+	}
+	for _, model := range models {
+		if model.Provider == "" {
+			t.Errorf("model %s has no provider", model.ID)
+		}
+	}
+
+	conversation, err := client.CreateConversation(ctx, ConversationRequest{Title: "Test"})
+	if err != nil {
+		t.Fatalf("CreateConversation: %v", err)
+	}
+	stream, err := client.SendMessage(ctx, conversation.ID, MessageRequest{Content: "Hello"})
+	if err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	for {
+		_, err := stream.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+	}
+	stream.Close()
+	if stream.Reply() == "" {
+		t.Error("the reply is empty")
+	}
+
+	messages, err := client.ListMessages(ctx, conversation.ID)
+	if err != nil {
+		t.Fatalf("ListMessages: %v", err)
+	}
+	if len(messages) != 2 || messages[0].Role != "user" || messages[1].Content != stream.Reply() {
+		t.Errorf("messages = %+v", messages)
+	}
+}
+
+func TestListModelsWithoutClerk(t *testing.T) {
+	server := newCountingServer(t)
+	client := New(Options{BaseURL: server.URL, AccessToken: mock.DefaultJWTToken})
+	if _, err := client.ListModels(context.Background()); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("ListModels error = %v, want ErrUnauthorized", err)
+	}
+}
@@ -0,0 +1,67 @@
+package omnichat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/omnichat/validator/internal/client"
+)
+
+// doneSentinel is the data payload that ends the message stream
+const doneSentinel = "[DONE]"
+
+// MessageStream yields the events of an assistant reply
+type MessageStream struct {
+	sse   *client.SSEReader
+	done  bool
+	reply strings.Builder
+}
+
+func newMessageStream(body io.ReadCloser) *MessageStream {
+	return &MessageStream{sse: client.NewSSEReader(body)}
+}
+
+// Next returns the next event, or io.EOF once the reply is complete. The
+// text of content chunks is also collected for Reply. An error event is
+// returned as a *StreamError.
+func (s *MessageStream) Next() (StreamEvent, error) {
+	if s.done {
+		return StreamEvent{}, io.EOF
+	}
+
+	raw, err := s.sse.Next()
+	if err == io.EOF {
+		return StreamEvent{}, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return StreamEvent{}, err
+	}
+	if strings.TrimSpace(raw.Data) == doneSentinel {
+		s.done = true
+		return StreamEvent{}, io.EOF
+	}
+
+	var event StreamEvent
+	if err := json.Unmarshal([]byte(raw.Data), &event); err != nil {
+		return StreamEvent{}, fmt.Errorf("omnichat: invalid stream event %q: %w", raw.Data, err)
+	}
+	switch event.Type {
+	case StreamEventContentChunk:
+		s.reply.WriteString(event.Content)
+	case StreamEventError:
+		return event, &StreamError{Message: event.Error}
+	}
+	return event, nil
+}
+
+// Reply returns the text streamed so far
+func (s *MessageStream) Reply() string {
+	return s.reply.String()
+}
+
+// Close closes the stream
+func (s *MessageStream) Close() error {
+	return s.sse.Close()
+}
@@ -0,0 +1,36 @@
+package omnichat
+
+import "github.com/omnichat/validator/internal/types"
+
+// The API's request and response types, shared with the validator so the
+// SDK and the checks cannot drift apart. Every type reachable from the
+// client's methods is named here, so importers can name the nested ones.
+type (
+	Model                 = types.AIModel
+	ModelsResponse        = types.ModelsResponse
+	Conversation          = types.Conversation
+	ConversationRequest   = types.ConversationRequest
+	ConversationsResponse = types.ConversationsResponse
+	LastMessage           = types.LastMessage
+	Message               = types.Message
+	MessageAttachment     = types.MessageAttachment
+	MessagesResponse      = types.MessagesResponse
+	MessageRequest        = types.V1MessageRequest
+	StreamEvent           = types.StreamEvent
+	Attachment            = types.Attachment
+	UserProfile           = types.UserProfile
+	UserSubscription      = types.UserSubscription
+	UserBattery           = types.UserBattery
+	UserUsage             = types.UserUsageResponse
+	AuthResponse          = types.AuthResponse
+	AuthUser              = types.AuthUser
+	ErrorResponse         = types.ErrorResponse
+)
+
+// Event types of the message stream
+const (
+	StreamEventMessageStart    = types.StreamEventMessageStart
+	StreamEventContentChunk    = types.StreamEventContentChunk
+	StreamEventMessageComplete = types.StreamEventMessageComplete
+	StreamEventError           = types.StreamEventError
+)
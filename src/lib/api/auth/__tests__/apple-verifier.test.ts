@@ -0,0 +1,129 @@
+/**
+ * @vitest-environment node
+ */
+import { describe, it, expect, vi, beforeAll, beforeEach, afterEach } from 'vitest';
+import { SignJWT, exportJWK, generateKeyPair } from 'jose';
+
+const APPLE_JWKS_URI = 'https://appleid.apple.com/auth/keys';
+const LOCAL_JWKS_URI = 'http://localhost:3003/auth/keys';
+const CLIENT_ID = 'com.omnichat.test';
+
+let oldKey: CryptoKey;
+let newKey: CryptoKey;
+let oldJwk: Record<string, unknown>;
+let newJwk: Record<string, unknown>;
+
+beforeAll(async () => {
+  const oldPair = await generateKeyPair('RS256', { extractable: true });
+  const newPair = await generateKeyPair('RS256', { extractable: true });
+  oldKey = oldPair.privateKey;
+  newKey = newPair.privateKey;
+  oldJwk = { ...(await exportJWK(oldPair.publicKey)), kid: 'old', use: 'sig', alg: 'RS256' };
+  newJwk = { ...(await exportJWK(newPair.publicKey)), kid: 'new', use: 'sig', alg: 'RS256' };
+});
+
+function signIdToken(key: CryptoKey, kid: string) {
+  return new SignJWT({ email: 'user@example.com' })
+    .setProtectedHeader({ alg: 'RS256', kid })
+    .setIssuer('https://appleid.apple.com')
+    .setAudience(CLIENT_ID)
+    .setSubject('apple-user')
+    .setIssuedAt()
+    .setExpirationTime('10m')
+    .sign(key);
+}
+
+function jwksResponse(...keys: Record<string, unknown>[]) {
+  return new Response(JSON.stringify({ keys }), { status: 200 });
+}
+
+// The verifier caches keys in module state, so each test loads it afresh
+async function loadVerifier() {
+  vi.resetModules();
+  return import('../apple-verifier');
+}
+
+describe('verifyAppleIdToken - key rotation', () => {
+  const mockFetch = vi.fn();
+
+  beforeEach(() => {
+    mockFetch.mockReset();
+    vi.stubGlobal('fetch', mockFetch);
+    vi.useFakeTimers({ toFake: ['Date'] });
+  });
+
+  afterEach(() => {
+    vi.useRealTimers();
+    vi.unstubAllGlobals();
+    vi.unstubAllEnvs();
+  });
+
+  it('refreshes the cached keys when a token has an unknown kid', async () => {
+    const { verifyAppleIdToken } = await loadVerifier();
+    mockFetch
+      .mockResolvedValueOnce(jwksResponse(oldJwk))
+      .mockResolvedValueOnce(jwksResponse(oldJwk, newJwk));
+
+    await verifyAppleIdToken(await signIdToken(oldKey, 'old'), CLIENT_ID);
+    expect(mockFetch).toHaveBeenCalledTimes(1);
+
+    // Apple rotated its keys after the cache was filled
+    const payload = await verifyAppleIdToken(await signIdToken(newKey, 'new'), CLIENT_ID);
+    expect(payload.sub).toBe('apple-user');
+    expect(mockFetch).toHaveBeenCalledTimes(2);
+
+    // The refreshed keys are cached
+    await verifyAppleIdToken(await signIdToken(newKey, 'new'), CLIENT_ID);
+    expect(mockFetch).toHaveBeenCalledTimes(2);
+  });
+
+  it('forces a refresh at most once a minute', async () => {
+    const { verifyAppleIdToken } = await loadVerifier();
+    mockFetch.mockImplementation(async () => jwksResponse(oldJwk));
+    const unknownKid = await signIdToken(newKey, 'made-up');
+
+    await expect(verifyAppleIdToken(unknownKid, CLIENT_ID)).rejects.toThrow('Invalid Apple ID token');
+    expect(mockFetch).toHaveBeenCalledTimes(2);
+
+    await expect(verifyAppleIdToken(unknownKid, CLIENT_ID)).rejects.toThrow('Invalid Apple ID token');
+    expect(mockFetch).toHaveBeenCalledTimes(2);
+
+    vi.setSystemTime(Date.now() + 61 * 1000);
+    await expect(verifyAppleIdToken(unknownKid, CLIENT_ID)).rejects.toThrow('Invalid Apple ID token');
+    expect(mockFetch).toHaveBeenCalledTimes(3);
+  });
+});
+
+describe('verifyAppleIdToken - APPLE_JWKS_URI', () => {
+  const mockFetch = vi.fn();
+
+  beforeEach(() => {
+    mockFetch.mockReset();
+    mockFetch.mockImplementation(async () => jwksResponse(oldJwk));
+    vi.stubGlobal('fetch', mockFetch);
+    vi.stubEnv('APPLE_JWKS_URI', LOCAL_JWKS_URI);
+  });
+
+  afterEach(() => {
+    vi.unstubAllGlobals();
+    vi.unstubAllEnvs();
+  });
+
+  it('is honored under next dev', async () => {
+    vi.stubEnv('NODE_ENV', 'development');
+    const { verifyAppleIdToken } = await loadVerifier();
+
+    await verifyAppleIdToken(await signIdToken(oldKey, 'old'), CLIENT_ID);
+    expect(mockFetch).toHaveBeenCalledWith(LOCAL_JWKS_URI);
+  });
+
+  it('is ignored by other builds, even with NEXT_PUBLIC_DEV_MODE', async () => {
+    vi.stubEnv('NODE_ENV', 'production');
+    vi.stubEnv('NEXT_PUBLIC_DEV_MODE', 'true');
+    const { verifyAppleIdToken } = await loadVerifier();
+
+    await verifyAppleIdToken(await signIdToken(oldKey, 'old'), CLIENT_ID);
+    expect(mockFetch).toHaveBeenCalledWith(APPLE_JWKS_URI);
+    expect(mockFetch).not.toHaveBeenCalledWith(LOCAL_JWKS_URI);
+  });
+});
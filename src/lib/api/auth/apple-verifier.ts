@@ -1,9 +1,21 @@
 // Production Apple ID token verification
-import { importSPKI, jwtVerify } from 'jose';
+import { importJWK, jwtVerify } from 'jose';
 
 const APPLE_ISSUER = 'https://appleid.apple.com';
 const APPLE_JWKS_URI = 'https://appleid.apple.com/auth/keys';
 
+// Local test tools serve their own signing keys through APPLE_JWKS_URI.
+// Only `next dev` honors it, as trusting another key set would let anyone
+// holding its private key sign in as any Apple user. NEXT_PUBLIC_DEV_MODE is
+// not enough: it is a public build flag a preview deploy can have set.
+function getAppleJwksUri(): string {
+  const override = process.env.APPLE_JWKS_URI;
+  if (override && process.env.NODE_ENV === 'development') {
+    return override;
+  }
+  return APPLE_JWKS_URI;
+}
+
 interface AppleKey {
   kty: string;
   kid: string;
@@ -31,14 +43,26 @@ interface AppleIdToken {
 let keysCache: { keys: AppleKey[]; timestamp: number } | null = null;
 const CACHE_DURATION = 24 * 60 * 60 * 1000; // 24 hours
 
-async function getApplePublicKeys(): Promise<AppleKey[]> {
+// Unknown kids force a refresh at most this often, so tokens with made-up
+// kids cannot turn every request into a fetch
+const FORCED_REFRESH_INTERVAL = 60 * 1000; // 1 minute
+let lastForcedRefresh = 0;
+
+async function getApplePublicKeys(forceRefresh = false): Promise<AppleKey[]> {
+  if (forceRefresh && keysCache && Date.now() - lastForcedRefresh < FORCED_REFRESH_INTERVAL) {
+    return keysCache.keys;
+  }
+
   // Check cache
-  if (keysCache && Date.now() - keysCache.timestamp < CACHE_DURATION) {
+  if (!forceRefresh && keysCache && Date.now() - keysCache.timestamp < CACHE_DURATION) {
     return keysCache.keys;
   }
 
   // Fetch fresh keys
-  const response = await fetch(APPLE_JWKS_URI);
+  if (forceRefresh) {
+    lastForcedRefresh = Date.now();
+  }
+  const response = await fetch(getAppleJwksUri());
   if (!response.ok) {
     throw new Error('Failed to fetch Apple public keys');
   }
@@ -52,36 +76,6 @@ async function getApplePublicKeys(): Promise<AppleKey[]> {
   return data.keys;
 }
 
-function rsaPublicKeyToPEM(n: string, e: string): string {
-  const nBuffer = Buffer.from(n, 'base64url');
-  const eBuffer = Buffer.from(e, 'base64url');
-
-  const modulusHex = nBuffer.toString('hex');
-  const exponentHex = eBuffer.toString('hex');
-
-  // Construct ASN.1 DER encoding
-  const modulus = `00${modulusHex}`;
-  const exponent = exponentHex;
-
-  const rsaPublicKey = `30${getHexLength(modulus, exponent)}02${getHexLength(modulus)}${modulus}02${getHexLength(
-    exponent
-  )}${exponent}`;
-  const publicKey = `30${getHexLength(rsaPublicKey)}0609608648016503040201050003${getHexLength(
-    rsaPublicKey
-  )}00${rsaPublicKey}`;
-
-  const base64 = Buffer.from(publicKey, 'hex').toString('base64');
-  const pem = `-----BEGIN PUBLIC KEY-----\n${base64.match(/.{1,64}/g)?.join('\n')}\n-----END PUBLIC KEY-----`;
-
-  return pem;
-}
-
-function getHexLength(...args: string[]): string {
-  const length = args.reduce((acc, arg) => acc + arg.length / 2, 0);
-  const hexLength = length.toString(16);
-  return hexLength.length % 2 === 0 ? hexLength : `0${hexLength}`;
-}
-
 export async function verifyAppleIdToken(
   idToken: string,
   clientId: string,
@@ -98,16 +92,20 @@ export async function verifyAppleIdToken(
     }
 
     // Get Apple's public keys
-    const keys = await getApplePublicKeys();
-    const key = keys.find((k) => k.kid === kid);
+    let keys = await getApplePublicKeys();
+    let key = keys.find((k) => k.kid === kid);
+
+    // Apple rotates its keys, so an unknown kid may be newer than the cache
+    if (!key) {
+      keys = await getApplePublicKeys(true);
+      key = keys.find((k) => k.kid === kid);
+    }
 
     if (!key) {
       throw new Error('Public key not found');
     }
 
-    // Convert key to PEM format
-    const pem = rsaPublicKeyToPEM(key.n, key.e);
-    const publicKey = await importSPKI(pem, alg);
+    const publicKey = await importJWK({ kty: key.kty, n: key.n, e: key.e }, alg);
 
     // Verify the token
     const { payload } = await jwtVerify(idToken, publicKey, {